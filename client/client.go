@@ -2,29 +2,48 @@ package client
 
 import (
 	"crypto/tls"
-	"crypto/x509"
 	"errors"
+	"fmt"
+	"io"
 	"net"
 	"net/http"
-	"net/smtp"
 	"os"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/go-ping/ping"
 )
 
+const (
+	// DefaultTCPTimeout is the default timeout for establishing a TCP connection, including for STARTTLS
+	DefaultTCPTimeout = 5 * time.Second
+
+	// DefaultICMPTimeout is the default timeout for the Ping function
+	DefaultICMPTimeout = 5 * time.Second
+
+	// DefaultUDPTimeout is the default timeout for receiving the reply of a UDP service
+	DefaultUDPTimeout = 5 * time.Second
+
+	// maximumUDPPacketSize is the maximum size of the payload of a UDP packet
+	maximumUDPPacketSize = 65535
+
+	// MaximumTCPResponseSize is the maximum number of bytes read from the response of a TCP service
+	MaximumTCPResponseSize = 64 * 1024
+
+	// DefaultMaximumRedirects is the default maximum number of redirects followed by an HTTP client
+	DefaultMaximumRedirects = 10
+)
+
 var (
 	secureHTTPClient   *http.Client
 	insecureHTTPClient *http.Client
 
-	// pingTimeout is the timeout for the Ping function
-	// This is mainly exposed for testing purposes
-	pingTimeout = 5 * time.Second
-
 	// httpTimeout is the timeout for secureHTTPClient and insecureHTTPClient
 	httpTimeout = 10 * time.Second
+
+	// runPinger sends the packets of a pinger and waits for the replies. Tests override it to simulate a lack of
+	// privileges.
+	runPinger = (*ping.Pinger).Run
 )
 
 func init() {
@@ -37,6 +56,44 @@ func init() {
 	}
 }
 
+// GetDefaultHTTPTimeout returns the timeout of the shared HTTP clients
+//
+// Defaults to 10 seconds, but can be overridden through the HTTP_CLIENT_TIMEOUT_IN_SECONDS environment variable
+func GetDefaultHTTPTimeout() time.Duration {
+	return httpTimeout
+}
+
+// GetHTTPClientWithTimeout returns a HTTP client that shares its transport with the shared HTTP client, but that has
+// a different timeout. If the timeout is 0, the shared HTTP client is returned.
+func GetHTTPClientWithTimeout(insecure bool, timeout time.Duration) *http.Client {
+	sharedClient := GetHTTPClient(insecure)
+	if timeout <= 0 || timeout == sharedClient.Timeout {
+		return sharedClient
+	}
+	httpClient := *sharedClient
+	httpClient.Timeout = timeout
+	return &httpClient
+}
+
+// WithRedirectPolicy returns a HTTP client that shares its transport and its timeout with the given HTTP client, but
+// that follows at most maximumRedirects redirects, or none at all if followRedirects is false.
+//
+// When redirects aren't followed, the redirect response itself is returned. When there are more redirects than
+// maximumRedirects, an error is returned.
+func WithRedirectPolicy(httpClient *http.Client, followRedirects bool, maximumRedirects int) *http.Client {
+	httpClientWithRedirectPolicy := *httpClient
+	httpClientWithRedirectPolicy.CheckRedirect = func(request *http.Request, via []*http.Request) error {
+		if !followRedirects {
+			return http.ErrUseLastResponse
+		}
+		if len(via) > maximumRedirects {
+			return fmt.Errorf("stopped after %d redirects", maximumRedirects)
+		}
+		return nil
+	}
+	return &httpClientWithRedirectPolicy
+}
+
 // GetHTTPClient returns the shared HTTP client
 func GetHTTPClient(insecure bool) *http.Client {
 	if insecure {
@@ -69,61 +126,209 @@ func GetHTTPClient(insecure bool) *http.Client {
 }
 
 // CanCreateTCPConnection checks whether a connection can be established with a TCP service
-func CanCreateTCPConnection(address string) bool {
-	conn, err := net.DialTimeout("tcp", address, 5*time.Second)
+//
+// If the timeout is 0, DefaultTCPTimeout is used.
+func CanCreateTCPConnection(address string, timeout time.Duration) (bool, error) {
+	if timeout <= 0 {
+		timeout = DefaultTCPTimeout
+	}
+	conn, err := net.DialTimeout("tcp", address, timeout)
 	if err != nil {
-		return false
+		return false, err
 	}
 	_ = conn.Close()
-	return true
+	return true, nil
 }
 
-// CanPerformStartTLS checks whether a connection can be established to an address using the STARTTLS protocol
-func CanPerformStartTLS(address string, insecure bool) (connected bool, certificate *x509.Certificate, err error) {
-	hostAndPort := strings.Split(address, ":")
-	if len(hostAndPort) != 2 {
-		return false, nil, errors.New("invalid address for starttls, format must be host:port")
+// SendTCPPayload establishes a connection with a TCP service, writes the given payload, if any, and returns the
+// first chunk of data sent by the service, which is either the response to the payload or, if there's no payload,
+// the banner of the service
+//
+// At most MaximumTCPResponseSize bytes are read. The timeout applies to the entire exchange. If the timeout is 0,
+// DefaultTCPTimeout is used.
+func SendTCPPayload(address string, payload []byte, timeout time.Duration) (bool, []byte, error) {
+	if timeout <= 0 {
+		timeout = DefaultTCPTimeout
 	}
-	smtpClient, err := smtp.Dial(address)
+	conn, err := net.DialTimeout("tcp", address, timeout)
 	if err != nil {
-		return
+		return false, nil, err
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return true, nil, err
+	}
+	if len(payload) > 0 {
+		if _, err = conn.Write(payload); err != nil {
+			return true, nil, err
+		}
+	}
+	response := make([]byte, MaximumTCPResponseSize)
+	n, err := conn.Read(response)
+	if err != nil && (err != io.EOF || n == 0) {
+		return true, nil, err
+	}
+	return true, response[:n], nil
+}
+
+// SendUDPPacket sends a packet with the given payload to a UDP service and returns the payload of the first packet
+// received in reply
+//
+// Because UDP is connectionless, a reply is the only way to know whether there's something listening at the given
+// address, so not receiving a reply before the timeout is treated as an error. If the timeout is 0, DefaultUDPTimeout
+// is used.
+func SendUDPPacket(address string, payload []byte, timeout time.Duration) ([]byte, error) {
+	if timeout <= 0 {
+		timeout = DefaultUDPTimeout
+	}
+	conn, err := net.DialTimeout("udp", address, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
 	}
-	err = smtpClient.StartTLS(&tls.Config{
-		InsecureSkipVerify: insecure,
-		ServerName:         hostAndPort[0],
-	})
+	if _, err = conn.Write(payload); err != nil {
+		return nil, err
+	}
+	reply := make([]byte, maximumUDPPacketSize)
+	n, err := conn.Read(reply)
+	if err != nil {
+		return nil, err
+	}
+	return reply[:n], nil
+}
+
+// CanPerformTLS checks whether a TLS connection can be established to an address
+//
+// If the server name of the TLS configuration is empty, the host of the address is used.
+// The timeout applies to the entire exchange. If the timeout is 0, DefaultTCPTimeout is used.
+func CanPerformTLS(address string, tlsConfig *tls.Config, timeout time.Duration) (connected bool, state *tls.ConnectionState, err error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false, nil, errors.New("invalid address for tls, format must be host:port")
+	}
+	if timeout <= 0 {
+		timeout = DefaultTCPTimeout
+	}
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: timeout, Deadline: time.Now().Add(timeout)}, "tcp", address, withDefaultServerName(tlsConfig, host))
 	if err != nil {
 		return
 	}
-	if state, ok := smtpClient.TLSConnectionState(); ok {
-		certificate = state.PeerCertificates[0]
-	} else {
-		return false, nil, errors.New("could not get TLS connection state")
+	defer conn.Close()
+	connectionState := conn.ConnectionState()
+	if len(connectionState.PeerCertificates) == 0 {
+		return false, nil, errors.New("no certificate presented by the server")
 	}
-	return true, certificate, nil
+	return true, &connectionState, nil
+}
+
+// withDefaultServerName returns a copy of the TLS configuration with the server name set to the default server name
+// if it isn't already set
+func withDefaultServerName(tlsConfig *tls.Config, defaultServerName string) *tls.Config {
+	if tlsConfig == nil {
+		return &tls.Config{ServerName: defaultServerName}
+	}
+	if len(tlsConfig.ServerName) > 0 {
+		return tlsConfig
+	}
+	tlsConfig = tlsConfig.Clone()
+	tlsConfig.ServerName = defaultServerName
+	return tlsConfig
+}
+
+// PingStatistics are the statistics of the packets sent to an address by PingWithStatistics
+type PingStatistics struct {
+	// PacketsSent is the number of packets sent
+	PacketsSent int
+
+	// PacketsReceived is the number of packets received in reply
+	PacketsReceived int
+
+	// PacketLoss is the percentage of packets sent for which no reply was received
+	PacketLoss float64
+
+	// AverageRTT is the average of the round-trip times
+	AverageRTT time.Duration
+
+	// MaximumRTT is the highest round-trip time
+	MaximumRTT time.Duration
+
+	// Jitter is the average of the differences between consecutive round-trip times
+	Jitter time.Duration
 }
 
-// Ping checks if an address can be pinged and returns the round-trip time if the address can be pinged
+// PingWithStatistics sends a given number of packets to an address, waiting for the interval between each packet,
+// and returns the statistics of these packets
 //
-// Note that this function takes at least 100ms, even if the address is 127.0.0.1
-func Ping(address string) (bool, time.Duration) {
-	pinger, err := ping.NewPinger(address)
+// A privileged ping (raw ICMP socket) is used whenever possible. If the process lacks the privileges to use one,
+// such as the CAP_NET_RAW capability on Linux, an unprivileged ping (UDP socket) is used instead.
+//
+// The timeout applies to all packets, which means that it must be higher than (count - 1) * interval for all
+// packets to be sent. If the interval is 0, packets are sent every second. If the timeout is 0, DefaultICMPTimeout
+// is used.
+func PingWithStatistics(address string, count int, interval, timeout time.Duration) (*PingStatistics, error) {
+	if timeout <= 0 {
+		timeout = DefaultICMPTimeout
+	}
+	pinger, err := newPinger(address, count, interval, timeout, true)
+	if err != nil {
+		return nil, err
+	}
+	err = runPinger(pinger)
+	if err != nil && errors.Is(err, os.ErrPermission) {
+		// A pinger cannot be run again once it has failed, so a new one is needed for the unprivileged ping
+		if pinger, err = newPinger(address, count, interval, timeout, false); err != nil {
+			return nil, err
+		}
+		err = runPinger(pinger)
+	}
 	if err != nil {
-		return false, 0
+		return nil, err
 	}
-	pinger.Count = 1
-	pinger.Timeout = pingTimeout
-	pinger.SetPrivileged(true)
-	err = pinger.Run()
+	pingerStatistics := pinger.Statistics()
+	statistics := &PingStatistics{
+		PacketsSent:     pingerStatistics.PacketsSent,
+		PacketsReceived: pingerStatistics.PacketsRecv,
+		AverageRTT:      pingerStatistics.AvgRtt,
+		MaximumRTT:      pingerStatistics.MaxRtt,
+		Jitter:          calculateJitter(pingerStatistics.Rtts),
+	}
+	if statistics.PacketsSent > 0 {
+		statistics.PacketLoss = float64(statistics.PacketsSent-statistics.PacketsReceived) / float64(statistics.PacketsSent) * 100
+	}
+	return statistics, nil
+}
+
+// newPinger creates a pinger sending the given number of packets to an address, waiting for the interval between
+// each packet. If the interval is 0, the default interval of the pinger is kept.
+func newPinger(address string, count int, interval, timeout time.Duration, privileged bool) (*ping.Pinger, error) {
+	pinger, err := ping.NewPinger(address)
 	if err != nil {
-		return false, 0
+		return nil, err
+	}
+	pinger.Count = count
+	if interval > 0 {
+		pinger.Interval = interval
+	}
+	pinger.Timeout = timeout
+	pinger.SetPrivileged(privileged)
+	return pinger, nil
+}
+
+// calculateJitter returns the average of the absolute differences between consecutive round-trip times
+func calculateJitter(rtts []time.Duration) time.Duration {
+	if len(rtts) < 2 {
+		return 0
 	}
-	if pinger.Statistics() != nil {
-		// If the packet loss is 100, it means that the packet didn't reach the host
-		if pinger.Statistics().PacketLoss == 100 {
-			return false, pinger.Timeout
+	var total time.Duration
+	for i := 1; i < len(rtts); i++ {
+		difference := rtts[i] - rtts[i-1]
+		if difference < 0 {
+			difference = -difference
 		}
-		return true, pinger.Statistics().MaxRtt
+		total += difference
 	}
-	return true, 0
+	return total / time.Duration(len(rtts)-1)
 }
@@ -1,8 +1,18 @@
 package client
 
 import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/go-ping/ping"
 )
 
 func TestGetHTTPClient(t *testing.T) {
@@ -28,24 +38,242 @@ func TestGetHTTPClient(t *testing.T) {
 	}
 }
 
-func TestPing(t *testing.T) {
-	pingTimeout = 500 * time.Millisecond
-	if success, rtt := Ping("127.0.0.1"); !success {
-		t.Error("expected true")
-		if rtt == 0 {
-			t.Error("Round-trip time returned on success should've higher than 0")
+func TestGetHTTPClientWithTimeout(t *testing.T) {
+	if GetHTTPClientWithTimeout(false, 0) != GetHTTPClient(false) {
+		t.Error("expected the shared client to be returned when the timeout is 0")
+	}
+	httpClient := GetHTTPClientWithTimeout(true, 3*time.Second)
+	if httpClient == GetHTTPClient(true) {
+		t.Error("expected a different client to be returned when the timeout is different from the shared client's")
+	}
+	if httpClient.Timeout != 3*time.Second {
+		t.Errorf("expected timeout to be %s, got %s", 3*time.Second, httpClient.Timeout)
+	}
+	if httpClient.Transport != GetHTTPClient(true).Transport {
+		t.Error("expected the transport to be shared with the shared client")
+	}
+	if GetHTTPClient(true).Timeout != GetDefaultHTTPTimeout() {
+		t.Error("the timeout of the shared client shouldn't have been modified")
+	}
+}
+
+func TestWithRedirectPolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		// Redirects /3 to /2, /2 to /1 and /1 to /0
+		hops, _ := strconv.Atoi(strings.TrimPrefix(request.URL.Path, "/"))
+		if hops > 0 {
+			http.Redirect(writer, request, "/"+strconv.Itoa(hops-1), http.StatusFound)
+			return
+		}
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	scenarios := []struct {
+		name             string
+		followRedirects  bool
+		maximumRedirects int
+		expectedStatus   int
+		expectedError    bool
+	}{
+		{name: "follow", followRedirects: true, maximumRedirects: 3, expectedStatus: http.StatusOK},
+		{name: "follow-with-too-many-redirects", followRedirects: true, maximumRedirects: 2, expectedError: true},
+		{name: "no-follow", followRedirects: false, maximumRedirects: 3, expectedStatus: http.StatusFound},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			httpClient := WithRedirectPolicy(GetHTTPClient(false), scenario.followRedirects, scenario.maximumRedirects)
+			response, err := httpClient.Get(server.URL + "/3")
+			if scenario.expectedError {
+				if err == nil {
+					t.Error("expected an error, because there are more redirects than the maximum")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("expected no error, got", err.Error())
+			}
+			_ = response.Body.Close()
+			if response.StatusCode != scenario.expectedStatus {
+				t.Errorf("expected status %d, got %d", scenario.expectedStatus, response.StatusCode)
+			}
+		})
+	}
+	if GetHTTPClient(false).CheckRedirect != nil {
+		t.Error("expected the redirect policy not to have been applied to the shared HTTP client")
+	}
+}
+
+func TestCanCreateTCPConnection(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	if connected, err := CanCreateTCPConnection(address, time.Second); !connected || err != nil {
+		t.Errorf("expected to be able to connect to %s, got err=%v", address, err)
+	}
+	_ = listener.Close()
+	if connected, err := CanCreateTCPConnection(address, time.Second); connected || err == nil {
+		t.Errorf("expected not to be able to connect to %s, because the listener is closed", address)
+	}
+}
+
+func TestSendTCPPayload(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				buffer := make([]byte, 1024)
+				n, err := conn.Read(buffer)
+				if err != nil {
+					return
+				}
+				// Like Redis, only reply to PING and ignore everything else
+				if string(buffer[:n]) == "PING\r\n" {
+					_, _ = conn.Write([]byte("+PONG\r\n"))
+				}
+				time.Sleep(time.Second)
+			}(conn)
+		}
+	}()
+	address := listener.Addr().String()
+	connected, response, err := SendTCPPayload(address, []byte("PING\r\n"), time.Second)
+	if !connected || err != nil {
+		t.Fatalf("expected to be able to connect to %s, got err=%v", address, err)
+	}
+	if string(response) != "+PONG\r\n" {
+		t.Errorf("expected response to be %q, got %q", "+PONG\r\n", string(response))
+	}
+	connected, _, err = SendTCPPayload(address, []byte("HELLO\r\n"), 100*time.Millisecond)
+	if !connected {
+		t.Error("expected to be able to connect to", address)
+	}
+	if err == nil {
+		t.Error("expected an error, because the server did not respond")
+	}
+}
+
+func TestSendTCPPayloadWithoutPayload(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
 		}
+		_, _ = conn.Write([]byte("SSH-2.0-OpenSSH_8.4\r\n"))
+		_ = conn.Close()
+	}()
+	connected, response, err := SendTCPPayload(listener.Addr().String(), nil, time.Second)
+	if !connected || err != nil {
+		t.Fatalf("expected to be able to connect, got err=%v", err)
+	}
+	if string(response) != "SSH-2.0-OpenSSH_8.4\r\n" {
+		t.Errorf("expected the banner to have been read, got %q", string(response))
+	}
+}
+
+func TestSendUDPPacket(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
 	}
-	if success, rtt := Ping("256.256.256.256"); success {
-		t.Error("expected false, because the IP is invalid")
-		if rtt != 0 {
-			t.Error("Round-trip time returned on failure should've been 0")
+	defer conn.Close()
+	go func() {
+		buffer := make([]byte, 1024)
+		for {
+			n, address, err := conn.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+			if string(buffer[:n]) == "ping" {
+				_, _ = conn.WriteTo([]byte("pong"), address)
+			}
 		}
+	}()
+	address := conn.LocalAddr().String()
+	reply, err := SendUDPPacket(address, []byte("ping"), time.Second)
+	if err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	if string(reply) != "pong" {
+		t.Errorf("expected reply to be %s, got %s", "pong", string(reply))
+	}
+	// The server only replies to "ping", so this should time out
+	if _, err = SendUDPPacket(address, []byte("hello"), 100*time.Millisecond); err == nil {
+		t.Error("expected an error, because no reply was sent")
+	}
+}
+
+func TestPingWithStatistics(t *testing.T) {
+	statistics, err := PingWithStatistics("127.0.0.1", 3, 10*time.Millisecond, time.Second)
+	if err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	if statistics.PacketsSent != 3 || statistics.PacketsReceived != 3 {
+		t.Errorf("expected 3 packets to have been sent and received, got %d sent and %d received", statistics.PacketsSent, statistics.PacketsReceived)
+	}
+	if statistics.PacketLoss != 0 {
+		t.Errorf("expected no packet loss, got %f", statistics.PacketLoss)
+	}
+	if statistics.AverageRTT <= 0 || statistics.AverageRTT > statistics.MaximumRTT {
+		t.Errorf("expected the average round-trip time to be between 0 and %s, got %s", statistics.MaximumRTT, statistics.AverageRTT)
+	}
+	if _, err := PingWithStatistics("256.256.256.256", 3, 10*time.Millisecond, time.Second); err == nil {
+		t.Error("expected an error, because the IP is invalid")
+	}
+}
+
+func TestPingWithStatisticsWithoutPrivileges(t *testing.T) {
+	defer func() { runPinger = (*ping.Pinger).Run }()
+	var pingers []*ping.Pinger
+	runPinger = func(pinger *ping.Pinger) error {
+		pingers = append(pingers, pinger)
+		return &net.OpError{Op: "listen", Net: "ip4:icmp", Err: os.ErrPermission}
+	}
+	if _, err := PingWithStatistics("127.0.0.1", 3, 10*time.Millisecond, time.Second); !errors.Is(err, os.ErrPermission) {
+		t.Errorf("expected a permission error, got %v", err)
+	}
+	if len(pingers) != 2 {
+		t.Fatalf("expected a privileged and an unprivileged ping, got %d pings", len(pingers))
+	}
+	if pingers[0] == pingers[1] {
+		t.Error("expected the unprivileged ping to use a new pinger, because a pinger cannot be run again")
+	}
+	if !pingers[0].Privileged() || pingers[1].Privileged() {
+		t.Error("expected the first ping to be privileged and the second one to be unprivileged")
+	}
+	if pingers[1].Count != 3 || pingers[1].Interval != 10*time.Millisecond || pingers[1].Timeout != time.Second {
+		t.Errorf("expected the configuration to have been copied, got count=%d, interval=%s and timeout=%s", pingers[1].Count, pingers[1].Interval, pingers[1].Timeout)
+	}
+}
+
+func TestCalculateJitter(t *testing.T) {
+	scenarios := []struct {
+		rtts           []time.Duration
+		expectedJitter time.Duration
+	}{
+		{rtts: nil, expectedJitter: 0},
+		{rtts: []time.Duration{10 * time.Millisecond}, expectedJitter: 0},
+		{rtts: []time.Duration{10 * time.Millisecond, 10 * time.Millisecond}, expectedJitter: 0},
+		{rtts: []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 10 * time.Millisecond}, expectedJitter: 10 * time.Millisecond},
+		{rtts: []time.Duration{10 * time.Millisecond, 14 * time.Millisecond, 12 * time.Millisecond, 12 * time.Millisecond}, expectedJitter: 2 * time.Millisecond},
 	}
-	if success, rtt := Ping("192.168.152.153"); success {
-		t.Error("expected false, because the IP is valid but the host should be unreachable")
-		if rtt != 0 {
-			t.Error("Round-trip time returned on failure should've been 0")
+	for _, scenario := range scenarios {
+		if jitter := calculateJitter(scenario.rtts); jitter != scenario.expectedJitter {
+			t.Errorf("expected jitter of %v to be %s, got %s", scenario.rtts, scenario.expectedJitter, jitter)
 		}
 	}
 }
@@ -54,6 +282,7 @@ func TestCanPerformStartTLS(t *testing.T) {
 	type args struct {
 		address  string
 		insecure bool
+		timeout  time.Duration
 	}
 	tests := []struct {
 		name          string
@@ -88,7 +317,7 @@ func TestCanPerformStartTLS(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			connected, _, err := CanPerformStartTLS(tt.args.address, tt.args.insecure)
+			connected, _, err := CanPerformStartTLS(tt.args.address, StartTLSProtocolSMTP, &tls.Config{InsecureSkipVerify: tt.args.insecure}, tt.args.timeout)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CanPerformStartTLS() err=%v, wantErr=%v", err, tt.wantErr)
 				return
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrClientCertificateWithoutKey is the error returned when only one of cert-file and key-file is specified
+	ErrClientCertificateWithoutKey = errors.New("client.cert-file and client.key-file must be specified together")
+
+	// ErrInvalidCAFile is the error returned when the CA file does not contain any valid PEM certificate
+	ErrInvalidCAFile = errors.New("client.ca-file does not contain any valid PEM certificate")
+)
+
+// Config is the configuration of the client used by a service to establish connections
+type Config struct {
+	// CAFile is the path to a file containing the PEM encoded certificates of the certificate authorities to trust
+	// when verifying the server's certificate. If empty, the system's certificate authorities are used.
+	CAFile string `yaml:"ca-file,omitempty"`
+
+	// CertFile is the path to a file containing the PEM encoded client certificate to present to the server
+	CertFile string `yaml:"cert-file,omitempty"`
+
+	// KeyFile is the path to a file containing the PEM encoded private key of the client certificate
+	KeyFile string `yaml:"key-file,omitempty"`
+
+	// ServerName is the name used to verify the server's certificate, and which is also sent through SNI.
+	// If empty, the host of the address being connected to is used.
+	ServerName string `yaml:"server-name,omitempty"`
+
+	tlsConfig *tls.Config
+
+	httpClient     *http.Client
+	httpClientLock sync.Mutex
+}
+
+// ValidateAndSetDefaults validates the client configuration and loads the certificates it references
+func (c *Config) ValidateAndSetDefaults() error {
+	if (len(c.CertFile) == 0) != (len(c.KeyFile) == 0) {
+		return ErrClientCertificateWithoutKey
+	}
+	tlsConfig := &tls.Config{ServerName: c.ServerName}
+	if len(c.CAFile) > 0 {
+		caCertificates, err := ioutil.ReadFile(c.CAFile)
+		if err != nil {
+			return err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caCertificates) {
+			return ErrInvalidCAFile
+		}
+	}
+	if len(c.CertFile) > 0 {
+		certificate, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return err
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	c.tlsConfig = tlsConfig
+	return nil
+}
+
+// GetTLSConfig returns the TLS configuration to use for a connection.
+//
+// The receiver may be nil, in which case the default TLS configuration is returned.
+// If no server name is configured, defaultServerName is used.
+func (c *Config) GetTLSConfig(insecure bool, defaultServerName string) *tls.Config {
+	var tlsConfig *tls.Config
+	if c != nil && c.tlsConfig != nil {
+		tlsConfig = c.tlsConfig.Clone()
+	} else {
+		tlsConfig = &tls.Config{}
+	}
+	tlsConfig.InsecureSkipVerify = insecure
+	if len(tlsConfig.ServerName) == 0 {
+		tlsConfig.ServerName = defaultServerName
+	}
+	return tlsConfig
+}
+
+// GetHTTPClient returns the HTTP client of the configuration.
+//
+// Unlike the shared HTTP clients, this client is dedicated to the configuration, because its TLS configuration cannot be
+// shared with other services. The client is created on the first call, after which insecure and timeout are ignored.
+// The receiver may be nil, in which case the shared HTTP client is returned.
+func (c *Config) GetHTTPClient(insecure bool, timeout time.Duration) *http.Client {
+	if c == nil {
+		return GetHTTPClientWithTimeout(insecure, timeout)
+	}
+	c.httpClientLock.Lock()
+	defer c.httpClientLock.Unlock()
+	if c.httpClient == nil {
+		if timeout <= 0 {
+			timeout = httpTimeout
+		}
+		c.httpClient = &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				MaxIdleConns:        100,
+				MaxIdleConnsPerHost: 20,
+				Proxy:               http.ProxyFromEnvironment,
+				TLSClientConfig:     c.GetTLSConfig(insecure, ""),
+			},
+		}
+	}
+	return c.httpClient
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeSelfSignedCertificate creates a self-signed certificate, writes it and its key in the given directory and
+// returns the path to both files as well as the parsed certificate
+func writeSelfSignedCertificate(t *testing.T, directory, name string) (string, string, *x509.Certificate) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	certificateBytes, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyBytes, err := x509.MarshalECPrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile := filepath.Join(directory, name+".crt")
+	keyFile := filepath.Join(directory, name+".key")
+	_ = ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificateBytes}), 0600)
+	_ = ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}), 0600)
+	certificate, _ := x509.ParseCertificate(certificateBytes)
+	return certFile, keyFile, certificate
+}
+
+func TestConfig_ValidateAndSetDefaults(t *testing.T) {
+	directory := t.TempDir()
+	certFile, keyFile, _ := writeSelfSignedCertificate(t, directory, "client")
+	invalidCAFile := filepath.Join(directory, "invalid.crt")
+	_ = ioutil.WriteFile(invalidCAFile, []byte("not a certificate"), 0600)
+	scenarios := []struct {
+		Name        string
+		Config      *Config
+		ExpectedErr error
+	}{
+		{Name: "empty", Config: &Config{}},
+		{Name: "server-name", Config: &Config{ServerName: "example.org"}},
+		{Name: "ca-file", Config: &Config{CAFile: certFile}},
+		{Name: "client-certificate", Config: &Config{CertFile: certFile, KeyFile: keyFile}},
+		{Name: "cert-file-without-key-file", Config: &Config{CertFile: certFile}, ExpectedErr: ErrClientCertificateWithoutKey},
+		{Name: "key-file-without-cert-file", Config: &Config{KeyFile: keyFile}, ExpectedErr: ErrClientCertificateWithoutKey},
+		{Name: "invalid-ca-file", Config: &Config{CAFile: invalidCAFile}, ExpectedErr: ErrInvalidCAFile},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			if err := scenario.Config.ValidateAndSetDefaults(); err != scenario.ExpectedErr {
+				t.Errorf("expected error %v, got %v", scenario.ExpectedErr, err)
+			}
+		})
+	}
+	if err := (&Config{CAFile: filepath.Join(directory, "does-not-exist.crt")}).ValidateAndSetDefaults(); err == nil {
+		t.Error("expected an error, because the CA file does not exist")
+	}
+}
+
+func TestConfig_GetTLSConfig(t *testing.T) {
+	var nilConfig *Config
+	if tlsConfig := nilConfig.GetTLSConfig(true, "example.org"); !tlsConfig.InsecureSkipVerify || tlsConfig.ServerName != "example.org" {
+		t.Error("expected a nil configuration to return the default TLS configuration")
+	}
+	config := &Config{ServerName: "internal.example.org"}
+	_ = config.ValidateAndSetDefaults()
+	if tlsConfig := config.GetTLSConfig(false, "example.org"); tlsConfig.ServerName != "internal.example.org" {
+		t.Errorf("expected the configured server name to take precedence, got %s", tlsConfig.ServerName)
+	}
+}
+
+// newMutualTLSServer creates a TLS server that requires a client certificate signed by clientCA
+func newMutualTLSServer(clientCA *x509.Certificate) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCA)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	return server
+}
+
+func TestConfig_GetHTTPClientWithMutualTLS(t *testing.T) {
+	directory := t.TempDir()
+	certFile, keyFile, clientCertificate := writeSelfSignedCertificate(t, directory, "client")
+	server := newMutualTLSServer(clientCertificate)
+	defer server.Close()
+	caFile := filepath.Join(directory, "server-ca.crt")
+	_ = ioutil.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600)
+
+	config := &Config{CAFile: caFile, CertFile: certFile, KeyFile: keyFile}
+	if err := config.ValidateAndSetDefaults(); err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	response, err := config.GetHTTPClient(false, time.Second).Get(server.URL)
+	if err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	_ = response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Errorf("expected status code %d, got %d", http.StatusOK, response.StatusCode)
+	}
+	if config.GetHTTPClient(false, time.Second) != config.GetHTTPClient(false, time.Second) {
+		t.Error("expected the HTTP client to be reused")
+	}
+	// Without the client certificate, the server should reject the connection
+	configWithoutClientCertificate := &Config{CAFile: caFile}
+	_ = configWithoutClientCertificate.ValidateAndSetDefaults()
+	if _, err := configWithoutClientCertificate.GetHTTPClient(false, time.Second).Get(server.URL); err == nil {
+		t.Error("expected an error, because no client certificate was presented")
+	}
+	// Without the CA, the server's certificate should not be trusted
+	configWithoutCA := &Config{CertFile: certFile, KeyFile: keyFile}
+	_ = configWithoutCA.ValidateAndSetDefaults()
+	if _, err := configWithoutCA.GetHTTPClient(false, time.Second).Get(server.URL); err == nil {
+		t.Error("expected an error, because the server's certificate is not signed by a trusted CA")
+	}
+}
+
+func TestCanPerformTLSWithMutualTLS(t *testing.T) {
+	directory := t.TempDir()
+	certFile, keyFile, clientCertificate := writeSelfSignedCertificate(t, directory, "client")
+	server := newMutualTLSServer(clientCertificate)
+	defer server.Close()
+	address := server.Listener.Addr().String()
+	config := &Config{CertFile: certFile, KeyFile: keyFile}
+	_ = config.ValidateAndSetDefaults()
+	connected, state, err := CanPerformTLS(address, config.GetTLSConfig(true, ""), time.Second)
+	if err != nil || !connected {
+		t.Fatalf("expected to be able to connect, got err=%v", err)
+	}
+	if !state.PeerCertificates[0].Equal(server.Certificate()) {
+		t.Error("expected the certificate returned to be the server's certificate")
+	}
+	if connected, _, err = CanPerformTLS(address, (*Config)(nil).GetTLSConfig(false, ""), time.Second); connected || err == nil {
+		t.Error("expected not to be able to connect, because the server's certificate is not trusted")
+	}
+	if _, _, err = CanPerformTLS("invalid-address", nil, time.Second); err == nil {
+		t.Error("expected an error, because the address is invalid")
+	}
+}
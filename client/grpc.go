@@ -0,0 +1,182 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+const (
+	// DefaultGRPCTimeout is the default timeout for the gRPC health checks
+	DefaultGRPCTimeout = 10 * time.Second
+
+	// grpcHealthCheckPath is the path of the Check method of the standard gRPC health checking service
+	grpcHealthCheckPath = "/grpc.health.v1.Health/Check"
+
+	// maximumGRPCResponseSize is the maximum number of bytes read from the response of a gRPC health check
+	maximumGRPCResponseSize = 64 * 1024
+)
+
+var (
+	// ErrInvalidGRPCResponse is the error returned when the response of a gRPC health check could not be decoded
+	ErrInvalidGRPCResponse = errors.New("invalid gRPC health check response")
+
+	// grpcServingStatuses maps the values of the grpc.health.v1.HealthCheckResponse.ServingStatus enum to their name
+	grpcServingStatuses = map[uint64]string{
+		0: "UNKNOWN",
+		1: "SERVING",
+		2: "NOT_SERVING",
+		3: "SERVICE_UNKNOWN",
+	}
+)
+
+// CheckGRPCHealth calls the Check method of the standard gRPC health checking service (grpc.health.v1.Health) and
+// returns the serving status of the given service, such as SERVING or NOT_SERVING. If the service is empty, the
+// overall health of the server is checked.
+//
+// If the TLS configuration is nil, the connection is established in plaintext. The timeout applies to the entire
+// exchange. If the timeout is 0, DefaultGRPCTimeout is used.
+func CheckGRPCHealth(address, service string, tlsConfig *tls.Config, timeout time.Duration) (bool, string, *tls.ConnectionState, error) {
+	if timeout <= 0 {
+		timeout = DefaultGRPCTimeout
+	}
+	scheme := "https"
+	transport := &http2.Transport{TLSClientConfig: tlsConfig}
+	if tlsConfig == nil {
+		scheme = "http"
+		transport.AllowHTTP = true
+		transport.DialTLS = func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return net.DialTimeout(network, addr, timeout)
+		}
+	} else {
+		transport.DialTLS = func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+			conn, err := tls.DialWithDialer(&net.Dialer{Timeout: timeout}, network, addr, cfg)
+			if err != nil {
+				return nil, err
+			}
+			if conn.ConnectionState().NegotiatedProtocol != http2.NextProtoTLS {
+				_ = conn.Close()
+				return nil, errors.New("server does not support HTTP/2")
+			}
+			return conn, nil
+		}
+	}
+	defer transport.CloseIdleConnections()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, (&url.URL{Scheme: scheme, Host: address, Path: grpcHealthCheckPath}).String(), bytes.NewReader(encodeGRPCHealthCheckRequest(service)))
+	if err != nil {
+		return false, "", nil, err
+	}
+	request.Header.Set("Content-Type", "application/grpc")
+	request.Header.Set("TE", "trailers")
+	response, err := transport.RoundTrip(request)
+	if err != nil {
+		return false, "", nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return true, "", response.TLS, fmt.Errorf("unexpected HTTP status %d", response.StatusCode)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(response.Body, maximumGRPCResponseSize))
+	if err != nil {
+		return true, "", response.TLS, err
+	}
+	// The status is sent as a trailer, unless the server responded with an error right away (Trailers-Only)
+	grpcStatus := response.Trailer.Get("Grpc-Status")
+	grpcMessage := response.Trailer.Get("Grpc-Message")
+	if len(grpcStatus) == 0 {
+		grpcStatus = response.Header.Get("Grpc-Status")
+		grpcMessage = response.Header.Get("Grpc-Message")
+	}
+	if grpcStatus != "0" {
+		if message, err := url.PathUnescape(grpcMessage); err == nil {
+			grpcMessage = message
+		}
+		return true, "", response.TLS, fmt.Errorf("gRPC error (code=%s): %s", grpcStatus, grpcMessage)
+	}
+	servingStatus, err := decodeGRPCHealthCheckResponse(body)
+	return true, servingStatus, response.TLS, err
+}
+
+// encodeGRPCHealthCheckRequest encodes a grpc.health.v1.HealthCheckRequest into a length-prefixed gRPC message
+func encodeGRPCHealthCheckRequest(service string) []byte {
+	// message HealthCheckRequest { string service = 1; }
+	var message []byte
+	if len(service) > 0 {
+		message = append(message, 1<<3|2)
+		message = appendVarint(message, uint64(len(service)))
+		message = append(message, service...)
+	}
+	frame := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+	return append(frame, message...)
+}
+
+// decodeGRPCHealthCheckResponse decodes a length-prefixed grpc.health.v1.HealthCheckResponse and returns the name
+// of its serving status
+func decodeGRPCHealthCheckResponse(frame []byte) (string, error) {
+	if len(frame) < 5 || frame[0] != 0 || uint64(binary.BigEndian.Uint32(frame[1:5])) != uint64(len(frame)-5) {
+		return "", ErrInvalidGRPCResponse
+	}
+	// message HealthCheckResponse { ServingStatus status = 1; }
+	var status uint64
+	message := frame[5:]
+	for len(message) > 0 {
+		key, n := binary.Uvarint(message)
+		if n <= 0 {
+			return "", ErrInvalidGRPCResponse
+		}
+		message = message[n:]
+		switch wireType := key & 7; wireType {
+		case 0: // varint
+			value, n := binary.Uvarint(message)
+			if n <= 0 {
+				return "", ErrInvalidGRPCResponse
+			}
+			if key>>3 == 1 {
+				status = value
+			}
+			message = message[n:]
+		case 1, 5: // fixed64, fixed32
+			size := 8
+			if wireType == 5 {
+				size = 4
+			}
+			if len(message) < size {
+				return "", ErrInvalidGRPCResponse
+			}
+			message = message[size:]
+		case 2: // length-delimited
+			length, n := binary.Uvarint(message)
+			if n <= 0 || uint64(len(message)-n) < length {
+				return "", ErrInvalidGRPCResponse
+			}
+			message = message[uint64(n)+length:]
+		default:
+			return "", ErrInvalidGRPCResponse
+		}
+	}
+	if name, ok := grpcServingStatuses[status]; ok {
+		return name, nil
+	}
+	return strconv.FormatUint(status, 10), nil
+}
+
+// appendVarint appends the protobuf base 128 varint encoding of the value to the buffer
+func appendVarint(buffer []byte, value uint64) []byte {
+	varint := make([]byte, binary.MaxVarintLen64)
+	return append(buffer, varint[:binary.PutUvarint(varint, value)]...)
+}
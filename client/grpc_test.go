@@ -0,0 +1,186 @@
+package client
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// grpcHealthHandler is a stand-in for the standard gRPC health checking service, which knows of the service "up",
+// which is serving, and of the service "down", which is not. The overall health of the server is SERVING.
+func grpcHealthHandler(writer http.ResponseWriter, request *http.Request) {
+	if request.URL.Path != grpcHealthCheckPath || request.Header.Get("Content-Type") != "application/grpc" {
+		writer.Header().Set("Content-Type", "application/grpc")
+		writer.Header().Set("Grpc-Status", "12")
+		writer.Header().Set("Grpc-Message", "unknown%20method")
+		return
+	}
+	body, _ := ioutil.ReadAll(request.Body)
+	var status byte
+	switch string(body) {
+	case string(encodeGRPCHealthCheckRequest("")), string(encodeGRPCHealthCheckRequest("up")):
+		status = 1
+	case string(encodeGRPCHealthCheckRequest("down")):
+		status = 2
+	default:
+		// Trailers-Only response
+		writer.Header().Set("Content-Type", "application/grpc")
+		writer.Header().Set("Grpc-Status", "5")
+		writer.Header().Set("Grpc-Message", "unknown%20service")
+		return
+	}
+	writer.Header().Set("Content-Type", "application/grpc")
+	writer.WriteHeader(http.StatusOK)
+	_, _ = writer.Write([]byte{0, 0, 0, 0, 2, 1 << 3, status})
+	writer.Header().Set(http.TrailerPrefix+"Grpc-Status", "0")
+}
+
+func startGRPCServer(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	server := &http2.Server{}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.ServeConn(conn, &http2.ServeConnOpts{Handler: http.HandlerFunc(grpcHealthHandler)})
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestCheckGRPCHealth(t *testing.T) {
+	address := startGRPCServer(t)
+	scenarios := []struct {
+		name           string
+		service        string
+		expectedStatus string
+		expectedError  bool
+	}{
+		{
+			name:           "server",
+			service:        "",
+			expectedStatus: "SERVING",
+		},
+		{
+			name:           "serving",
+			service:        "up",
+			expectedStatus: "SERVING",
+		},
+		{
+			name:           "not-serving",
+			service:        "down",
+			expectedStatus: "NOT_SERVING",
+		},
+		{
+			name:          "unknown-service",
+			service:       "unknown",
+			expectedError: true,
+		},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			connected, status, _, err := CheckGRPCHealth(address, scenario.service, nil, time.Second)
+			if !connected {
+				t.Error("expected to be connected")
+			}
+			if scenario.expectedError != (err != nil) {
+				t.Errorf("expected error to be %v, got %v", scenario.expectedError, err)
+			}
+			if status != scenario.expectedStatus {
+				t.Errorf("expected status to be %s, got %s", scenario.expectedStatus, status)
+			}
+		})
+	}
+}
+
+func TestCheckGRPCHealthWithTLS(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(grpcHealthHandler))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+	connected, status, state, err := CheckGRPCHealth(server.Listener.Addr().String(), "", &tls.Config{InsecureSkipVerify: true}, time.Second)
+	if !connected || err != nil {
+		t.Fatalf("expected to be connected, got err=%v", err)
+	}
+	if status != "SERVING" {
+		t.Errorf("expected status to be SERVING, got %s", status)
+	}
+	if state == nil || len(state.PeerCertificates) == 0 {
+		t.Error("expected the TLS connection state to be returned")
+	}
+}
+
+func TestCheckGRPCHealthWithNoServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	_ = listener.Close()
+	if connected, _, _, err := CheckGRPCHealth(address, "", nil, time.Second); connected || err == nil {
+		t.Error("expected not to be able to connect, because the listener is closed")
+	}
+}
+
+func TestDecodeGRPCHealthCheckResponse(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		frame          []byte
+		expectedStatus string
+		expectedError  bool
+	}{
+		{
+			name:           "serving",
+			frame:          []byte{0, 0, 0, 0, 2, 0x08, 1},
+			expectedStatus: "SERVING",
+		},
+		{
+			name:           "default-value",
+			frame:          []byte{0, 0, 0, 0, 0},
+			expectedStatus: "UNKNOWN",
+		},
+		{
+			name:          "field-without-value",
+			frame:         []byte{0, 0, 0, 0, 7, 0x12, 2, 'h', 'i', 0x08, 2, 0x18},
+			expectedError: true,
+		},
+		{
+			name:           "unknown-fields-skipped",
+			frame:          []byte{0, 0, 0, 0, 6, 0x12, 2, 'h', 'i', 0x08, 2},
+			expectedStatus: "NOT_SERVING",
+		},
+		{
+			name:          "compressed",
+			frame:         []byte{1, 0, 0, 0, 2, 0x08, 1},
+			expectedError: true,
+		},
+		{
+			name:          "truncated",
+			frame:         []byte{0, 0, 0, 0, 2, 0x08},
+			expectedError: true,
+		},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			status, err := decodeGRPCHealthCheckResponse(scenario.frame)
+			if scenario.expectedError != (err != nil) {
+				t.Errorf("expected error to be %v, got %v", scenario.expectedError, err)
+			}
+			if status != scenario.expectedStatus {
+				t.Errorf("expected status to be %s, got %s", scenario.expectedStatus, status)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// DefaultSSHTimeout is the default timeout for an SSH service, including the authentication and the execution
+	// of the command, if any
+	DefaultSSHTimeout = 10 * time.Second
+
+	// maximumSSHServerVersionRecordSize is the maximum number of bytes recorded while looking for the identification
+	// string of the server, which, according to RFC 4253, may be preceded by other lines of data
+	maximumSSHServerVersionRecordSize = 8 * 1024
+)
+
+// SSHResult is the outcome of a successful SSH handshake
+type SSHResult struct {
+	// ServerVersion is the identification string sent by the server (e.g. SSH-2.0-OpenSSH_8.4)
+	ServerVersion string
+
+	// HostKeyFingerprint is the SHA256 fingerprint of the host key of the server (e.g. SHA256:ABCD...)
+	HostKeyFingerprint string
+
+	// ExitCode is the exit code of the command. Only set if a command was executed.
+	ExitCode int
+
+	// Output is the combined standard output and standard error of the command. Only set if a command was executed.
+	Output []byte
+}
+
+// CanPerformSSH checks whether an SSH handshake can be performed with the server at the given address
+//
+// If no authentication method is provided, the check stops after the key exchange, which is enough to retrieve the
+// identification string and the host key of the server. Otherwise, the client authenticates as the given user and,
+// if the command is not empty, executes it. A command exiting with a non-zero exit code is not treated as an error.
+//
+// The host key of the server is not verified. Instead, its fingerprint is returned so that it can be compared with
+// the expected one. The timeout applies to the entire exchange. If the timeout is 0, DefaultSSHTimeout is used.
+func CanPerformSSH(address, username string, authMethods []ssh.AuthMethod, command string, timeout time.Duration) (bool, *SSHResult, error) {
+	if timeout <= 0 {
+		timeout = DefaultSSHTimeout
+	}
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return false, nil, err
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return false, nil, err
+	}
+	recorder := &serverVersionRecorder{Conn: conn}
+	result := &SSHResult{}
+	sshConn, channels, requests, err := ssh.NewClientConn(recorder, address, &ssh.ClientConfig{
+		User: username,
+		Auth: authMethods,
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			result.HostKeyFingerprint = ssh.FingerprintSHA256(key)
+			return nil
+		},
+		Timeout: timeout,
+	})
+	result.ServerVersion = recorder.serverVersion()
+	if err != nil {
+		// The host key callback is only called once the key exchange has taken place, so if there are no
+		// authentication methods, being rejected by the server is the expected outcome
+		if len(result.HostKeyFingerprint) > 0 {
+			if len(authMethods) == 0 {
+				return true, result, nil
+			}
+			return true, result, err
+		}
+		return false, nil, err
+	}
+	client := ssh.NewClient(sshConn, channels, requests)
+	defer client.Close()
+	if len(command) == 0 {
+		return true, result, nil
+	}
+	session, err := client.NewSession()
+	if err != nil {
+		return true, result, err
+	}
+	defer session.Close()
+	result.Output, err = session.CombinedOutput(command)
+	if err != nil {
+		var exitError *ssh.ExitError
+		if !errors.As(err, &exitError) {
+			return true, result, err
+		}
+		result.ExitCode = exitError.ExitStatus()
+	}
+	return true, result, nil
+}
+
+// serverVersionRecorder is a connection that records the data read until the identification string of the server
+// has been received
+type serverVersionRecorder struct {
+	net.Conn
+	data []byte
+}
+
+func (r *serverVersionRecorder) Read(b []byte) (int, error) {
+	n, err := r.Conn.Read(b)
+	if len(r.data) < maximumSSHServerVersionRecordSize && len(r.serverVersion()) == 0 {
+		r.data = append(r.data, b[:n]...)
+	}
+	return n, err
+}
+
+// serverVersion returns the identification string of the server, or an empty string if it has not been received yet
+func (r *serverVersionRecorder) serverVersion() string {
+	data := r.data
+	for {
+		lineEnd := bytes.IndexByte(data, '\n')
+		if lineEnd == -1 {
+			return ""
+		}
+		line := strings.TrimRight(string(data[:lineEnd]), "\r")
+		if strings.HasPrefix(line, "SSH-") {
+			return line
+		}
+		data = data[lineEnd+1:]
+	}
+}
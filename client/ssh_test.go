@@ -0,0 +1,167 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// startSSHServer starts an SSH server that accepts the password "password" for the user "gatus", and which executes
+// commands by echoing them back. Commands starting with "exit " exit with the given exit code.
+func startSSHServer(t *testing.T) (string, ssh.PublicKey) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostKey, err := ssh.NewSignerFromKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverConfig := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == "gatus" && string(password) == "password" {
+				return nil, nil
+			}
+			return nil, errors.New("invalid credentials")
+		},
+	}
+	serverConfig.AddHostKey(hostKey)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go handleSSHConnection(conn, serverConfig)
+		}
+	}()
+	return listener.Addr().String(), hostKey.PublicKey()
+}
+
+func handleSSHConnection(conn net.Conn, serverConfig *ssh.ServerConfig) {
+	defer conn.Close()
+	_, channels, requests, err := ssh.NewServerConn(conn, serverConfig)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+	for newChannel := range channels {
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		for request := range channelRequests {
+			if request.Type != "exec" {
+				_ = request.Reply(false, nil)
+				continue
+			}
+			_ = request.Reply(true, nil)
+			// The payload of an exec request is the command, prefixed by its length
+			command := string(request.Payload[4:])
+			exitStatus := make([]byte, 4)
+			if strings.HasPrefix(command, "exit ") {
+				exitCode, _ := strconv.Atoi(strings.TrimPrefix(command, "exit "))
+				binary.BigEndian.PutUint32(exitStatus, uint32(exitCode))
+			}
+			_, _ = channel.Write([]byte(command))
+			_, _ = channel.SendRequest("exit-status", false, exitStatus)
+			_ = channel.Close()
+		}
+	}
+}
+
+func TestCanPerformSSH(t *testing.T) {
+	address, hostKey := startSSHServer(t)
+	scenarios := []struct {
+		Name             string
+		Username         string
+		AuthMethods      []ssh.AuthMethod
+		Command          string
+		ExpectedError    bool
+		ExpectedExitCode int
+		ExpectedOutput   string
+	}{
+		{
+			Name: "handshake-only",
+		},
+		{
+			Name:        "authentication-only",
+			Username:    "gatus",
+			AuthMethods: []ssh.AuthMethod{ssh.Password("password")},
+		},
+		{
+			Name:           "command",
+			Username:       "gatus",
+			AuthMethods:    []ssh.AuthMethod{ssh.Password("password")},
+			Command:        "uptime",
+			ExpectedOutput: "uptime",
+		},
+		{
+			Name:             "command-with-non-zero-exit-code",
+			Username:         "gatus",
+			AuthMethods:      []ssh.AuthMethod{ssh.Password("password")},
+			Command:          "exit 3",
+			ExpectedExitCode: 3,
+			ExpectedOutput:   "exit 3",
+		},
+		{
+			Name:          "invalid-credentials",
+			Username:      "gatus",
+			AuthMethods:   []ssh.AuthMethod{ssh.Password("bad-password")},
+			Command:       "uptime",
+			ExpectedError: true,
+		},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			connected, result, err := CanPerformSSH(address, scenario.Username, scenario.AuthMethods, scenario.Command, 5*time.Second)
+			if scenario.ExpectedError && err == nil {
+				t.Error("expected an error, got none")
+			}
+			if !scenario.ExpectedError && err != nil {
+				t.Error("expected no error, got", err.Error())
+			}
+			if !connected || result == nil {
+				t.Fatal("expected the handshake to have been performed")
+			}
+			if !strings.HasPrefix(result.ServerVersion, "SSH-2.0-") {
+				t.Errorf("expected server version to start with SSH-2.0-, got %s", result.ServerVersion)
+			}
+			if result.HostKeyFingerprint != ssh.FingerprintSHA256(hostKey) {
+				t.Errorf("expected host key fingerprint to be %s, got %s", ssh.FingerprintSHA256(hostKey), result.HostKeyFingerprint)
+			}
+			if result.ExitCode != scenario.ExpectedExitCode {
+				t.Errorf("expected exit code to be %d, got %d", scenario.ExpectedExitCode, result.ExitCode)
+			}
+			if string(result.Output) != scenario.ExpectedOutput {
+				t.Errorf("expected output to be %q, got %q", scenario.ExpectedOutput, string(result.Output))
+			}
+		})
+	}
+}
+
+func TestCanPerformSSHWithNoServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	_ = listener.Close()
+	if connected, _, err := CanPerformSSH(address, "", nil, "", time.Second); connected || err == nil {
+		t.Error("expected not to be able to connect, because the listener is closed")
+	}
+}
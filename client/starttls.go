@@ -0,0 +1,380 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// StartTLSProtocol is the protocol used to negotiate the upgrade of a plain text connection to TLS
+type StartTLSProtocol string
+
+const (
+	// StartTLSProtocolSMTP is the StartTLSProtocol for SMTP, which uses the STARTTLS command
+	StartTLSProtocolSMTP StartTLSProtocol = "smtp"
+
+	// StartTLSProtocolIMAP is the StartTLSProtocol for IMAP, which uses the STARTTLS command
+	StartTLSProtocolIMAP StartTLSProtocol = "imap"
+
+	// StartTLSProtocolPOP3 is the StartTLSProtocol for POP3, which uses the STLS command
+	StartTLSProtocolPOP3 StartTLSProtocol = "pop3"
+
+	// StartTLSProtocolLDAP is the StartTLSProtocol for LDAP, which uses the StartTLS extended operation
+	StartTLSProtocolLDAP StartTLSProtocol = "ldap"
+
+	// StartTLSProtocolXMPP is the StartTLSProtocol for XMPP, which uses the starttls stream feature
+	StartTLSProtocolXMPP StartTLSProtocol = "xmpp"
+
+	// StartTLSProtocolFTP is the StartTLSProtocol for FTP, which uses the AUTH TLS command
+	StartTLSProtocolFTP StartTLSProtocol = "ftp"
+
+	// StartTLSProtocolPostgreSQL is the StartTLSProtocol for PostgreSQL, which uses the SSLRequest message
+	StartTLSProtocolPostgreSQL StartTLSProtocol = "postgres"
+)
+
+const (
+	// maximumStartTLSResponseSize is the maximum number of bytes read from the server before the TLS handshake
+	maximumStartTLSResponseSize = 64 * 1024
+
+	// ldapStartTLSOID is the OID of the LDAP StartTLS extended operation (RFC 4511)
+	ldapStartTLSOID = "1.3.6.1.4.1.1466.20037"
+
+	// postgreSQLSSLRequestCode is the code of the SSLRequest message of the PostgreSQL protocol
+	postgreSQLSSLRequestCode = 80877103
+)
+
+var (
+	// ErrInvalidStartTLSProtocol is the error returned when the STARTTLS protocol is not supported
+	ErrInvalidStartTLSProtocol = errors.New("invalid starttls protocol, supported protocols are smtp, imap, pop3, ldap, xmpp, ftp and postgres")
+
+	// ErrStartTLSRejected is the error returned when the server refuses to upgrade the connection to TLS
+	ErrStartTLSRejected = errors.New("server refused to upgrade the connection to tls")
+)
+
+// IsValid returns whether the STARTTLS protocol is supported. An empty protocol is valid and defaults to SMTP.
+func (protocol StartTLSProtocol) IsValid() bool {
+	switch protocol {
+	case "", StartTLSProtocolSMTP, StartTLSProtocolIMAP, StartTLSProtocolPOP3, StartTLSProtocolLDAP, StartTLSProtocolXMPP, StartTLSProtocolFTP, StartTLSProtocolPostgreSQL:
+		return true
+	}
+	return false
+}
+
+// CanPerformStartTLS checks whether a connection can be established to an address using the STARTTLS protocol
+//
+// The protocol determines how the upgrade to TLS is negotiated. If the protocol is empty, SMTP is used.
+// If the server name of the TLS configuration is empty, the host of the address is used.
+// The timeout applies to the entire exchange. If the timeout is 0, DefaultTCPTimeout is used.
+func CanPerformStartTLS(address string, protocol StartTLSProtocol, tlsConfig *tls.Config, timeout time.Duration) (connected bool, state *tls.ConnectionState, err error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false, nil, errors.New("invalid address for starttls, format must be host:port")
+	}
+	if !protocol.IsValid() {
+		return false, nil, ErrInvalidStartTLSProtocol
+	}
+	tlsConfig = withDefaultServerName(tlsConfig, host)
+	if timeout <= 0 {
+		timeout = DefaultTCPTimeout
+	}
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return
+	}
+	var connectionState tls.ConnectionState
+	if protocol == StartTLSProtocolSMTP || len(protocol) == 0 {
+		if connectionState, err = startTLSWithSMTP(conn, host, tlsConfig); err != nil {
+			return
+		}
+	} else {
+		reader := bufio.NewReader(io.LimitReader(conn, maximumStartTLSResponseSize))
+		switch protocol {
+		case StartTLSProtocolIMAP:
+			err = negotiateStartTLSWithIMAP(conn, reader)
+		case StartTLSProtocolPOP3:
+			err = negotiateStartTLSWithPOP3(conn, reader)
+		case StartTLSProtocolLDAP:
+			err = negotiateStartTLSWithLDAP(conn, reader)
+		case StartTLSProtocolXMPP:
+			err = negotiateStartTLSWithXMPP(conn, reader, host)
+		case StartTLSProtocolFTP:
+			err = negotiateStartTLSWithFTP(conn, reader)
+		case StartTLSProtocolPostgreSQL:
+			err = negotiateStartTLSWithPostgreSQL(conn, reader)
+		}
+		if err != nil {
+			return
+		}
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err = tlsConn.Handshake(); err != nil {
+			return
+		}
+		connectionState = tlsConn.ConnectionState()
+	}
+	if len(connectionState.PeerCertificates) == 0 {
+		return false, nil, errors.New("no certificate presented by the server")
+	}
+	return true, &connectionState, nil
+}
+
+func startTLSWithSMTP(conn net.Conn, host string, tlsConfig *tls.Config) (tls.ConnectionState, error) {
+	smtpClient, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
+	if err = smtpClient.StartTLS(tlsConfig); err != nil {
+		return tls.ConnectionState{}, err
+	}
+	connectionState, ok := smtpClient.TLSConnectionState()
+	if !ok {
+		return tls.ConnectionState{}, errors.New("could not get TLS connection state")
+	}
+	return connectionState, nil
+}
+
+// readLine reads a single line, without the trailing CRLF
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// negotiateStartTLSWithIMAP negotiates the upgrade to TLS using the STARTTLS command of IMAP (RFC 3501)
+func negotiateStartTLSWithIMAP(conn net.Conn, reader *bufio.Reader) error {
+	greeting, err := readLine(reader)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(greeting, "* OK") {
+		return fmt.Errorf("unexpected imap greeting: %s", greeting)
+	}
+	if _, err = conn.Write([]byte("a001 STARTTLS\r\n")); err != nil {
+		return err
+	}
+	for {
+		line, err := readLine(reader)
+		if err != nil {
+			return err
+		}
+		// Untagged responses may be sent before the tagged response
+		if strings.HasPrefix(line, "* ") {
+			continue
+		}
+		if strings.HasPrefix(line, "a001 OK") {
+			return nil
+		}
+		return fmt.Errorf("%w: %s", ErrStartTLSRejected, line)
+	}
+}
+
+// negotiateStartTLSWithPOP3 negotiates the upgrade to TLS using the STLS command of POP3 (RFC 2595)
+func negotiateStartTLSWithPOP3(conn net.Conn, reader *bufio.Reader) error {
+	greeting, err := readLine(reader)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(greeting, "+OK") {
+		return fmt.Errorf("unexpected pop3 greeting: %s", greeting)
+	}
+	if _, err = conn.Write([]byte("STLS\r\n")); err != nil {
+		return err
+	}
+	response, err := readLine(reader)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(response, "+OK") {
+		return fmt.Errorf("%w: %s", ErrStartTLSRejected, response)
+	}
+	return nil
+}
+
+// readFTPResponse reads a potentially multi-line FTP response and returns its code as well as its last line
+func readFTPResponse(reader *bufio.Reader) (string, string, error) {
+	line, err := readLine(reader)
+	if err != nil {
+		return "", "", err
+	}
+	if len(line) < 4 {
+		return "", "", fmt.Errorf("unexpected ftp response: %s", line)
+	}
+	code := line[:3]
+	if line[3] == '-' {
+		// Multi-line responses end with a line starting with the code followed by a space
+		for !strings.HasPrefix(line, code+" ") {
+			if line, err = readLine(reader); err != nil {
+				return "", "", err
+			}
+		}
+	}
+	return code, line, nil
+}
+
+// negotiateStartTLSWithFTP negotiates the upgrade to TLS using the AUTH TLS command of FTP (RFC 4217)
+func negotiateStartTLSWithFTP(conn net.Conn, reader *bufio.Reader) error {
+	code, line, err := readFTPResponse(reader)
+	if err != nil {
+		return err
+	}
+	if code != "220" {
+		return fmt.Errorf("unexpected ftp greeting: %s", line)
+	}
+	if _, err = conn.Write([]byte("AUTH TLS\r\n")); err != nil {
+		return err
+	}
+	if code, line, err = readFTPResponse(reader); err != nil {
+		return err
+	}
+	if code != "234" {
+		return fmt.Errorf("%w: %s", ErrStartTLSRejected, line)
+	}
+	return nil
+}
+
+// negotiateStartTLSWithXMPP negotiates the upgrade to TLS using the STARTTLS negotiation of XMPP (RFC 6120)
+func negotiateStartTLSWithXMPP(conn net.Conn, reader *bufio.Reader, host string) error {
+	streamHeader := fmt.Sprintf("<?xml version='1.0'?><stream:stream to='%s' xmlns='jabber:client' xmlns:stream='http://etherx.jabber.org/streams' version='1.0'>", host)
+	if _, err := conn.Write([]byte(streamHeader)); err != nil {
+		return err
+	}
+	features, err := readUntil(reader, "</stream:features>")
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(features, "<starttls") {
+		return fmt.Errorf("%w: starttls is not part of the stream features", ErrStartTLSRejected)
+	}
+	if _, err = conn.Write([]byte("<starttls xmlns='urn:ietf:params:xml:ns:xmpp-tls'/>")); err != nil {
+		return err
+	}
+	response, err := readUntil(reader, ">")
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(response, "<proceed") {
+		return fmt.Errorf("%w: %s", ErrStartTLSRejected, response)
+	}
+	return nil
+}
+
+// readUntil reads from the reader until the delimiter is found and returns everything read, including the delimiter
+//
+// An error is returned if the delimiter isn't found within the first maximumStartTLSResponseSize bytes.
+func readUntil(reader *bufio.Reader, delimiter string) (string, error) {
+	var buffer bytes.Buffer
+	suffix := []byte(delimiter)
+	for !bytes.HasSuffix(buffer.Bytes(), suffix) {
+		if buffer.Len() >= maximumStartTLSResponseSize {
+			return "", fmt.Errorf("%s not found within the first %d bytes", delimiter, maximumStartTLSResponseSize)
+		}
+		b, err := reader.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		buffer.WriteByte(b)
+	}
+	return buffer.String(), nil
+}
+
+// negotiateStartTLSWithPostgreSQL negotiates the upgrade to TLS using the SSLRequest message of PostgreSQL
+func negotiateStartTLSWithPostgreSQL(conn net.Conn, reader *bufio.Reader) error {
+	request := make([]byte, 8)
+	binary.BigEndian.PutUint32(request[0:4], 8)
+	binary.BigEndian.PutUint32(request[4:8], postgreSQLSSLRequestCode)
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+	response, err := reader.ReadByte()
+	if err != nil {
+		return err
+	}
+	if response != 'S' {
+		return fmt.Errorf("%w: server responded with '%c'", ErrStartTLSRejected, response)
+	}
+	return nil
+}
+
+// negotiateStartTLSWithLDAP negotiates the upgrade to TLS using the StartTLS extended operation of LDAP (RFC 4511)
+func negotiateStartTLSWithLDAP(conn net.Conn, reader *bufio.Reader) error {
+	// LDAPMessage ::= SEQUENCE { messageID 1, ExtendedRequest [APPLICATION 23] { requestName [0] ldapStartTLSOID } }
+	extendedRequest := append([]byte{0x80, byte(len(ldapStartTLSOID))}, ldapStartTLSOID...)
+	protocolOp := append([]byte{0x77, byte(len(extendedRequest))}, extendedRequest...)
+	message := append([]byte{0x02, 0x01, 0x01}, protocolOp...)
+	if _, err := conn.Write(append([]byte{0x30, byte(len(message))}, message...)); err != nil {
+		return err
+	}
+	tag, content, err := readBERElement(reader)
+	if err != nil {
+		return err
+	}
+	if tag != 0x30 {
+		return fmt.Errorf("unexpected ldap response tag 0x%x", tag)
+	}
+	contentReader := bufio.NewReader(bytes.NewReader(content))
+	// Skip the messageID
+	if _, _, err = readBERElement(contentReader); err != nil {
+		return err
+	}
+	tag, extendedResponse, err := readBERElement(contentReader)
+	if err != nil {
+		return err
+	}
+	// ExtendedResponse is [APPLICATION 24], and its first element is the resultCode, an ENUMERATED
+	if tag != 0x78 || len(extendedResponse) < 3 || extendedResponse[0] != 0x0a || extendedResponse[1] != 0x01 {
+		return errors.New("unexpected ldap extended response")
+	}
+	if resultCode := extendedResponse[2]; resultCode != 0 {
+		return fmt.Errorf("%w: ldap result code %d", ErrStartTLSRejected, resultCode)
+	}
+	return nil
+}
+
+// readBERElement reads a single BER encoded element and returns its tag and its content
+func readBERElement(reader *bufio.Reader) (byte, []byte, error) {
+	tag, err := reader.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	lengthByte, err := reader.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length := int(lengthByte)
+	if lengthByte&0x80 != 0 {
+		// Long form: the lower bits are the number of bytes used to encode the length
+		numberOfBytes := int(lengthByte & 0x7f)
+		if numberOfBytes == 0 || numberOfBytes > 3 {
+			return 0, nil, errors.New("unsupported ber length")
+		}
+		length = 0
+		for i := 0; i < numberOfBytes; i++ {
+			b, err := reader.ReadByte()
+			if err != nil {
+				return 0, nil, err
+			}
+			length = length<<8 | int(b)
+		}
+	}
+	if length > maximumStartTLSResponseSize {
+		return 0, nil, errors.New("ber element too large")
+	}
+	content := make([]byte, length)
+	if _, err = io.ReadFull(reader, content); err != nil {
+		return 0, nil, err
+	}
+	return tag, content, nil
+}
@@ -0,0 +1,299 @@
+package client
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startTLSServerStandIn starts a server that negotiates the upgrade to TLS of a single protocol using the given
+// function, after which it performs the TLS handshake. If the negotiation function returns false, the connection is
+// closed without performing the TLS handshake. If afterHandshake is not nil, it is called with the upgraded connection.
+func startTLSServerStandIn(t *testing.T, negotiate func(conn net.Conn, reader *bufio.Reader) bool, afterHandshake func(conn *tls.Conn)) string {
+	certFile, keyFile, _ := writeSelfSignedCertificate(t, t.TempDir(), "server")
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+				if !negotiate(conn, bufio.NewReader(conn)) {
+					return
+				}
+				tlsConn := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{certificate}})
+				if err := tlsConn.Handshake(); err != nil || afterHandshake == nil {
+					return
+				}
+				afterHandshake(tlsConn)
+			}(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func readLineFromClient(reader *bufio.Reader) string {
+	line, _ := reader.ReadString('\n')
+	return strings.TrimRight(line, "\r\n")
+}
+
+func TestCanPerformStartTLSWithProtocols(t *testing.T) {
+	scenarios := []struct {
+		Name           string
+		Protocol       StartTLSProtocol
+		Negotiate      func(conn net.Conn, reader *bufio.Reader) bool
+		AfterHandshake func(conn *tls.Conn)
+	}{
+		{
+			Name:     "smtp",
+			Protocol: StartTLSProtocolSMTP,
+			Negotiate: func(conn net.Conn, reader *bufio.Reader) bool {
+				_, _ = conn.Write([]byte("220 localhost ESMTP\r\n"))
+				if !strings.HasPrefix(readLineFromClient(reader), "EHLO") {
+					return false
+				}
+				_, _ = conn.Write([]byte("250-localhost\r\n250 STARTTLS\r\n"))
+				if readLineFromClient(reader) != "STARTTLS" {
+					return false
+				}
+				_, _ = conn.Write([]byte("220 Ready to start TLS\r\n"))
+				return true
+			},
+			AfterHandshake: func(conn *tls.Conn) {
+				// net/smtp sends EHLO once more after the upgrade
+				if strings.HasPrefix(readLineFromClient(bufio.NewReader(conn)), "EHLO") {
+					_, _ = conn.Write([]byte("250 localhost\r\n"))
+				}
+			},
+		},
+		{
+			Name:     "imap",
+			Protocol: StartTLSProtocolIMAP,
+			Negotiate: func(conn net.Conn, reader *bufio.Reader) bool {
+				_, _ = conn.Write([]byte("* OK IMAP4rev1 Service Ready\r\n"))
+				if readLineFromClient(reader) != "a001 STARTTLS" {
+					return false
+				}
+				_, _ = conn.Write([]byte("* CAPABILITY IMAP4rev1\r\na001 OK Begin TLS negotiation now\r\n"))
+				return true
+			},
+		},
+		{
+			Name:     "pop3",
+			Protocol: StartTLSProtocolPOP3,
+			Negotiate: func(conn net.Conn, reader *bufio.Reader) bool {
+				_, _ = conn.Write([]byte("+OK POP3 server ready\r\n"))
+				if readLineFromClient(reader) != "STLS" {
+					return false
+				}
+				_, _ = conn.Write([]byte("+OK Begin TLS negotiation\r\n"))
+				return true
+			},
+		},
+		{
+			Name:     "ftp",
+			Protocol: StartTLSProtocolFTP,
+			Negotiate: func(conn net.Conn, reader *bufio.Reader) bool {
+				_, _ = conn.Write([]byte("220-Welcome\r\n220-to the\r\n220 FTP server\r\n"))
+				if readLineFromClient(reader) != "AUTH TLS" {
+					return false
+				}
+				_, _ = conn.Write([]byte("234 Proceed with negotiation.\r\n"))
+				return true
+			},
+		},
+		{
+			Name:     "xmpp",
+			Protocol: StartTLSProtocolXMPP,
+			Negotiate: func(conn net.Conn, reader *bufio.Reader) bool {
+				header, _ := reader.ReadString('>') // <?xml ...?>
+				streamHeader, _ := reader.ReadString('>')
+				if !strings.HasPrefix(header, "<?xml") || !strings.Contains(streamHeader, "to='127.0.0.1'") {
+					return false
+				}
+				_, _ = conn.Write([]byte("<?xml version='1.0'?><stream:stream from='127.0.0.1' id='1' version='1.0' xmlns='jabber:client' xmlns:stream='http://etherx.jabber.org/streams'>" +
+					"<stream:features><starttls xmlns='urn:ietf:params:xml:ns:xmpp-tls'><required/></starttls></stream:features>"))
+				if request, _ := reader.ReadString('>'); !strings.HasPrefix(request, "<starttls") {
+					return false
+				}
+				_, _ = conn.Write([]byte("<proceed xmlns='urn:ietf:params:xml:ns:xmpp-tls'/>"))
+				return true
+			},
+		},
+		{
+			Name:     "postgres",
+			Protocol: StartTLSProtocolPostgreSQL,
+			Negotiate: func(conn net.Conn, reader *bufio.Reader) bool {
+				request := make([]byte, 8)
+				if _, err := io.ReadFull(reader, request); err != nil {
+					return false
+				}
+				if binary.BigEndian.Uint32(request[0:4]) != 8 || binary.BigEndian.Uint32(request[4:8]) != postgreSQLSSLRequestCode {
+					return false
+				}
+				_, _ = conn.Write([]byte{'S'})
+				return true
+			},
+		},
+		{
+			Name:     "ldap",
+			Protocol: StartTLSProtocolLDAP,
+			Negotiate: func(conn net.Conn, reader *bufio.Reader) bool {
+				tag, content, err := readBERElement(reader)
+				if err != nil || tag != 0x30 || !strings.Contains(string(content), ldapStartTLSOID) {
+					return false
+				}
+				// LDAPMessage { messageID 1, ExtendedResponse { resultCode success, matchedDN "", diagnosticMessage "" } }
+				_, _ = conn.Write([]byte{0x30, 0x0c, 0x02, 0x01, 0x01, 0x78, 0x07, 0x0a, 0x01, 0x00, 0x04, 0x00, 0x04, 0x00})
+				return true
+			},
+		},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			address := startTLSServerStandIn(t, scenario.Negotiate, scenario.AfterHandshake)
+			connected, state, err := CanPerformStartTLS(address, scenario.Protocol, &tls.Config{InsecureSkipVerify: true}, time.Second)
+			if err != nil {
+				t.Fatal("expected no error, got", err.Error())
+			}
+			if !connected || state == nil || len(state.PeerCertificates) == 0 {
+				t.Error("expected the connection to have been upgraded to TLS")
+			}
+		})
+	}
+}
+
+func TestCanPerformStartTLSWhenRejected(t *testing.T) {
+	scenarios := []struct {
+		Name      string
+		Protocol  StartTLSProtocol
+		Negotiate func(conn net.Conn, reader *bufio.Reader) bool
+	}{
+		{
+			Name:     "imap",
+			Protocol: StartTLSProtocolIMAP,
+			Negotiate: func(conn net.Conn, reader *bufio.Reader) bool {
+				_, _ = conn.Write([]byte("* OK IMAP4rev1 Service Ready\r\n"))
+				readLineFromClient(reader)
+				_, _ = conn.Write([]byte("a001 BAD STARTTLS not supported\r\n"))
+				return false
+			},
+		},
+		{
+			Name:     "pop3",
+			Protocol: StartTLSProtocolPOP3,
+			Negotiate: func(conn net.Conn, reader *bufio.Reader) bool {
+				_, _ = conn.Write([]byte("+OK POP3 server ready\r\n"))
+				readLineFromClient(reader)
+				_, _ = conn.Write([]byte("-ERR STLS not supported\r\n"))
+				return false
+			},
+		},
+		{
+			Name:     "ftp",
+			Protocol: StartTLSProtocolFTP,
+			Negotiate: func(conn net.Conn, reader *bufio.Reader) bool {
+				_, _ = conn.Write([]byte("220 FTP server\r\n"))
+				readLineFromClient(reader)
+				_, _ = conn.Write([]byte("502 Command not implemented\r\n"))
+				return false
+			},
+		},
+		{
+			Name:     "xmpp",
+			Protocol: StartTLSProtocolXMPP,
+			Negotiate: func(conn net.Conn, reader *bufio.Reader) bool {
+				_, _ = reader.ReadString('>')
+				_, _ = reader.ReadString('>')
+				_, _ = conn.Write([]byte("<stream:stream><stream:features><mechanisms/></stream:features>"))
+				return false
+			},
+		},
+		{
+			Name:     "postgres",
+			Protocol: StartTLSProtocolPostgreSQL,
+			Negotiate: func(conn net.Conn, reader *bufio.Reader) bool {
+				_, _ = io.ReadFull(reader, make([]byte, 8))
+				_, _ = conn.Write([]byte{'N'})
+				return false
+			},
+		},
+		{
+			Name:     "ldap",
+			Protocol: StartTLSProtocolLDAP,
+			Negotiate: func(conn net.Conn, reader *bufio.Reader) bool {
+				_, _, _ = readBERElement(reader)
+				// resultCode 2 (protocolError)
+				_, _ = conn.Write([]byte{0x30, 0x0c, 0x02, 0x01, 0x01, 0x78, 0x07, 0x0a, 0x01, 0x02, 0x04, 0x00, 0x04, 0x00})
+				return false
+			},
+		},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			address := startTLSServerStandIn(t, scenario.Negotiate, nil)
+			connected, _, err := CanPerformStartTLS(address, scenario.Protocol, &tls.Config{InsecureSkipVerify: true}, time.Second)
+			if err == nil || connected {
+				t.Error("expected an error, because the server refused to upgrade the connection to TLS")
+			}
+		})
+	}
+}
+
+func TestCanPerformStartTLSWithInvalidProtocol(t *testing.T) {
+	if _, _, err := CanPerformStartTLS("127.0.0.1:25", "gopher", nil, time.Second); err != ErrInvalidStartTLSProtocol {
+		t.Errorf("expected error %v, got %v", ErrInvalidStartTLSProtocol, err)
+	}
+}
+
+func TestReadUntil(t *testing.T) {
+	scenarios := []struct {
+		Name           string
+		Input          string
+		ExpectedOutput string
+		ExpectedError  bool
+	}{
+		{
+			Name:           "delimiter-found",
+			Input:          "<stream:features><starttls/></stream:features><proceed/>",
+			ExpectedOutput: "<stream:features><starttls/></stream:features>",
+		},
+		{
+			Name:          "delimiter-not-found",
+			Input:         "<stream:features><starttls/>",
+			ExpectedError: true,
+		},
+		{
+			Name:          "delimiter-beyond-maximum-size",
+			Input:         strings.Repeat(" ", maximumStartTLSResponseSize) + "</stream:features>",
+			ExpectedError: true,
+		},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			output, err := readUntil(bufio.NewReader(strings.NewReader(scenario.Input)), "</stream:features>")
+			if (err != nil) != scenario.ExpectedError {
+				t.Errorf("expected error to be %v, got %v", scenario.ExpectedError, err)
+			}
+			if output != scenario.ExpectedOutput {
+				t.Errorf("expected output to be %s, got %s", scenario.ExpectedOutput, output)
+			}
+		})
+	}
+}
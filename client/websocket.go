@@ -0,0 +1,80 @@
+package client
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// MaximumWebSocketMessageSize is the maximum size of the message read from a WebSocket service
+	MaximumWebSocketMessageSize = 64 * 1024
+)
+
+// QueryWebSocket performs the WebSocket opening handshake with the given endpoint (ws:// or wss://), sends the given
+// payload, if any, as a text message and, if readMessage is true, waits for the first message sent by the server.
+//
+// The TLS configuration is only used for wss:// endpoints, and the TLS connection state is only returned for these.
+// The timeout applies to the entire exchange. If the timeout is 0, GetDefaultHTTPTimeout is used.
+func QueryWebSocket(endpoint string, headers http.Header, payload []byte, readMessage bool, tlsConfig *tls.Config, timeout time.Duration) (bool, []byte, *tls.ConnectionState, error) {
+	if timeout <= 0 {
+		timeout = GetDefaultHTTPTimeout()
+	}
+	// The origin is mandatory, but since this isn't a browser, the endpoint itself is used as the origin
+	config, err := websocket.NewConfig(endpoint, strings.Replace(endpoint, "ws", "http", 1))
+	if err != nil {
+		return false, nil, nil, err
+	}
+	config.Header = headers
+	address := config.Location.Host
+	if len(config.Location.Port()) == 0 {
+		if config.Location.Scheme == "wss" {
+			address = net.JoinHostPort(config.Location.Hostname(), "443")
+		} else {
+			address = net.JoinHostPort(config.Location.Hostname(), "80")
+		}
+	}
+	var conn net.Conn
+	var state *tls.ConnectionState
+	if config.Location.Scheme == "wss" {
+		tlsConn, err := tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", address, withDefaultServerName(tlsConfig, config.Location.Hostname()))
+		if err != nil {
+			return false, nil, nil, err
+		}
+		connectionState := tlsConn.ConnectionState()
+		conn, state = tlsConn, &connectionState
+	} else if config.Location.Scheme == "ws" {
+		if conn, err = net.DialTimeout("tcp", address, timeout); err != nil {
+			return false, nil, nil, err
+		}
+	} else {
+		return false, nil, nil, websocket.ErrBadScheme
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return false, nil, state, err
+	}
+	ws, err := websocket.NewClient(config, conn)
+	if err != nil {
+		return false, nil, state, &url.Error{Op: "Handshake", URL: endpoint, Err: err}
+	}
+	ws.MaxPayloadBytes = MaximumWebSocketMessageSize
+	if len(payload) > 0 {
+		if err = websocket.Message.Send(ws, string(payload)); err != nil {
+			return true, nil, state, err
+		}
+	}
+	if !readMessage {
+		return true, nil, state, nil
+	}
+	var message []byte
+	if err = websocket.Message.Receive(ws, &message); err != nil {
+		return true, nil, state, err
+	}
+	return true, message, state, nil
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// webSocketEchoHandler replies to every message with the same message in uppercase, and rejects handshakes without
+// the header X-Token
+var webSocketEchoHandler = websocket.Server{
+	Handshake: func(config *websocket.Config, request *http.Request) error {
+		if request.Header.Get("X-Token") != "secret" {
+			return errors.New("missing token")
+		}
+		return nil
+	},
+	Handler: func(ws *websocket.Conn) {
+		var message string
+		for websocket.Message.Receive(ws, &message) == nil {
+			_ = websocket.Message.Send(ws, strings.ToUpper(message))
+		}
+	},
+}
+
+func TestQueryWebSocket(t *testing.T) {
+	server := httptest.NewServer(webSocketEchoHandler)
+	defer server.Close()
+	endpoint := strings.Replace(server.URL, "http", "ws", 1)
+	headers := http.Header{"X-Token": []string{"secret"}}
+	connected, message, state, err := QueryWebSocket(endpoint, headers, []byte(`{"status":"up"}`), true, nil, time.Second)
+	if !connected || err != nil {
+		t.Fatalf("expected to be connected, got err=%v", err)
+	}
+	if string(message) != `{"STATUS":"UP"}` {
+		t.Errorf("expected message to be %s, got %s", `{"STATUS":"UP"}`, string(message))
+	}
+	if state != nil {
+		t.Error("expected no TLS connection state, because the endpoint does not use TLS")
+	}
+	// Without a payload, the server doesn't send anything, so waiting for a message should time out
+	connected, _, _, err = QueryWebSocket(endpoint, headers, nil, true, nil, 100*time.Millisecond)
+	if !connected || err == nil {
+		t.Error("expected to be connected and to have timed out while waiting for a message")
+	}
+	connected, _, _, err = QueryWebSocket(endpoint, headers, nil, false, nil, time.Second)
+	if !connected || err != nil {
+		t.Errorf("expected to be connected, got err=%v", err)
+	}
+	// Without the token, the handshake should be rejected
+	if connected, _, _, err = QueryWebSocket(endpoint, nil, nil, false, nil, time.Second); connected || err == nil {
+		t.Error("expected the handshake to have been rejected")
+	}
+}
+
+func TestQueryWebSocketWithTLS(t *testing.T) {
+	server := httptest.NewTLSServer(webSocketEchoHandler)
+	defer server.Close()
+	endpoint := strings.Replace(server.URL, "https", "wss", 1)
+	connected, message, state, err := QueryWebSocket(endpoint, http.Header{"X-Token": []string{"secret"}}, []byte("ping"), true, &tls.Config{InsecureSkipVerify: true}, time.Second)
+	if !connected || err != nil {
+		t.Fatalf("expected to be connected, got err=%v", err)
+	}
+	if string(message) != "PING" {
+		t.Errorf("expected message to be PING, got %s", string(message))
+	}
+	if state == nil || len(state.PeerCertificates) == 0 {
+		t.Error("expected the TLS connection state to be returned")
+	}
+}
+
+func TestQueryWebSocketWithInvalidScheme(t *testing.T) {
+	if connected, _, _, err := QueryWebSocket("http://127.0.0.1", nil, nil, false, nil, time.Second); connected || err == nil {
+		t.Error("expected an error, because the scheme is not ws or wss")
+	}
+}
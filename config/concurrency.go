@@ -0,0 +1,70 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// DefaultMaximumConcurrency is the default maximum number of services that can be evaluated at the same time
+	DefaultMaximumConcurrency = 1
+
+	// UnlimitedConcurrency is the value of ConcurrencyConfig.Max used to indicate that there is no limit to the number
+	// of services that can be evaluated at the same time
+	UnlimitedConcurrency = -1
+
+	// DefaultMaximumStartJitter is the default maximum random delay before the first evaluation of each service
+	DefaultMaximumStartJitter = 10 * time.Second
+)
+
+var (
+	// ErrInvalidConcurrencyGroupLimit is the error returned when the concurrency limit of a group is lower than 1
+	ErrInvalidConcurrencyGroupLimit = errors.New("concurrency.groups values must be greater than 0")
+
+	// ErrInvalidMaximumStartJitter is the error returned when the maximum start jitter is negative
+	ErrInvalidMaximumStartJitter = errors.New("concurrency.maximum-start-jitter cannot be negative")
+)
+
+// ConcurrencyConfig is the configuration of how many services can be evaluated at the same time
+type ConcurrencyConfig struct {
+	// Max is the maximum number of services that can be evaluated at the same time.
+	//
+	// Defaults to DefaultMaximumConcurrency, or to UnlimitedConcurrency if Config.DisableMonitoringLock is true.
+	// Any negative value means that there is no limit.
+	Max int `yaml:"max"`
+
+	// Groups is a map of group names (key) to the maximum number of services of that group that can be evaluated at
+	// the same time (value). This limit applies on top of Max.
+	Groups map[string]int `yaml:"groups"`
+
+	// MaximumStartJitter is the maximum random delay to wait for before the first evaluation of each service.
+	//
+	// This spreads the evaluations of the services on start rather than evaluating all of them at once.
+	// The actual delay of a service is never longer than its interval.
+	MaximumStartJitter time.Duration `yaml:"maximum-start-jitter"`
+}
+
+// validateAndSetDefaults checks and sets the default values for fields that are not set
+func (concurrency *ConcurrencyConfig) validateAndSetDefaults(disableMonitoringLock bool) error {
+	if concurrency.Max == 0 {
+		if disableMonitoringLock {
+			concurrency.Max = UnlimitedConcurrency
+		} else {
+			concurrency.Max = DefaultMaximumConcurrency
+		}
+	} else if concurrency.Max < 0 {
+		concurrency.Max = UnlimitedConcurrency
+	}
+	for _, limit := range concurrency.Groups {
+		if limit < 1 {
+			return ErrInvalidConcurrencyGroupLimit
+		}
+	}
+	if concurrency.MaximumStartJitter < 0 {
+		return ErrInvalidMaximumStartJitter
+	}
+	if concurrency.MaximumStartJitter == 0 {
+		concurrency.MaximumStartJitter = DefaultMaximumStartJitter
+	}
+	return nil
+}
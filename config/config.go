@@ -60,8 +60,14 @@ type Config struct {
 	// DisableMonitoringLock Whether to disable the monitoring lock
 	// The monitoring lock is what prevents multiple services from being processed at the same time.
 	// Disabling this may lead to inaccurate response times
+	//
+	// Deprecated: Use Concurrency instead. Setting this to true is equivalent to setting Concurrency.Max to
+	// UnlimitedConcurrency, unless Concurrency.Max is explicitly set.
 	DisableMonitoringLock bool `yaml:"disable-monitoring-lock"`
 
+	// Concurrency is the configuration of how many services can be evaluated at the same time
+	Concurrency *ConcurrencyConfig `yaml:"concurrency"`
+
 	// Security Configuration for securing access to Gatus
 	Security *security.Config `yaml:"security"`
 
@@ -168,6 +174,9 @@ func parseAndValidateConfigBytes(yamlBytes []byte) (config *Config, err error) {
 		if err := validateWebConfig(config); err != nil {
 			return nil, err
 		}
+		if err := validateConcurrencyConfig(config); err != nil {
+			return nil, err
+		}
 		if err := validateStorageConfig(config); err != nil {
 			return nil, err
 		}
@@ -204,6 +213,13 @@ func validateWebConfig(config *Config) error {
 	return nil
 }
 
+func validateConcurrencyConfig(config *Config) error {
+	if config.Concurrency == nil {
+		config.Concurrency = &ConcurrencyConfig{}
+	}
+	return config.Concurrency.validateAndSetDefaults(config.DisableMonitoringLock)
+}
+
 // deprecated
 // I don't like the current implementation.
 func validateKubernetesConfig(config *Config) error {
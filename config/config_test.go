@@ -1232,3 +1232,129 @@ func TestGetAlertingProviderByAlertType(t *testing.T) {
 		t.Error("expected Twilio configuration")
 	}
 }
+
+func TestParseAndValidateConfigBytesWithConcurrency(t *testing.T) {
+	config, err := parseAndValidateConfigBytes([]byte(`
+concurrency:
+  max: 5
+  groups:
+    core: 2
+  maximum-start-jitter: 30s
+services:
+  - name: twinnation
+    url: https://twinnation.org/health
+    exclusive: true
+    conditions:
+      - "[STATUS] == 200"
+`))
+	if err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	if config.Concurrency.Max != 5 {
+		t.Errorf("expected concurrency.max to be %d, got %d", 5, config.Concurrency.Max)
+	}
+	if config.Concurrency.Groups["core"] != 2 {
+		t.Errorf("expected concurrency.groups.core to be %d, got %d", 2, config.Concurrency.Groups["core"])
+	}
+	if config.Concurrency.MaximumStartJitter != 30*time.Second {
+		t.Errorf("expected concurrency.maximum-start-jitter to be %s, got %s", 30*time.Second, config.Concurrency.MaximumStartJitter)
+	}
+	if !config.Services[0].Exclusive {
+		t.Error("expected service to be exclusive")
+	}
+}
+
+func TestParseAndValidateConfigBytesWithConcurrencyDefaults(t *testing.T) {
+	scenarios := []struct {
+		Name        string
+		Config      string
+		ExpectedMax int
+	}{
+		{
+			Name:        "default",
+			Config:      "",
+			ExpectedMax: DefaultMaximumConcurrency,
+		},
+		{
+			Name:        "disable-monitoring-lock",
+			Config:      "disable-monitoring-lock: true",
+			ExpectedMax: UnlimitedConcurrency,
+		},
+		{
+			Name:        "disable-monitoring-lock-with-explicit-max",
+			Config:      "disable-monitoring-lock: true\nconcurrency:\n  max: 3",
+			ExpectedMax: 3,
+		},
+		{
+			Name:        "negative-max",
+			Config:      "concurrency:\n  max: -10",
+			ExpectedMax: UnlimitedConcurrency,
+		},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			config, err := parseAndValidateConfigBytes([]byte(scenario.Config + `
+services:
+  - name: twinnation
+    url: https://twinnation.org/health
+    conditions:
+      - "[STATUS] == 200"
+`))
+			if err != nil {
+				t.Fatal("expected no error, got", err.Error())
+			}
+			if config.Concurrency.Max != scenario.ExpectedMax {
+				t.Errorf("expected concurrency.max to be %d, got %d", scenario.ExpectedMax, config.Concurrency.Max)
+			}
+			if config.Concurrency.MaximumStartJitter != DefaultMaximumStartJitter {
+				t.Errorf("expected concurrency.maximum-start-jitter to default to %s, got %s", DefaultMaximumStartJitter, config.Concurrency.MaximumStartJitter)
+			}
+		})
+	}
+}
+
+func TestParseAndValidateConfigBytesWithInvalidConcurrency(t *testing.T) {
+	_, err := parseAndValidateConfigBytes([]byte(`
+concurrency:
+  groups:
+    core: 0
+services:
+  - name: twinnation
+    url: https://twinnation.org/health
+    conditions:
+      - "[STATUS] == 200"
+`))
+	if err != ErrInvalidConcurrencyGroupLimit {
+		t.Errorf("expected error %v, got %v", ErrInvalidConcurrencyGroupLimit, err)
+	}
+}
+
+func TestParseAndValidateConfigBytesWithClientConfig(t *testing.T) {
+	config, err := parseAndValidateConfigBytes([]byte(`
+services:
+  - name: internal-api
+    url: https://internal.example.org/health
+    client:
+      server-name: api.internal
+    conditions:
+      - "[STATUS] == 200"
+`))
+	if err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	if config.Services[0].ClientConfig == nil || config.Services[0].ClientConfig.ServerName != "api.internal" {
+		t.Error("expected the client configuration of the service to have been parsed")
+	}
+	_, err = parseAndValidateConfigBytes([]byte(`
+services:
+  - name: internal-api
+    url: https://internal.example.org/health
+    client:
+      cert-file: /path/to/client.crt
+    conditions:
+      - "[STATUS] == 200"
+`))
+	if err == nil {
+		t.Error("expected an error, because client.cert-file was specified without client.key-file")
+	}
+}
@@ -1,31 +1,71 @@
 package controller
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/TwinProduction/gatus/config"
 	"github.com/TwinProduction/gatus/core"
+	"github.com/TwinProduction/gatus/core/ui"
 	"github.com/TwinProduction/gatus/storage"
+	"github.com/TwinProduction/gatus/util"
 	"github.com/gorilla/mux"
 )
 
-// badgeHandler handles the automatic generation of badge based on the group name and service name passed.
+const (
+	badgeColorHexAwesome  = "#40cc11"
+	badgeColorHexGreat    = "#94cc11"
+	badgeColorHexGood     = "#ccd311"
+	badgeColorHexPassable = "#ccb311"
+	badgeColorHexBad      = "#cc8111"
+	badgeColorHexVeryBad  = "#c7130a"
+	badgeColorHexUnknown  = "#9f9f9f"
+)
+
+const (
+	// HealthStatusUp is the health status of a service whose last result was successful and that has no triggered alert
+	HealthStatusUp = "up"
+
+	// HealthStatusDown is the health status of a service whose last result was unsuccessful and that either has a
+	// triggered alert or has no alert configured
+	HealthStatusDown = "down"
+
+	// HealthStatusDegraded is the health status of a service whose last result does not match the state of its alerts,
+	// for instance, a service that has started failing but hasn't reached the failure threshold of its alerts yet, or
+	// a service that is successful again but hasn't reached the success threshold required to resolve its alerts yet
+	HealthStatusDegraded = "degraded"
+
+	// HealthStatusUnknown is the health status of a service that has no results yet
+	HealthStatusUnknown = "unknown"
+)
+
+// shieldsBadge is the payload returned for badges in the format expected by shields.io's endpoint badge.
+// See https://shields.io/endpoint
+type shieldsBadge struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+// uptimeBadgeHandler handles the automatic generation of badge based on the group name and service name passed.
 //
-// Valid values for {duration}: 7d, 24h, 1h
-// Pattern for {identifier}: <KEY>.svg
-func badgeHandler(writer http.ResponseWriter, request *http.Request) {
+// Valid values for {duration}: 90d, 30d, 7d, 24h, 1h
+// Pattern for {identifier}: <KEY>.svg, or <KEY>.json for shields.io's endpoint badge format
+func uptimeBadgeHandler(writer http.ResponseWriter, request *http.Request) {
 	variables := mux.Vars(request)
 	duration := variables["duration"]
-	if duration != "7d" && duration != "24h" && duration != "1h" {
+	if !isValidBadgeDuration(duration) {
 		writer.WriteHeader(http.StatusBadRequest)
-		_, _ = writer.Write([]byte("Durations supported: 7d, 24h, 1h"))
+		_, _ = writer.Write([]byte("Durations supported: 90d, 30d, 7d, 24h, 1h"))
 		return
 	}
 	identifier := variables["identifier"]
-	key := strings.TrimSuffix(identifier, ".svg")
-	serviceStatus := storage.Get().GetServiceStatusByKey(key)
+	key := strings.TrimSuffix(strings.TrimSuffix(identifier, ".svg"), ".json")
+	serviceStatus := storage.Get().GetServiceStatusByKeyWithResultPagination(key, 1, 1)
 	if serviceStatus == nil {
 		writer.WriteHeader(http.StatusNotFound)
 		_, _ = writer.Write([]byte("Requested service not found"))
@@ -36,40 +76,261 @@ func badgeHandler(writer http.ResponseWriter, request *http.Request) {
 		_, _ = writer.Write([]byte("Failed to compute uptime"))
 		return
 	}
+	if strings.HasSuffix(identifier, ".json") {
+		value, color := getUptimeBadgeValueAndColor(duration, serviceStatus.Uptime)
+		writeShieldsBadge(writer, "uptime "+duration, value, color)
+		return
+	}
+	writeBadge(writer, generateUptimeBadgeSVG(duration, serviceStatus.Uptime))
+}
+
+// healthBadgeHandler handles the automatic generation of a badge displaying whether the service identified by the
+// group name and service name passed is currently up, down or degraded.
+//
+// The health is derived from the last result of the service and from whether any of its alerts is triggered.
+//
+// Pattern for {identifier}: <KEY>.svg, or <KEY>.json for shields.io's endpoint badge format
+func healthBadgeHandler(cfg *config.Config) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		identifier := mux.Vars(request)["identifier"]
+		key := strings.TrimSuffix(strings.TrimSuffix(identifier, ".svg"), ".json")
+		serviceStatus := storage.Get().GetServiceStatusByKeyWithResultPagination(key, 1, 1)
+		if serviceStatus == nil {
+			writer.WriteHeader(http.StatusNotFound)
+			_, _ = writer.Write([]byte("Requested service not found"))
+			return
+		}
+		var lastResult *core.Result
+		if len(serviceStatus.Results) > 0 {
+			lastResult = serviceStatus.Results[len(serviceStatus.Results)-1]
+		}
+		healthStatus := getHealthStatus(lastResult, getService(cfg, key))
+		color := getBadgeColorFromHealthStatus(healthStatus)
+		if strings.HasSuffix(identifier, ".json") {
+			writeShieldsBadge(writer, "health", healthStatus, color)
+			return
+		}
+		writeBadge(writer, generateBadgeSVG("health", 50, healthStatus, len(healthStatus)*9+10, color))
+	}
+}
+
+// getHealthStatus returns the health status of a service based on its last result and the state of its alerts
+func getHealthStatus(lastResult *core.Result, service *core.Service) string {
+	if lastResult == nil {
+		return HealthStatusUnknown
+	}
+	hasAlerts, hasTriggeredAlert := false, false
+	if service != nil {
+		for _, serviceAlert := range service.Alerts {
+			if !serviceAlert.IsEnabled() {
+				continue
+			}
+			hasAlerts = true
+			if serviceAlert.Triggered {
+				hasTriggeredAlert = true
+			}
+		}
+	}
+	if lastResult.Success {
+		if hasTriggeredAlert {
+			return HealthStatusDegraded
+		}
+		return HealthStatusUp
+	}
+	if hasAlerts && !hasTriggeredAlert {
+		return HealthStatusDegraded
+	}
+	return HealthStatusDown
+}
+
+func getBadgeColorFromHealthStatus(healthStatus string) string {
+	switch healthStatus {
+	case HealthStatusUp:
+		return badgeColorHexAwesome
+	case HealthStatusDegraded:
+		return badgeColorHexPassable
+	case HealthStatusDown:
+		return badgeColorHexVeryBad
+	default:
+		return badgeColorHexUnknown
+	}
+}
+
+// responseTimeBadgeHandler handles the automatic generation of a badge displaying the average response time of the
+// service identified by the group name and service name passed.
+//
+// The color of the badge is determined by the response time badge thresholds of the service's UI configuration.
+//
+// Valid values for {duration}: 90d, 30d, 7d, 24h, 1h
+// Pattern for {identifier}: <KEY>.svg, or <KEY>.json for shields.io's endpoint badge format
+func responseTimeBadgeHandler(cfg *config.Config) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		variables := mux.Vars(request)
+		duration := variables["duration"]
+		if !isValidBadgeDuration(duration) {
+			writer.WriteHeader(http.StatusBadRequest)
+			_, _ = writer.Write([]byte("Durations supported: 90d, 30d, 7d, 24h, 1h"))
+			return
+		}
+		identifier := variables["identifier"]
+		key := strings.TrimSuffix(strings.TrimSuffix(identifier, ".svg"), ".json")
+		serviceStatus := storage.Get().GetServiceStatusByKeyWithResultPagination(key, 1, 1)
+		if serviceStatus == nil {
+			writer.WriteHeader(http.StatusNotFound)
+			_, _ = writer.Write([]byte("Requested service not found"))
+			return
+		}
+		if serviceStatus.Uptime == nil {
+			writer.WriteHeader(http.StatusInternalServerError)
+			_, _ = writer.Write([]byte("Failed to compute response time"))
+			return
+		}
+		if strings.HasSuffix(identifier, ".json") {
+			value, color := getResponseTimeBadgeValueAndColor(duration, serviceStatus.Uptime, getResponseTimeBadgeThresholds(cfg, key))
+			writeShieldsBadge(writer, "response time "+duration, value, color)
+			return
+		}
+		writeBadge(writer, generateResponseTimeBadgeSVG(duration, serviceStatus.Uptime, getResponseTimeBadgeThresholds(cfg, key)))
+	}
+}
+
+// getResponseTimeBadgeThresholds returns the response time badge thresholds of the service with the given key, or
+// ui.DefaultResponseTimeBadgeThresholds if the service has none configured
+func getResponseTimeBadgeThresholds(cfg *config.Config, key string) []int {
+	service := getService(cfg, key)
+	if service != nil && service.UIConfig != nil && service.UIConfig.Badge != nil && service.UIConfig.Badge.ResponseTime != nil && len(service.UIConfig.Badge.ResponseTime.Thresholds) == len(ui.DefaultResponseTimeBadgeThresholds) {
+		return service.UIConfig.Badge.ResponseTime.Thresholds
+	}
+	return ui.DefaultResponseTimeBadgeThresholds
+}
+
+// getService returns the configured service with the given key, or nil if there is none
+func getService(cfg *config.Config, key string) *core.Service {
+	if cfg == nil {
+		return nil
+	}
+	for _, service := range cfg.Services {
+		if util.ConvertGroupAndServiceToKey(service.Group, service.Name) == key {
+			return service
+		}
+	}
+	return nil
+}
+
+func isValidBadgeDuration(duration string) bool {
+	return duration == "90d" || duration == "30d" || duration == "7d" || duration == "24h" || duration == "1h"
+}
+
+func writeBadge(writer http.ResponseWriter, svg []byte) {
 	formattedDate := time.Now().Format(http.TimeFormat)
 	writer.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
 	writer.Header().Set("Date", formattedDate)
 	writer.Header().Set("Expires", formattedDate)
 	writer.Header().Set("Content-Type", "image/svg+xml")
-	_, _ = writer.Write(generateSVG(duration, serviceStatus.Uptime))
+	_, _ = writer.Write(svg)
 }
 
-func generateSVG(duration string, uptime *core.Uptime) []byte {
-	var labelWidth, valueWidth, valueWidthAdjustment int
-	var color string
+func writeShieldsBadge(writer http.ResponseWriter, label, message, color string) {
+	output, err := json.Marshal(shieldsBadge{
+		SchemaVersion: 1,
+		Label:         label,
+		Message:       message,
+		Color:         strings.TrimPrefix(color, "#"),
+	})
+	if err != nil {
+		writer.WriteHeader(http.StatusInternalServerError)
+		_, _ = writer.Write([]byte("unable to marshal object to JSON"))
+		return
+	}
+	writer.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	writer.Header().Set("Content-Type", "application/json")
+	_, _ = writer.Write(output)
+}
+
+func generateUptimeBadgeSVG(duration string, uptime *core.Uptime) []byte {
+	var labelWidth, valueWidthAdjustment int
+	switch duration {
+	case "90d", "30d", "24h":
+		labelWidth = 70
+	case "7d", "1h":
+		labelWidth = 65
+	default:
+	}
+	sanitizedValue, color := getUptimeBadgeValueAndColor(duration, uptime)
+	if strings.Contains(sanitizedValue, ".") {
+		valueWidthAdjustment = -10
+	}
+	return generateBadgeSVG("uptime "+duration, labelWidth, sanitizedValue, (len(sanitizedValue)*11)+valueWidthAdjustment, color)
+}
+
+// getUptimeBadgeValueAndColor returns the formatted uptime of the given duration as well as the color of its badge
+func getUptimeBadgeValueAndColor(duration string, uptime *core.Uptime) (string, string) {
 	var value float64
 	switch duration {
+	case "90d":
+		value = uptime.LastNinetyDays
+	case "30d":
+		value = uptime.LastThirtyDays
 	case "7d":
-		labelWidth = 65
 		value = uptime.LastSevenDays
 	case "24h":
-		labelWidth = 70
 		value = uptime.LastTwentyFourHours
 	case "1h":
-		labelWidth = 65
 		value = uptime.LastHour
 	default:
 	}
+	color := badgeColorHexVeryBad
 	if value >= 0.8 {
-		color = "#40cc11"
-	} else {
-		color = "#c7130a"
+		color = badgeColorHexAwesome
 	}
-	sanitizedValue := strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.2f", value*100), "0"), ".") + "%"
-	if strings.Contains(sanitizedValue, ".") {
-		valueWidthAdjustment = -10
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.2f", value*100), "0"), ".") + "%", color
+}
+
+func generateResponseTimeBadgeSVG(duration string, uptime *core.Uptime, thresholds []int) []byte {
+	var labelWidth int
+	switch duration {
+	case "90d", "30d", "24h":
+		labelWidth = 110
+	case "7d", "1h":
+		labelWidth = 105
+	default:
+	}
+	sanitizedValue, color := getResponseTimeBadgeValueAndColor(duration, uptime, thresholds)
+	return generateBadgeSVG("response time "+duration, labelWidth, sanitizedValue, len(sanitizedValue)*11, color)
+}
+
+// getResponseTimeBadgeValueAndColor returns the formatted average response time of the given duration as well as the
+// color of its badge
+func getResponseTimeBadgeValueAndColor(duration string, uptime *core.Uptime, thresholds []int) (string, string) {
+	var value int64
+	switch duration {
+	case "90d":
+		value = uptime.AverageResponseTime.LastNinetyDays
+	case "30d":
+		value = uptime.AverageResponseTime.LastThirtyDays
+	case "7d":
+		value = uptime.AverageResponseTime.LastSevenDays
+	case "24h":
+		value = uptime.AverageResponseTime.LastTwentyFourHours
+	case "1h":
+		value = uptime.AverageResponseTime.LastHour
+	default:
+	}
+	return fmt.Sprintf("%dms", value), getBadgeColorFromResponseTime(value, thresholds)
+}
+
+// getBadgeColorFromResponseTime returns the color of the response time badge based on the thresholds passed
+func getBadgeColorFromResponseTime(responseTime int64, thresholds []int) string {
+	colors := []string{badgeColorHexAwesome, badgeColorHexGreat, badgeColorHexGood, badgeColorHexPassable, badgeColorHexBad}
+	for i, threshold := range thresholds {
+		if i < len(colors) && responseTime <= int64(threshold) {
+			return colors[i]
+		}
 	}
-	valueWidth = (len(sanitizedValue) * 11) + valueWidthAdjustment
+	return badgeColorHexVeryBad
+}
+
+func generateBadgeSVG(label string, labelWidth int, value string, valueWidth int, color string) []byte {
 	width := labelWidth + valueWidth
 	labelX := labelWidth / 2
 	valueX := labelWidth + (valueWidth / 2)
@@ -88,10 +349,10 @@ func generateSVG(duration string, uptime *core.Uptime) []byte {
   </g>
   <g fill="#fff" text-anchor="middle" font-family="DejaVu Sans,Verdana,Geneva,sans-serif" font-size="11">
     <text x="%d" y="15" fill="#010101" fill-opacity=".3">
-      uptime %s
+      %s
     </text>
     <text x="%d" y="14">
-      uptime %s
+      %s
     </text>
     <text x="%d" y="15" fill="#010101" fill-opacity=".3">
       %s
@@ -100,6 +361,6 @@ func generateSVG(duration string, uptime *core.Uptime) []byte {
       %s
     </text>
   </g>
-</svg>`, width, width, labelWidth, color, labelWidth, valueWidth, labelWidth, width, labelX, duration, labelX, duration, valueX, sanitizedValue, valueX, sanitizedValue))
+</svg>`, width, width, labelWidth, color, labelWidth, valueWidth, labelWidth, width, labelX, label, labelX, label, valueX, value, valueX, value))
 	return svg
 }
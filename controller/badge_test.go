@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TwinProduction/gatus/alerting/alert"
+	"github.com/TwinProduction/gatus/config"
+	"github.com/TwinProduction/gatus/core"
+	"github.com/TwinProduction/gatus/core/ui"
+)
+
+func TestGetBadgeColorFromResponseTime(t *testing.T) {
+	scenarios := []struct {
+		ResponseTime  int64
+		ExpectedColor string
+	}{
+		{ResponseTime: 10, ExpectedColor: badgeColorHexAwesome},
+		{ResponseTime: 50, ExpectedColor: badgeColorHexAwesome},
+		{ResponseTime: 150, ExpectedColor: badgeColorHexGreat},
+		{ResponseTime: 250, ExpectedColor: badgeColorHexGood},
+		{ResponseTime: 400, ExpectedColor: badgeColorHexPassable},
+		{ResponseTime: 600, ExpectedColor: badgeColorHexBad},
+		{ResponseTime: 1500, ExpectedColor: badgeColorHexVeryBad},
+	}
+	for _, scenario := range scenarios {
+		if color := getBadgeColorFromResponseTime(scenario.ResponseTime, ui.DefaultResponseTimeBadgeThresholds); color != scenario.ExpectedColor {
+			t.Errorf("expected color %s for a response time of %dms, got %s", scenario.ExpectedColor, scenario.ResponseTime, color)
+		}
+	}
+}
+
+func TestGetResponseTimeBadgeThresholds(t *testing.T) {
+	customThresholds := []int{100, 200, 400, 800, 1600}
+	cfg := &config.Config{
+		Services: []*core.Service{
+			{
+				Name:     "frontend",
+				Group:    "core",
+				UIConfig: &ui.Config{Badge: &ui.Badge{ResponseTime: &ui.ResponseTime{Thresholds: customThresholds}}},
+			},
+			{
+				Name:  "backend",
+				Group: "core",
+			},
+		},
+	}
+	if thresholds := getResponseTimeBadgeThresholds(cfg, "core_frontend"); thresholds[0] != customThresholds[0] {
+		t.Errorf("expected custom thresholds %v, got %v", customThresholds, thresholds)
+	}
+	if thresholds := getResponseTimeBadgeThresholds(cfg, "core_backend"); thresholds[0] != ui.DefaultResponseTimeBadgeThresholds[0] {
+		t.Errorf("expected default thresholds %v, got %v", ui.DefaultResponseTimeBadgeThresholds, thresholds)
+	}
+	if thresholds := getResponseTimeBadgeThresholds(nil, "core_frontend"); thresholds[0] != ui.DefaultResponseTimeBadgeThresholds[0] {
+		t.Errorf("expected default thresholds %v, got %v", ui.DefaultResponseTimeBadgeThresholds, thresholds)
+	}
+}
+
+func TestGenerateResponseTimeBadgeSVG(t *testing.T) {
+	uptime := core.NewUptime()
+	uptime.ProcessResult(&core.Result{Timestamp: time.Now(), Duration: 120 * time.Millisecond, Success: true})
+	svg := string(generateResponseTimeBadgeSVG("1h", uptime, ui.DefaultResponseTimeBadgeThresholds))
+	if !strings.Contains(svg, "120ms") {
+		t.Error("expected badge to contain the average response time")
+	}
+	if !strings.Contains(svg, badgeColorHexGreat) {
+		t.Errorf("expected badge to have color %s", badgeColorHexGreat)
+	}
+}
+
+func TestGetHealthStatus(t *testing.T) {
+	enabled := true
+	scenarios := []struct {
+		Name                 string
+		LastResult           *core.Result
+		Service              *core.Service
+		ExpectedHealthStatus string
+	}{
+		{
+			Name:                 "no-result",
+			LastResult:           nil,
+			Service:              &core.Service{},
+			ExpectedHealthStatus: HealthStatusUnknown,
+		},
+		{
+			Name:                 "success-without-alerts",
+			LastResult:           &core.Result{Success: true},
+			Service:              &core.Service{},
+			ExpectedHealthStatus: HealthStatusUp,
+		},
+		{
+			Name:                 "failure-without-alerts",
+			LastResult:           &core.Result{Success: false},
+			Service:              &core.Service{},
+			ExpectedHealthStatus: HealthStatusDown,
+		},
+		{
+			Name:                 "failure-without-service",
+			LastResult:           &core.Result{Success: false},
+			Service:              nil,
+			ExpectedHealthStatus: HealthStatusDown,
+		},
+		{
+			Name:                 "failure-with-alert-not-triggered-yet",
+			LastResult:           &core.Result{Success: false},
+			Service:              &core.Service{Alerts: []*alert.Alert{{Enabled: &enabled}}},
+			ExpectedHealthStatus: HealthStatusDegraded,
+		},
+		{
+			Name:                 "failure-with-triggered-alert",
+			LastResult:           &core.Result{Success: false},
+			Service:              &core.Service{Alerts: []*alert.Alert{{Enabled: &enabled, Triggered: true}}},
+			ExpectedHealthStatus: HealthStatusDown,
+		},
+		{
+			Name:                 "success-with-alert-not-resolved-yet",
+			LastResult:           &core.Result{Success: true},
+			Service:              &core.Service{Alerts: []*alert.Alert{{Enabled: &enabled, Triggered: true}}},
+			ExpectedHealthStatus: HealthStatusDegraded,
+		},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			if healthStatus := getHealthStatus(scenario.LastResult, scenario.Service); healthStatus != scenario.ExpectedHealthStatus {
+				t.Errorf("expected health status %s, got %s", scenario.ExpectedHealthStatus, healthStatus)
+			}
+		})
+	}
+}
+
+func TestWriteShieldsBadge(t *testing.T) {
+	responseRecorder := httptest.NewRecorder()
+	writeShieldsBadge(responseRecorder, "health", HealthStatusUp, badgeColorHexAwesome)
+	if contentType := responseRecorder.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("expected Content-Type to be application/json, got %s", contentType)
+	}
+	var badge map[string]interface{}
+	if err := json.Unmarshal(responseRecorder.Body.Bytes(), &badge); err != nil {
+		t.Fatal("expected response to be valid JSON, got error:", err.Error())
+	}
+	if badge["schemaVersion"] != float64(1) || badge["label"] != "health" || badge["message"] != HealthStatusUp || badge["color"] != "40cc11" {
+		t.Errorf("unexpected badge: %v", badge)
+	}
+}
@@ -44,19 +44,19 @@ func init() {
 }
 
 // Handle creates the router and starts the server
-func Handle(securityConfig *security.Config, webConfig *config.WebConfig, enableMetrics bool) {
-	var router http.Handler = CreateRouter(securityConfig, enableMetrics)
+func Handle(cfg *config.Config) {
+	var router http.Handler = CreateRouter(cfg)
 	if os.Getenv("ENVIRONMENT") == "dev" {
 		router = developmentCorsHandler(router)
 	}
 	server = &http.Server{
-		Addr:         fmt.Sprintf("%s:%d", webConfig.Address, webConfig.Port),
+		Addr:         fmt.Sprintf("%s:%d", cfg.Web.Address, cfg.Web.Port),
 		Handler:      router,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  15 * time.Second,
 	}
-	log.Println("[controller][Handle] Listening on " + webConfig.SocketAddress())
+	log.Println("[controller][Handle] Listening on " + cfg.Web.SocketAddress())
 	if os.Getenv("ROUTER_TEST") == "true" {
 		return
 	}
@@ -72,16 +72,20 @@ func Shutdown() {
 }
 
 // CreateRouter creates the router for the http server
-func CreateRouter(securityConfig *security.Config, enabledMetrics bool) *mux.Router {
+func CreateRouter(cfg *config.Config) *mux.Router {
+	securityConfig := cfg.Security
 	router := mux.NewRouter()
-	if enabledMetrics {
+	if cfg.Metrics {
 		router.Handle("/metrics", promhttp.Handler()).Methods("GET")
 	}
 	router.Handle("/health", health.Handler().WithJSON(true)).Methods("GET")
 	router.HandleFunc("/favicon.ico", favIconHandler).Methods("GET")
 	router.HandleFunc("/api/v1/statuses", secureIfNecessary(securityConfig, serviceStatusesHandler)).Methods("GET") // No GzipHandler for this one, because we cache the content
 	router.HandleFunc("/api/v1/statuses/{key}", secureIfNecessary(securityConfig, GzipHandlerFunc(serviceStatusHandler))).Methods("GET")
-	router.HandleFunc("/api/v1/badges/uptime/{duration}/{identifier}", badgeHandler).Methods("GET")
+	router.HandleFunc("/api/v1/statuses/{key}/results", secureIfNecessary(securityConfig, GzipHandlerFunc(serviceResultsHandler))).Methods("GET")
+	router.HandleFunc("/api/v1/badges/uptime/{duration}/{identifier}", uptimeBadgeHandler).Methods("GET")
+	router.HandleFunc("/api/v1/badges/response-time/{duration}/{identifier}", responseTimeBadgeHandler(cfg)).Methods("GET")
+	router.HandleFunc("/api/v1/badges/health/{identifier}", healthBadgeHandler(cfg)).Methods("GET")
 	// SPA
 	router.HandleFunc("/services/{service}", spaHandler).Methods("GET")
 	// Everything else falls back on static content
@@ -142,7 +146,7 @@ func serviceStatusesHandler(writer http.ResponseWriter, r *http.Request) {
 func serviceStatusHandler(writer http.ResponseWriter, r *http.Request) {
 	page, pageSize := extractPageAndPageSizeFromRequest(r)
 	vars := mux.Vars(r)
-	serviceStatus := storage.Get().GetServiceStatusByKey(vars["key"])
+	serviceStatus := storage.Get().GetServiceStatusByKeyWithResultPagination(vars["key"], page, pageSize)
 	if serviceStatus == nil {
 		log.Printf("[controller][serviceStatusHandler] Service with key=%s not found", vars["key"])
 		writer.WriteHeader(http.StatusNotFound)
@@ -150,13 +154,16 @@ func serviceStatusHandler(writer http.ResponseWriter, r *http.Request) {
 		return
 	}
 	data := map[string]interface{}{
-		"serviceStatus": serviceStatus.WithResultPagination(page, pageSize),
+		"serviceStatus": serviceStatus,
 		// The following fields, while present on core.ServiceStatus, are annotated to remain hidden so that we can
 		// expose only the necessary data on /api/v1/statuses.
 		// Since the /api/v1/statuses/{key} endpoint does need this data, however, we explicitly expose it here
 		"events": serviceStatus.Events,
 		"uptime": serviceStatus.Uptime,
 	}
+	if serviceStatus.Uptime != nil {
+		data["responseTime"] = serviceStatus.Uptime.AverageResponseTime
+	}
 	output, err := json.Marshal(data)
 	if err != nil {
 		log.Printf("[controller][serviceStatusHandler] Unable to marshal object to JSON: %s", err.Error())
@@ -168,3 +175,34 @@ func serviceStatusHandler(writer http.ResponseWriter, r *http.Request) {
 	writer.WriteHeader(http.StatusOK)
 	_, _ = writer.Write(output)
 }
+
+// serviceResultsHandler retrieves the results of a single service within a time range
+//
+// The time range is defined by the from and to query parameters, which must be in RFC3339 format.
+// If to is not specified, it defaults to the current time. If from is not specified, it defaults to 24 hours before to.
+func serviceResultsHandler(writer http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	from, to, err := extractTimeRangeFromRequest(r)
+	if err != nil {
+		writer.WriteHeader(http.StatusBadRequest)
+		_, _ = writer.Write([]byte(err.Error()))
+		return
+	}
+	results := storage.Get().GetServiceStatusResultsBetween(vars["key"], from, to)
+	if results == nil {
+		log.Printf("[controller][serviceResultsHandler] Service with key=%s not found", vars["key"])
+		writer.WriteHeader(http.StatusNotFound)
+		_, _ = writer.Write([]byte("not found"))
+		return
+	}
+	output, err := json.Marshal(results)
+	if err != nil {
+		log.Printf("[controller][serviceResultsHandler] Unable to marshal object to JSON: %s", err.Error())
+		writer.WriteHeader(http.StatusInternalServerError)
+		_, _ = writer.Write([]byte("unable to marshal object to JSON"))
+		return
+	}
+	writer.Header().Add("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusOK)
+	_, _ = writer.Write(output)
+}
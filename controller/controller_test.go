@@ -105,7 +105,7 @@ func TestCreateRouter(t *testing.T) {
 	}
 	watchdog.UpdateServiceStatuses(cfg.Services[0], &core.Result{Success: true, Duration: time.Millisecond, Timestamp: time.Now()})
 	watchdog.UpdateServiceStatuses(cfg.Services[1], &core.Result{Success: false, Duration: time.Second, Timestamp: time.Now()})
-	router := CreateRouter(cfg.Security, cfg.Metrics)
+	router := CreateRouter(cfg)
 	type Scenario struct {
 		Name         string
 		Path         string
@@ -138,6 +138,16 @@ func TestCreateRouter(t *testing.T) {
 			Path:         "/api/v1/badges/uptime/7d/core_frontend.svg",
 			ExpectedCode: http.StatusOK,
 		},
+		{
+			Name:         "badges-30d",
+			Path:         "/api/v1/badges/uptime/30d/core_frontend.svg",
+			ExpectedCode: http.StatusOK,
+		},
+		{
+			Name:         "badges-90d",
+			Path:         "/api/v1/badges/uptime/90d/core_backend.svg",
+			ExpectedCode: http.StatusOK,
+		},
 		{
 			Name:         "badges-with-invalid-duration",
 			Path:         "/api/v1/badges/uptime/3d/core_backend.svg",
@@ -148,6 +158,56 @@ func TestCreateRouter(t *testing.T) {
 			Path:         "/api/v1/badges/uptime/7d/invalid_key.svg",
 			ExpectedCode: http.StatusNotFound,
 		},
+		{
+			Name:         "badges-response-time-1h",
+			Path:         "/api/v1/badges/response-time/1h/core_frontend.svg",
+			ExpectedCode: http.StatusOK,
+		},
+		{
+			Name:         "badges-response-time-24h",
+			Path:         "/api/v1/badges/response-time/24h/core_backend.svg",
+			ExpectedCode: http.StatusOK,
+		},
+		{
+			Name:         "badges-response-time-90d",
+			Path:         "/api/v1/badges/response-time/90d/core_frontend.svg",
+			ExpectedCode: http.StatusOK,
+		},
+		{
+			Name:         "badges-response-time-with-invalid-duration",
+			Path:         "/api/v1/badges/response-time/3d/core_backend.svg",
+			ExpectedCode: http.StatusBadRequest,
+		},
+		{
+			Name:         "badges-response-time-for-invalid-key",
+			Path:         "/api/v1/badges/response-time/7d/invalid_key.svg",
+			ExpectedCode: http.StatusNotFound,
+		},
+		{
+			Name:         "badges-uptime-shields",
+			Path:         "/api/v1/badges/uptime/7d/core_frontend.json",
+			ExpectedCode: http.StatusOK,
+		},
+		{
+			Name:         "badges-response-time-shields",
+			Path:         "/api/v1/badges/response-time/24h/core_frontend.json",
+			ExpectedCode: http.StatusOK,
+		},
+		{
+			Name:         "badges-health",
+			Path:         "/api/v1/badges/health/core_frontend.svg",
+			ExpectedCode: http.StatusOK,
+		},
+		{
+			Name:         "badges-health-shields",
+			Path:         "/api/v1/badges/health/core_backend.json",
+			ExpectedCode: http.StatusOK,
+		},
+		{
+			Name:         "badges-health-for-invalid-key",
+			Path:         "/api/v1/badges/health/invalid_key.svg",
+			ExpectedCode: http.StatusNotFound,
+		},
 		{
 			Name:         "service-statuses",
 			Path:         "/api/v1/statuses",
@@ -180,6 +240,26 @@ func TestCreateRouter(t *testing.T) {
 			Path:         "/api/v1/statuses/invalid_key",
 			ExpectedCode: http.StatusNotFound,
 		},
+		{
+			Name:         "service-results",
+			Path:         "/api/v1/statuses/core_frontend/results",
+			ExpectedCode: http.StatusOK,
+		},
+		{
+			Name:         "service-results-with-time-range",
+			Path:         "/api/v1/statuses/core_frontend/results?from=2021-01-01T00:00:00Z&to=2021-01-02T00:00:00Z",
+			ExpectedCode: http.StatusOK,
+		},
+		{
+			Name:         "service-results-with-invalid-time-range",
+			Path:         "/api/v1/statuses/core_frontend/results?from=yesterday",
+			ExpectedCode: http.StatusBadRequest,
+		},
+		{
+			Name:         "service-results-for-invalid-key",
+			Path:         "/api/v1/statuses/invalid_key/results",
+			ExpectedCode: http.StatusNotFound,
+		},
 		{
 			Name:         "favicon",
 			Path:         "/favicon.ico",
@@ -238,7 +318,7 @@ func TestHandle(t *testing.T) {
 	_ = os.Setenv("ROUTER_TEST", "true")
 	_ = os.Setenv("ENVIRONMENT", "dev")
 	defer os.Clearenv()
-	Handle(cfg.Security, cfg.Web, cfg.Metrics)
+	Handle(cfg)
 	defer Shutdown()
 	request, _ := http.NewRequest("GET", "/health", nil)
 	responseRecorder := httptest.NewRecorder()
@@ -271,7 +351,7 @@ func TestServiceStatusesHandler(t *testing.T) {
 	// Can't be bothered dealing with timezone issues on the worker that runs the automated tests
 	firstResult.Timestamp = time.Time{}
 	secondResult.Timestamp = time.Time{}
-	router := CreateRouter(nil, false)
+	router := CreateRouter(&config.Config{})
 
 	type Scenario struct {
 		Name         string
@@ -1,8 +1,10 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 const (
@@ -14,6 +16,14 @@ const (
 
 	// MaximumPageSize is the maximum page size allowed
 	MaximumPageSize = 100
+
+	// DefaultTimeRange is the duration of the time range to use if the beginning of the time range isn't specified
+	DefaultTimeRange = 24 * time.Hour
+)
+
+var (
+	// ErrInvalidTimeRange is the error returned when the time range requested is invalid
+	ErrInvalidTimeRange = errors.New("invalid time range: from and to must be in RFC3339 format, and from must be before to")
 )
 
 func extractPageAndPageSizeFromRequest(r *http.Request) (page int, pageSize int) {
@@ -44,3 +54,20 @@ func extractPageAndPageSizeFromRequest(r *http.Request) (page int, pageSize int)
 	}
 	return
 }
+
+func extractTimeRangeFromRequest(r *http.Request) (from time.Time, to time.Time, err error) {
+	if toParameter := r.URL.Query().Get("to"); len(toParameter) == 0 {
+		to = time.Now()
+	} else if to, err = time.Parse(time.RFC3339, toParameter); err != nil {
+		return from, to, ErrInvalidTimeRange
+	}
+	if fromParameter := r.URL.Query().Get("from"); len(fromParameter) == 0 {
+		from = to.Add(-DefaultTimeRange)
+	} else if from, err = time.Parse(time.RFC3339, fromParameter); err != nil {
+		return from, to, ErrInvalidTimeRange
+	}
+	if from.After(to) {
+		return from, to, ErrInvalidTimeRange
+	}
+	return from, to, nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 )
 
 func TestExtractPageAndPageSizeFromRequest(t *testing.T) {
@@ -65,3 +66,28 @@ func TestExtractPageAndPageSizeFromRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractTimeRangeFromRequest(t *testing.T) {
+	request, _ := http.NewRequest("GET", "/api/v1/statuses/core_frontend/results?from=2021-01-01T00:00:00Z&to=2021-01-02T00:00:00Z", nil)
+	from, to, err := extractTimeRangeFromRequest(request)
+	if err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	if !from.Equal(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)) || !to.Equal(time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected from=2021-01-01T00:00:00Z and to=2021-01-02T00:00:00Z, got from=%s and to=%s", from, to)
+	}
+	request, _ = http.NewRequest("GET", "/api/v1/statuses/core_frontend/results", nil)
+	from, to, err = extractTimeRangeFromRequest(request)
+	if err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	if to.Sub(from) != DefaultTimeRange {
+		t.Errorf("expected the time range to default to %s, got %s", DefaultTimeRange, to.Sub(from))
+	}
+	for _, query := range []string{"from=yesterday", "to=tomorrow", "from=2021-01-02T00:00:00Z&to=2021-01-01T00:00:00Z"} {
+		request, _ = http.NewRequest("GET", "/api/v1/statuses/core_frontend/results?"+query, nil)
+		if _, _, err = extractTimeRangeFromRequest(request); err != ErrInvalidTimeRange {
+			t.Errorf("expected ErrInvalidTimeRange for query %s, got %v", query, err)
+		}
+	}
+}
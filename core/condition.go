@@ -6,8 +6,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/TwinProduction/gatus/cssselector"
 	"github.com/TwinProduction/gatus/jsonpath"
 	"github.com/TwinProduction/gatus/pattern"
+	"github.com/TwinProduction/gatus/xpath"
 )
 
 const (
@@ -26,6 +28,22 @@ const (
 	// Values that could replace the placeholder: NOERROR, FORMERR, SERVFAIL, NXDOMAIN, NOTIMP, REFUSED
 	DNSRCodePlaceholder = "[DNS_RCODE]"
 
+	// DNSTTLPlaceholder is a placeholder for the lowest TTL of the answers of a DNS query, in seconds.
+	//
+	// Values that could replace the placeholder: 300, 3600, ...
+	DNSTTLPlaceholder = "[DNS_TTL]"
+
+	// DNSAnswersPlaceholder is a placeholder for the answers of a DNS query, as a JSON array of strings. Like the
+	// BodyPlaceholder, it can be followed by a JSONPath expression.
+	//
+	// Values that could replace the placeholder: [], ["192.0.2.1","192.0.2.2"], ...
+	DNSAnswersPlaceholder = "[DNS_ANSWERS]"
+
+	// DNSAnswerCountPlaceholder is a placeholder for the number of answers of a DNS query.
+	//
+	// Values that could replace the placeholder: 0, 1, 2, ...
+	DNSAnswerCountPlaceholder = "[DNS_ANSWER_COUNT]"
+
 	// ResponseTimePlaceholder is a placeholder for the request response time, in milliseconds.
 	//
 	// Values that could replace the placeholder: 1, 500, 1000, ...
@@ -46,6 +64,87 @@ const (
 	// Values that could replace the placeholder: 4461677039 (~52 days)
 	CertificateExpirationPlaceholder = "[CERTIFICATE_EXPIRATION]"
 
+	// CertificateIssuerPlaceholder is a placeholder for the distinguished name of the issuer of the certificate.
+	//
+	// Values that could replace the placeholder: CN=R3,O=Let's Encrypt,C=US
+	CertificateIssuerPlaceholder = "[CERTIFICATE_ISSUER]"
+
+	// CertificateSubjectPlaceholder is a placeholder for the distinguished name of the subject of the certificate.
+	//
+	// Values that could replace the placeholder: CN=example.org
+	CertificateSubjectPlaceholder = "[CERTIFICATE_SUBJECT]"
+
+	// CertificateSANsPlaceholder is a placeholder for the comma-separated subject alternative names of the certificate.
+	//
+	// Values that could replace the placeholder: example.org,www.example.org
+	CertificateSANsPlaceholder = "[CERTIFICATE_SANS]"
+
+	// CertificateChainValidPlaceholder is a placeholder for whether the certificate chain could be verified, even if
+	// the service is configured to skip the verification.
+	//
+	// Values that could replace the placeholder: true, false
+	CertificateChainValidPlaceholder = "[CERTIFICATE_CHAIN_VALID]"
+
+	// TLSVersionPlaceholder is a placeholder for the version of TLS negotiated with the server.
+	//
+	// Values that could replace the placeholder: TLS 1.2, TLS 1.3
+	TLSVersionPlaceholder = "[TLS_VERSION]"
+
+	// SSHBannerPlaceholder is a placeholder for the identification string sent by the SSH server.
+	//
+	// Values that could replace the placeholder: SSH-2.0-OpenSSH_8.4
+	SSHBannerPlaceholder = "[SSH_BANNER]"
+
+	// SSHHostKeyFingerprintPlaceholder is a placeholder for the SHA256 fingerprint of the host key of the SSH server.
+	//
+	// Values that could replace the placeholder: SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8
+	SSHHostKeyFingerprintPlaceholder = "[SSH_HOST_KEY_FINGERPRINT]"
+
+	// SSHExitCodePlaceholder is a placeholder for the exit code of the command executed on the SSH server.
+	//
+	// Values that could replace the placeholder: 0, 1, 127, ...
+	SSHExitCodePlaceholder = "[SSH_EXIT_CODE]"
+
+	// GRPCStatusPlaceholder is a placeholder for the serving status returned by the gRPC health checking service.
+	//
+	// Values that could replace the placeholder: SERVING, NOT_SERVING, UNKNOWN, SERVICE_UNKNOWN
+	GRPCStatusPlaceholder = "[GRPC_STATUS]"
+
+	// PacketLossPlaceholder is a placeholder for the percentage of packets sent to an ICMP service that were lost.
+	//
+	// Values that could replace the placeholder: 0, 33.333333333333336, 100, ...
+	PacketLossPlaceholder = "[PACKET_LOSS]"
+
+	// AverageRTTPlaceholder is a placeholder for the average round-trip time of the packets sent to an ICMP service,
+	// in milliseconds.
+	//
+	// Values that could replace the placeholder: 1, 25, 150, ...
+	AverageRTTPlaceholder = "[AVG_RTT]"
+
+	// JitterPlaceholder is a placeholder for the average of the differences between the round-trip times of
+	// consecutive packets sent to an ICMP service, in milliseconds.
+	//
+	// Values that could replace the placeholder: 0, 2, 15, ...
+	JitterPlaceholder = "[JITTER]"
+
+	// FinalURLPlaceholder is a placeholder for the URL of the last request made to an HTTP service, after having
+	// followed the redirects.
+	//
+	// Values that could replace the placeholder: https://example.org/, https://example.org/login, ...
+	FinalURLPlaceholder = "[FINAL_URL]"
+
+	// RedirectCountPlaceholder is a placeholder for the number of redirects followed by an HTTP service.
+	//
+	// Values that could replace the placeholder: 0, 1, 2, ...
+	RedirectCountPlaceholder = "[REDIRECT_COUNT]"
+
+	// HeaderPlaceholder is a placeholder for the headers of the HTTP response. It must be followed by the name of the
+	// header, which is case-insensitive. If the header has multiple values, they are joined by a comma and a space
+	// (", ").
+	//
+	// Usage: [HEADER].Content-Type == pat(application/json*), has([HEADER].ETag) == true
+	HeaderPlaceholder = "[HEADER]"
+
 	// LengthFunctionPrefix is the prefix for the length function
 	//
 	// Usage: len([BODY].articles) == 10, len([BODY].name) > 5
@@ -66,6 +165,18 @@ const (
 	// Usage: [IP] == any(1.1.1.1, 1.0.0.1)
 	AnyFunctionPrefix = "any("
 
+	// XPathFunctionPrefix is the prefix for the xpath function, which evaluates an XPath expression against an XML or
+	// HTML body. It can be wrapped by the length function and the has function.
+	//
+	// Usage: xpath([BODY], //status/text()) == UP, len(xpath([BODY], //error)) == 0
+	XPathFunctionPrefix = "xpath("
+
+	// CSSFunctionPrefix is the prefix for the css function, which evaluates a CSS selector against an HTML body and
+	// returns the text of the first element matched. It can be wrapped by the length function and the has function.
+	//
+	// Usage: css([BODY], #status) == Operational, has(css([BODY], .incident)) == false
+	CSSFunctionPrefix = "css("
+
 	// FunctionSuffix is the suffix for all functions
 	FunctionSuffix = ")"
 
@@ -89,38 +200,38 @@ func (c Condition) evaluate(result *Result) bool {
 	condition := string(c)
 	success := false
 	conditionToDisplay := condition
-	if strings.Contains(condition, "==") {
-		parameters, resolvedParameters := sanitizeAndResolve(strings.Split(condition, "=="), result)
+	if elements := splitOnOperator(condition, "=="); elements != nil {
+		parameters, resolvedParameters := sanitizeAndResolve(elements, result)
 		success = isEqual(resolvedParameters[0], resolvedParameters[1])
 		if !success {
 			conditionToDisplay = prettify(parameters, resolvedParameters, "==")
 		}
-	} else if strings.Contains(condition, "!=") {
-		parameters, resolvedParameters := sanitizeAndResolve(strings.Split(condition, "!="), result)
+	} else if elements := splitOnOperator(condition, "!="); elements != nil {
+		parameters, resolvedParameters := sanitizeAndResolve(elements, result)
 		success = !isEqual(resolvedParameters[0], resolvedParameters[1])
 		if !success {
 			conditionToDisplay = prettify(parameters, resolvedParameters, "!=")
 		}
-	} else if strings.Contains(condition, "<=") {
-		parameters, resolvedParameters := sanitizeAndResolveNumerical(strings.Split(condition, "<="), result)
+	} else if elements := splitOnOperator(condition, "<="); elements != nil {
+		parameters, resolvedParameters := sanitizeAndResolveNumerical(elements, result)
 		success = resolvedParameters[0] <= resolvedParameters[1]
 		if !success {
 			conditionToDisplay = prettifyNumericalParameters(parameters, resolvedParameters, "<=")
 		}
-	} else if strings.Contains(condition, ">=") {
-		parameters, resolvedParameters := sanitizeAndResolveNumerical(strings.Split(condition, ">="), result)
+	} else if elements := splitOnOperator(condition, ">="); elements != nil {
+		parameters, resolvedParameters := sanitizeAndResolveNumerical(elements, result)
 		success = resolvedParameters[0] >= resolvedParameters[1]
 		if !success {
 			conditionToDisplay = prettifyNumericalParameters(parameters, resolvedParameters, ">=")
 		}
-	} else if strings.Contains(condition, ">") {
-		parameters, resolvedParameters := sanitizeAndResolveNumerical(strings.Split(condition, ">"), result)
+	} else if elements := splitOnOperator(condition, ">"); elements != nil {
+		parameters, resolvedParameters := sanitizeAndResolveNumerical(elements, result)
 		success = resolvedParameters[0] > resolvedParameters[1]
 		if !success {
 			conditionToDisplay = prettifyNumericalParameters(parameters, resolvedParameters, ">")
 		}
-	} else if strings.Contains(condition, "<") {
-		parameters, resolvedParameters := sanitizeAndResolveNumerical(strings.Split(condition, "<"), result)
+	} else if elements := splitOnOperator(condition, "<"); elements != nil {
+		parameters, resolvedParameters := sanitizeAndResolveNumerical(elements, result)
 		success = resolvedParameters[0] < resolvedParameters[1]
 		if !success {
 			conditionToDisplay = prettifyNumericalParameters(parameters, resolvedParameters, "<")
@@ -136,6 +247,34 @@ func (c Condition) evaluate(result *Result) bool {
 	return success
 }
 
+// splitOnOperator splits the condition into the two elements on each side of the first occurrence of the operator
+// that isn't part of a JSONPath expression or of the arguments of a function, such as the == of
+// [BODY].services[?(@.status == 'up')].name or the > of css([BODY], ul > li).
+// Returns nil if the condition doesn't contain such an occurrence of the operator.
+func splitOnOperator(condition, operator string) []string {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(condition); i++ {
+		switch c := condition[i]; {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case depth > 0 && (c == '\'' || c == '"'):
+			quote = c
+		case c == '[' || c == '(':
+			depth++
+		case (c == ']' || c == ')') && depth > 0:
+			depth--
+		case depth == 0 && strings.HasPrefix(condition[i:], operator):
+			return []string{condition[:i], condition[i+len(operator):]}
+		}
+	}
+	return nil
+}
+
 // hasBodyPlaceholder checks whether the condition has a BodyPlaceholder
 // Used for determining whether the response body should be read or not
 func (c Condition) hasBodyPlaceholder() bool {
@@ -215,13 +354,56 @@ func sanitizeAndResolve(elements []string, result *Result) ([]string, []string)
 			element = body
 		case DNSRCodePlaceholder:
 			element = result.DNSRCode
+		case DNSTTLPlaceholder:
+			element = strconv.FormatUint(uint64(result.DNSTTL), 10)
+		case DNSAnswersPlaceholder:
+			element = string(result.dnsAnswersAsJSON())
+		case DNSAnswerCountPlaceholder:
+			element = strconv.Itoa(result.DNSAnswerCount)
 		case ConnectedPlaceholder:
 			element = strconv.FormatBool(result.Connected)
 		case CertificateExpirationPlaceholder:
 			element = strconv.FormatInt(result.CertificateExpiration.Milliseconds(), 10)
+		case CertificateIssuerPlaceholder:
+			element = result.CertificateIssuer
+		case CertificateSubjectPlaceholder:
+			element = result.CertificateSubject
+		case CertificateSANsPlaceholder:
+			element = strings.Join(result.CertificateSANs, ",")
+		case CertificateChainValidPlaceholder:
+			element = strconv.FormatBool(result.CertificateChainValid)
+		case TLSVersionPlaceholder:
+			element = result.TLSVersion
+		case SSHBannerPlaceholder:
+			element = result.SSHServerVersion
+		case SSHHostKeyFingerprintPlaceholder:
+			element = result.SSHHostKeyFingerprint
+		case SSHExitCodePlaceholder:
+			element = strconv.Itoa(result.SSHExitCode)
+		case GRPCStatusPlaceholder:
+			element = result.GRPCStatus
+		case PacketLossPlaceholder:
+			element = strconv.FormatFloat(result.PacketLoss, 'f', -1, 64)
+		case AverageRTTPlaceholder:
+			element = strconv.Itoa(int(result.AverageRTT.Milliseconds()))
+		case JitterPlaceholder:
+			element = strconv.Itoa(int(result.Jitter.Milliseconds()))
+		case FinalURLPlaceholder:
+			element = result.FinalURL
+		case RedirectCountPlaceholder:
+			element = strconv.Itoa(result.RedirectCount)
 		default:
-			// if contains the BodyPlaceholder, then evaluate json path
-			if strings.Contains(element, BodyPlaceholder) {
+			if hasSelectorFunction(element) {
+				element = resolveSelectorFunction(element, result)
+			} else if strings.Contains(strings.ToUpper(element), HeaderPlaceholder+".") {
+				// if contains the HeaderPlaceholder, then look up the header
+				element = resolveHeader(element, result)
+			} else if strings.Contains(element, BodyPlaceholder) || strings.Contains(element, DNSAnswersPlaceholder) {
+				// if contains the BodyPlaceholder or the DNSAnswersPlaceholder, then evaluate json path
+				placeholder, data := BodyPlaceholder, result.body
+				if !strings.Contains(element, BodyPlaceholder) {
+					placeholder, data = DNSAnswersPlaceholder, result.dnsAnswersAsJSON()
+				}
 				checkingForLength := false
 				checkingForExistence := false
 				if strings.HasPrefix(element, LengthFunctionPrefix) && strings.HasSuffix(element, FunctionSuffix) {
@@ -232,13 +414,9 @@ func sanitizeAndResolve(elements []string, result *Result) ([]string, []string)
 					checkingForExistence = true
 					element = strings.TrimSuffix(strings.TrimPrefix(element, HasFunctionPrefix), FunctionSuffix)
 				}
-				resolvedElement, resolvedElementLength, err := jsonpath.Eval(strings.TrimPrefix(strings.TrimPrefix(element, BodyPlaceholder), "."), result.body)
+				resolvedElement, resolvedElementLength, err := jsonpath.Eval(strings.TrimPrefix(element, placeholder), data)
 				if checkingForExistence {
-					if err != nil {
-						element = "false"
-					} else {
-						element = "true"
-					}
+					element = strconv.FormatBool(jsonpath.Exists(strings.TrimPrefix(element, placeholder), data))
 				} else {
 					if err != nil {
 						if err.Error() != "unexpected end of JSON input" {
@@ -264,6 +442,84 @@ func sanitizeAndResolve(elements []string, result *Result) ([]string, []string)
 	return parameters, resolvedParameters
 }
 
+// resolveHeader resolves an element containing the HeaderPlaceholder, optionally wrapped by the length function or
+// the has function, into the value of the header, its length, or whether the header is present
+func resolveHeader(element string, result *Result) string {
+	checkingForLength := false
+	checkingForExistence := false
+	if strings.HasPrefix(element, LengthFunctionPrefix) && strings.HasSuffix(element, FunctionSuffix) {
+		checkingForLength = true
+		element = strings.TrimSuffix(strings.TrimPrefix(element, LengthFunctionPrefix), FunctionSuffix)
+	} else if strings.HasPrefix(element, HasFunctionPrefix) && strings.HasSuffix(element, FunctionSuffix) {
+		checkingForExistence = true
+		element = strings.TrimSuffix(strings.TrimPrefix(element, HasFunctionPrefix), FunctionSuffix)
+	}
+	// The header placeholder is case-insensitive, so strings.TrimPrefix cannot be used
+	name := element[strings.Index(strings.ToUpper(element), HeaderPlaceholder+".")+len(HeaderPlaceholder)+1:]
+	// Header names are case-insensitive, which http.Header takes care of by canonicalizing them
+	values := result.httpHeaders.Values(strings.TrimSpace(name))
+	if checkingForExistence {
+		return strconv.FormatBool(len(values) > 0)
+	}
+	value := strings.Join(values, ", ")
+	if checkingForLength {
+		return strconv.Itoa(len(value))
+	}
+	return value
+}
+
+// hasSelectorFunction checks whether the element uses the xpath function or the css function, optionally wrapped by
+// the length function or the has function
+func hasSelectorFunction(element string) bool {
+	for _, prefix := range []string{"", LengthFunctionPrefix, HasFunctionPrefix} {
+		if strings.HasPrefix(element, prefix+XPathFunctionPrefix) || strings.HasPrefix(element, prefix+CSSFunctionPrefix) {
+			return strings.HasSuffix(element, FunctionSuffix)
+		}
+	}
+	return false
+}
+
+// resolveSelectorFunction resolves an element using the xpath function or the css function, optionally wrapped by
+// the length function or the has function, into the value selected from the body, the number of nodes selected, or
+// whether any node was selected
+func resolveSelectorFunction(element string, result *Result) string {
+	function := element
+	checkingForLength := false
+	checkingForExistence := false
+	if strings.HasPrefix(function, LengthFunctionPrefix) {
+		checkingForLength = true
+		function = strings.TrimSuffix(strings.TrimPrefix(function, LengthFunctionPrefix), FunctionSuffix)
+	} else if strings.HasPrefix(function, HasFunctionPrefix) {
+		checkingForExistence = true
+		function = strings.TrimSuffix(strings.TrimPrefix(function, HasFunctionPrefix), FunctionSuffix)
+	}
+	eval := xpath.Eval
+	arguments := strings.TrimPrefix(function, XPathFunctionPrefix)
+	if strings.HasPrefix(function, CSSFunctionPrefix) {
+		eval = cssselector.Eval
+		arguments = strings.TrimPrefix(function, CSSFunctionPrefix)
+	}
+	// The first argument must be the body, and the second argument is the expression, which may contain commas
+	arguments = strings.TrimSpace(strings.TrimSuffix(arguments, FunctionSuffix))
+	if !strings.HasPrefix(arguments, BodyPlaceholder) || !strings.HasPrefix(strings.TrimSpace(strings.TrimPrefix(arguments, BodyPlaceholder)), ",") {
+		result.AddError(fmt.Sprintf("the first argument of %s must be %s", function, BodyPlaceholder))
+		return element + " " + InvalidConditionElementSuffix
+	}
+	expression := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(strings.TrimPrefix(arguments, BodyPlaceholder)), ","))
+	value, length, err := eval(expression, result.body)
+	if checkingForExistence {
+		return strconv.FormatBool(err == nil && length > 0)
+	}
+	if err != nil {
+		result.AddError(err.Error())
+		return element + " " + InvalidConditionElementSuffix
+	}
+	if checkingForLength {
+		return strconv.Itoa(length)
+	}
+	return value
+}
+
 func sanitizeAndResolveNumerical(list []string, result *Result) (parameters []string, resolvedNumericalParameters []int64) {
 	parameters, resolvedParameters := sanitizeAndResolve(list, result)
 	for _, element := range resolvedParameters {
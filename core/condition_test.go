@@ -1,6 +1,7 @@
 package core
 
 import (
+	"net/http"
 	"strconv"
 	"testing"
 	"time"
@@ -435,6 +436,286 @@ func TestCondition_evaluate(t *testing.T) {
 			ExpectedSuccess: false,
 			ExpectedOutput:  "has([BODY].errors) (true) == false",
 		},
+		{
+			Name:            "has-with-null-value",
+			Condition:       Condition("has([BODY].errors) == false"),
+			Result:          &Result{body: []byte(`{"errors": null}`)},
+			ExpectedSuccess: true,
+			ExpectedOutput:  "has([BODY].errors) == false",
+		},
+		{
+			Name:            "body-jsonpath-null-value",
+			Condition:       Condition("[BODY].errors == null"),
+			Result:          &Result{body: []byte(`{"errors": null}`)},
+			ExpectedSuccess: true,
+			ExpectedOutput:  "[BODY].errors == null",
+		},
+		{
+			Name:            "has-with-filter",
+			Condition:       Condition("has([BODY].services[?(@.status == 'down')]) == false"),
+			Result:          &Result{body: []byte(`{"services": [{"name": "api", "status": "up"}]}`)},
+			ExpectedSuccess: true,
+			ExpectedOutput:  "has([BODY].services[?(@.status == 'down')]) == false",
+		},
+		{
+			Name:            "body-jsonpath-filter",
+			Condition:       Condition("[BODY].services[?(@.status=='up')].name == [\"api\"]"),
+			Result:          &Result{body: []byte(`{"services": [{"name": "api", "status": "up"}, {"name": "db", "status": "down"}]}`)},
+			ExpectedSuccess: true,
+			ExpectedOutput:  "[BODY].services[?(@.status=='up')].name == [\"api\"]",
+		},
+		{
+			Name:            "body-jsonpath-filter-with-comparison-operators",
+			Condition:       Condition("len([BODY].services[?(@.latency >= 100 || @.status != 'up')]) < 1"),
+			Result:          &Result{body: []byte(`{"services": [{"name": "api", "status": "up", "latency": 50}, {"name": "db", "status": "down"}]}`)},
+			ExpectedSuccess: false,
+			ExpectedOutput:  "len([BODY].services[?(@.latency >= 100 || @.status != 'up')]) (1) < 1",
+		},
+		{
+			Name:            "body-jsonpath-recursive-descent",
+			Condition:       Condition("len([BODY]..status) == 3"),
+			Result:          &Result{body: []byte(`{"status": "up", "services": [{"status": "up"}, {"dependencies": [{"status": "up"}]}]}`)},
+			ExpectedSuccess: true,
+			ExpectedOutput:  "len([BODY]..status) == 3",
+		},
+		{
+			Name:            "body-jsonpath-negative-index-and-quoted-key",
+			Condition:       Condition("[BODY]['checks.history'][-1].status == up"),
+			Result:          &Result{body: []byte(`{"checks.history": [{"status": "down"}, {"status": "up"}]}`)},
+			ExpectedSuccess: true,
+			ExpectedOutput:  "[BODY]['checks.history'][-1].status == up",
+		},
+		{
+			Name:            "xpath",
+			Condition:       Condition("xpath([BODY], //status/text()) == UP"),
+			Result:          &Result{body: []byte("<health><status>UP</status></health>")},
+			ExpectedSuccess: true,
+			ExpectedOutput:  "xpath([BODY], //status/text()) == UP",
+		},
+		{
+			Name:            "xpath-failure",
+			Condition:       Condition("xpath([BODY], //component[@name='db']/status) == UP"),
+			Result:          &Result{body: []byte(`<health><component name="db"><status>DOWN</status></component></health>`)},
+			ExpectedSuccess: false,
+			ExpectedOutput:  "xpath([BODY], //component[@name='db']/status) (DOWN) == UP",
+		},
+		{
+			Name:            "xpath-with-operators-in-expression",
+			Condition:       Condition("xpath([BODY], count(//component[status != 'UP']) > 0) == false"),
+			Result:          &Result{body: []byte(`<health><component><status>UP</status></component></health>`)},
+			ExpectedSuccess: true,
+			ExpectedOutput:  "xpath([BODY], count(//component[status != 'UP']) > 0) == false",
+		},
+		{
+			Name:            "xpath-with-len",
+			Condition:       Condition("len(xpath([BODY], //component)) >= 2"),
+			Result:          &Result{body: []byte(`<health><component/><component/></health>`)},
+			ExpectedSuccess: true,
+			ExpectedOutput:  "len(xpath([BODY], //component)) >= 2",
+		},
+		{
+			Name:            "xpath-with-has",
+			Condition:       Condition("has(xpath([BODY], //soap:Fault)) == false"),
+			Result:          &Result{body: []byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault/></soap:Body></soap:Envelope>`)},
+			ExpectedSuccess: false,
+			ExpectedOutput:  "has(xpath([BODY], //soap:Fault)) (true) == false",
+		},
+		{
+			Name:            "xpath-with-invalid-expression",
+			Condition:       Condition("xpath([BODY], //status[@]) == UP"),
+			Result:          &Result{body: []byte("<status>UP</status>")},
+			ExpectedSuccess: false,
+			ExpectedOutput:  "xpath([BODY], //status[@]) (INVALID) == UP",
+		},
+		{
+			Name:            "xpath-without-body",
+			Condition:       Condition("xpath([STATUS], //status) == UP"),
+			Result:          &Result{body: []byte("<status>UP</status>")},
+			ExpectedSuccess: false,
+			ExpectedOutput:  "xpath([STATUS], //status) (INVALID) == UP",
+		},
+		{
+			Name:            "css",
+			Condition:       Condition("css([BODY], #status > .message) == All systems operational"),
+			Result:          &Result{body: []byte(`<html><body><div id="status"><p class="message"> All systems  operational </p></div></body></html>`)},
+			ExpectedSuccess: true,
+			ExpectedOutput:  "css([BODY], #status > .message) == All systems operational",
+		},
+		{
+			Name:            "css-with-pattern",
+			Condition:       Condition("css([BODY], title) == pat(*Status*)"),
+			Result:          &Result{body: []byte(`<html><head><title>Example - Status</title></head></html>`)},
+			ExpectedSuccess: true,
+			ExpectedOutput:  "css([BODY], title) == pat(*Status*)",
+		},
+		{
+			Name:            "css-with-len",
+			Condition:       Condition("len(css([BODY], ul.incidents > li)) == 0"),
+			Result:          &Result{body: []byte(`<ul class="incidents"><li>Degraded performance</li></ul>`)},
+			ExpectedSuccess: false,
+			ExpectedOutput:  "len(css([BODY], ul.incidents > li)) (1) == 0",
+		},
+		{
+			Name:            "css-with-has",
+			Condition:       Condition("has(css([BODY], [data-status=down])) == false"),
+			Result:          &Result{body: []byte(`<div data-status="up">API</div>`)},
+			ExpectedSuccess: true,
+			ExpectedOutput:  "has(css([BODY], [data-status=down])) == false",
+		},
+		{
+			Name:            "certificate-issuer",
+			Condition:       Condition("[CERTIFICATE_ISSUER] == pat(*O=Let's Encrypt*)"),
+			Result:          &Result{CertificateIssuer: "CN=R3,O=Let's Encrypt,C=US"},
+			ExpectedSuccess: true,
+			ExpectedOutput:  "[CERTIFICATE_ISSUER] == pat(*O=Let's Encrypt*)",
+		},
+		{
+			Name:            "certificate-subject",
+			Condition:       Condition("[CERTIFICATE_SUBJECT] == CN=example.org"),
+			Result:          &Result{CertificateSubject: "CN=example.org"},
+			ExpectedSuccess: true,
+			ExpectedOutput:  "[CERTIFICATE_SUBJECT] == CN=example.org",
+		},
+		{
+			Name:            "certificate-sans",
+			Condition:       Condition("[CERTIFICATE_SANS] == pat(*www.example.org*)"),
+			Result:          &Result{CertificateSANs: []string{"example.org", "www.example.org"}},
+			ExpectedSuccess: true,
+			ExpectedOutput:  "[CERTIFICATE_SANS] == pat(*www.example.org*)",
+		},
+		{
+			Name:            "certificate-chain-valid-failure",
+			Condition:       Condition("[CERTIFICATE_CHAIN_VALID] == true"),
+			Result:          &Result{CertificateChainValid: false},
+			ExpectedSuccess: false,
+			ExpectedOutput:  "[CERTIFICATE_CHAIN_VALID] (false) == true",
+		},
+		{
+			Name:            "tls-version",
+			Condition:       Condition("[TLS_VERSION] == any(TLS 1.2, TLS 1.3)"),
+			Result:          &Result{TLSVersion: "TLS 1.3"},
+			ExpectedSuccess: true,
+			ExpectedOutput:  "[TLS_VERSION] == any(TLS 1.2, TLS 1.3)",
+		},
+		{
+			Name:            "ssh-banner",
+			Condition:       Condition("[SSH_BANNER] == pat(SSH-2.0-OpenSSH*)"),
+			Result:          &Result{SSHServerVersion: "SSH-2.0-OpenSSH_8.4"},
+			ExpectedSuccess: true,
+			ExpectedOutput:  "[SSH_BANNER] == pat(SSH-2.0-OpenSSH*)",
+		},
+		{
+			Name:            "ssh-host-key-fingerprint-changed",
+			Condition:       Condition("[SSH_HOST_KEY_FINGERPRINT] == SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8"),
+			Result:          &Result{SSHHostKeyFingerprint: "SHA256:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU"},
+			ExpectedSuccess: false,
+			ExpectedOutput:  "[SSH_HOST_KEY_FINGERPRINT] (SHA256:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU) == SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8",
+		},
+		{
+			Name:            "ssh-exit-code",
+			Condition:       Condition("[SSH_EXIT_CODE] == 0"),
+			Result:          &Result{SSHExitCode: 1},
+			ExpectedSuccess: false,
+			ExpectedOutput:  "[SSH_EXIT_CODE] (1) == 0",
+		},
+		{
+			Name:            "grpc-status",
+			Condition:       Condition("[GRPC_STATUS] == SERVING"),
+			Result:          &Result{GRPCStatus: "NOT_SERVING"},
+			ExpectedSuccess: false,
+			ExpectedOutput:  "[GRPC_STATUS] (NOT_SERVING) == SERVING",
+		},
+		{
+			Name:            "packet-loss",
+			Condition:       Condition("[PACKET_LOSS] < 50"),
+			Result:          &Result{PacketLoss: 100.0 / 3},
+			ExpectedSuccess: true,
+			ExpectedOutput:  "[PACKET_LOSS] < 50",
+		},
+		{
+			Name:            "packet-loss-failure",
+			Condition:       Condition("[PACKET_LOSS] == 0"),
+			Result:          &Result{PacketLoss: 100},
+			ExpectedSuccess: false,
+			ExpectedOutput:  "[PACKET_LOSS] (100) == 0",
+		},
+		{
+			Name:            "average-rtt",
+			Condition:       Condition("[AVG_RTT] < 50"),
+			Result:          &Result{AverageRTT: 75 * time.Millisecond},
+			ExpectedSuccess: false,
+			ExpectedOutput:  "[AVG_RTT] (75) < 50",
+		},
+		{
+			Name:            "jitter",
+			Condition:       Condition("[JITTER] <= 10"),
+			Result:          &Result{Jitter: 4 * time.Millisecond},
+			ExpectedSuccess: true,
+			ExpectedOutput:  "[JITTER] <= 10",
+		},
+		{
+			Name:            "final-url",
+			Condition:       Condition("[FINAL_URL] == pat(https://*)"),
+			Result:          &Result{FinalURL: "http://example.org/"},
+			ExpectedSuccess: false,
+			ExpectedOutput:  "[FINAL_URL] (http://example.org/) == pat(https://*)",
+		},
+		{
+			Name:            "redirect-count",
+			Condition:       Condition("[REDIRECT_COUNT] <= 1"),
+			Result:          &Result{RedirectCount: 1},
+			ExpectedSuccess: true,
+			ExpectedOutput:  "[REDIRECT_COUNT] <= 1",
+		},
+		{
+			Name:            "header",
+			Condition:       Condition("[HEADER].Content-Type == pat(application/json*)"),
+			Result:          &Result{httpHeaders: http.Header{"Content-Type": []string{"application/json; charset=utf-8"}}},
+			ExpectedSuccess: true,
+			ExpectedOutput:  "[HEADER].Content-Type == pat(application/json*)",
+		},
+		{
+			Name:            "header-case-insensitive",
+			Condition:       Condition("[header].cache-control == any(no-cache, no-store)"),
+			Result:          &Result{httpHeaders: http.Header{"Cache-Control": []string{"public, max-age=3600"}}},
+			ExpectedSuccess: false,
+			ExpectedOutput:  "[header].cache-control (public, max-age=3600) == any(no-cache, no-store)",
+		},
+		{
+			Name:            "header-with-multiple-values",
+			Condition:       Condition("[HEADER].Vary == Accept-Encoding, Origin"),
+			Result:          &Result{httpHeaders: http.Header{"Vary": []string{"Accept-Encoding", "Origin"}}},
+			ExpectedSuccess: true,
+			ExpectedOutput:  "[HEADER].Vary == Accept-Encoding, Origin",
+		},
+		{
+			Name:            "header-missing",
+			Condition:       Condition("[HEADER].X-Cache == HIT"),
+			Result:          &Result{},
+			ExpectedSuccess: false,
+			ExpectedOutput:  "[HEADER].X-Cache () == HIT",
+		},
+		{
+			Name:            "has-header",
+			Condition:       Condition("has([HEADER].ETag) == true"),
+			Result:          &Result{httpHeaders: http.Header{"Etag": []string{`"33a64df5"`}}},
+			ExpectedSuccess: true,
+			ExpectedOutput:  "has([HEADER].ETag) == true",
+		},
+		{
+			Name:            "has-header-missing",
+			Condition:       Condition("has([HEADER].ETag) == true"),
+			Result:          &Result{httpHeaders: http.Header{}},
+			ExpectedSuccess: false,
+			ExpectedOutput:  "has([HEADER].ETag) (false) == true",
+		},
+		{
+			Name:            "len-header",
+			Condition:       Condition("len([HEADER].ETag) == 10"),
+			Result:          &Result{httpHeaders: http.Header{"Etag": []string{`"33a64df5"`}}},
+			ExpectedSuccess: true,
+			ExpectedOutput:  "len([HEADER].ETag) == 10",
+		},
 		{
 			Name:            "no-placeholders",
 			Condition:       Condition("1 == 2"),
@@ -1,10 +1,18 @@
 package core
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/TwinProduction/gatus/client"
 	"github.com/miekg/dns"
 )
 
@@ -14,10 +22,35 @@ var (
 
 	// ErrDNSWithInvalidQueryType is the error with which gatus will panic if a dns is configured with invalid query type
 	ErrDNSWithInvalidQueryType = errors.New("invalid query type")
+
+	// ErrDNSWithInvalidTransport is the error with which gatus will panic if a dns is configured with an invalid transport
+	ErrDNSWithInvalidTransport = errors.New("invalid transport, must be one of udp, tcp, dot or doh")
+
+	// ErrDNSOverHTTPSWithInvalidURL is the error with which gatus will panic if a dns using DNS over HTTPS is
+	// configured with an url that doesn't start with https://
+	ErrDNSOverHTTPSWithInvalidURL = errors.New("the url of a dns using the doh transport must start with https://")
 )
 
 const (
-	dnsPort = 53
+	dnsPort           = 53
+	dnsOverTLSPort    = 853
+	dnsMessageType    = "application/dns-message"
+	dnsMaximumUDPSize = 4096
+
+	// DNSTransportUDP is the transport used to send plain DNS queries over UDP
+	DNSTransportUDP = "udp"
+
+	// DNSTransportTCP is the transport used to send plain DNS queries over TCP
+	DNSTransportTCP = "tcp"
+
+	// DNSTransportTLS is the transport used to send DNS queries over TLS (RFC 7858)
+	DNSTransportTLS = "dot"
+
+	// DNSTransportHTTPS is the transport used to send DNS queries over HTTPS (RFC 8484)
+	DNSTransportHTTPS = "doh"
+
+	// DefaultDNSTimeout is the default timeout for DNS queries
+	DefaultDNSTimeout = 5 * time.Second
 )
 
 // DNS is the configuration for a Service of type DNS
@@ -27,6 +60,14 @@ type DNS struct {
 
 	// QueryName is the query for DNS
 	QueryName string `yaml:"query-name"`
+
+	// Transport is the transport used to send the query. Can be udp, tcp, dot (DNS over TLS) or doh (DNS over HTTPS).
+	// Defaults to udp.
+	Transport string `yaml:"transport,omitempty"`
+
+	// DNSSEC is whether to validate the DNSSEC signatures of the answers, from the root zone down to the zone
+	// containing the answers. If the answers cannot be validated, the result is marked as unsuccessful.
+	DNSSEC bool `yaml:"dnssec,omitempty"`
 }
 
 func (d *DNS) validateAndSetDefault() error {
@@ -39,48 +80,155 @@ func (d *DNS) validateAndSetDefault() error {
 	if _, ok := dns.StringToType[d.QueryType]; !ok {
 		return ErrDNSWithInvalidQueryType
 	}
+	if len(d.Transport) == 0 {
+		d.Transport = DNSTransportUDP
+	}
+	switch d.Transport {
+	case DNSTransportUDP, DNSTransportTCP, DNSTransportTLS, DNSTransportHTTPS:
+	default:
+		return ErrDNSWithInvalidTransport
+	}
 	return nil
 }
 
-func (d *DNS) query(url string, result *Result) {
-	if !strings.Contains(url, ":") {
-		url = fmt.Sprintf("%s:%d", url, dnsPort)
+// query sends the DNS query to the given url and stores the answers in the result
+//
+// The first answer whose type match the query type is stored in the body, and all of them are stored in
+// Result.DNSAnswers. The client configuration is only used by the dot and doh transports. If the timeout is 0,
+// DefaultDNSTimeout is used.
+func (d *DNS) query(url string, result *Result, clientConfig *client.Config, insecure bool, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = DefaultDNSTimeout
 	}
+	exchange := d.newExchangeFunc(url, clientConfig, insecure, timeout)
 	queryType := dns.StringToType[d.QueryType]
-	c := new(dns.Client)
 	m := new(dns.Msg)
 	m.SetQuestion(d.QueryName, queryType)
-	r, _, err := c.Exchange(m, url)
+	if d.DNSSEC {
+		m.SetEdns0(dnsMaximumUDPSize, true)
+	}
+	r, err := exchange(m)
 	if err != nil {
-		result.AddError(err.Error())
-		return
+		return err
 	}
 	result.Connected = true
 	result.DNSRCode = dns.RcodeToString[r.Rcode]
+	result.DNSAnswers = make([]string, 0, len(r.Answer))
+	var rrset []dns.RR
+	var signatures []*dns.RRSIG
 	for _, rr := range r.Answer {
-		switch rr.Header().Rrtype {
-		case dns.TypeA:
-			if a, ok := rr.(*dns.A); ok {
-				result.body = []byte(a.A.String())
-			}
-		case dns.TypeAAAA:
-			if aaaa, ok := rr.(*dns.AAAA); ok {
-				result.body = []byte(aaaa.AAAA.String())
-			}
-		case dns.TypeCNAME:
-			if cname, ok := rr.(*dns.CNAME); ok {
-				result.body = []byte(cname.Target)
-			}
-		case dns.TypeMX:
-			if mx, ok := rr.(*dns.MX); ok {
-				result.body = []byte(mx.Mx)
-			}
-		case dns.TypeNS:
-			if ns, ok := rr.(*dns.NS); ok {
-				result.body = []byte(ns.Ns)
-			}
-		default:
-			result.body = []byte("query type is not supported yet")
+		if rrsig, ok := rr.(*dns.RRSIG); ok && rrsig.TypeCovered == queryType {
+			signatures = append(signatures, rrsig)
+		}
+		if rr.Header().Rrtype != queryType && queryType != dns.TypeANY {
+			continue
+		}
+		if len(rrset) == 0 || rr.Header().Ttl < result.DNSTTL {
+			result.DNSTTL = rr.Header().Ttl
+		}
+		rrset = append(rrset, rr)
+		result.DNSAnswers = append(result.DNSAnswers, formatDNSAnswer(rr))
+	}
+	result.DNSAnswerCount = len(result.DNSAnswers)
+	if len(result.DNSAnswers) > 0 {
+		result.body = []byte(result.DNSAnswers[0])
+	}
+	if d.DNSSEC && len(rrset) > 0 {
+		validator := newDNSSECValidator(exchange, rootTrustAnchors)
+		if err = validator.validate(rrset, signatures); err != nil {
+			result.Success = false
+			return fmt.Errorf("DNSSEC validation failed: %w", err)
 		}
 	}
+	return nil
+}
+
+// newExchangeFunc returns a function that sends a DNS message to the given url using the transport of the DNS
+// configuration, and returns the response
+func (d *DNS) newExchangeFunc(url string, clientConfig *client.Config, insecure bool, timeout time.Duration) func(*dns.Msg) (*dns.Msg, error) {
+	if d.Transport == DNSTransportHTTPS {
+		httpClient := clientConfig.GetHTTPClient(insecure, timeout)
+		return func(m *dns.Msg) (*dns.Msg, error) {
+			return exchangeOverHTTPS(httpClient, url, m)
+		}
+	}
+	c := &dns.Client{Net: d.Transport, Timeout: timeout}
+	port := dnsPort
+	if d.Transport == DNSTransportTLS {
+		host := url
+		if h, _, err := net.SplitHostPort(url); err == nil {
+			host = h
+		}
+		c.Net = "tcp-tls"
+		c.TLSConfig = clientConfig.GetTLSConfig(insecure, host)
+		port = dnsOverTLSPort
+	} else if d.DNSSEC {
+		c.UDPSize = dnsMaximumUDPSize
+	}
+	if _, _, err := net.SplitHostPort(url); err != nil {
+		url = net.JoinHostPort(url, strconv.Itoa(port))
+	}
+	return func(m *dns.Msg) (*dns.Msg, error) {
+		r, _, err := c.Exchange(m, url)
+		return r, err
+	}
+}
+
+// exchangeOverHTTPS sends a DNS message to the given url using DNS over HTTPS (RFC 8484) and returns the response
+func exchangeOverHTTPS(httpClient *http.Client, url string, m *dns.Msg) (*dns.Msg, error) {
+	// The ID should be 0 to maximize the cache friendliness of the request
+	m.Id = 0
+	packedMessage, err := m.Pack()
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(packedMessage))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", dnsMessageType)
+	request.Header.Set("Accept", dnsMessageType)
+	request.Header.Set(UserAgentHeader, GatusUserAgent)
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status %d", response.StatusCode)
+	}
+	packedResponse, err := ioutil.ReadAll(io.LimitReader(response.Body, dns.MaxMsgSize))
+	if err != nil {
+		return nil, err
+	}
+	r := new(dns.Msg)
+	if err = r.Unpack(packedResponse); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// formatDNSAnswer returns the data of a resource record as a string
+//
+// The records whose data is a single value, such as A or CNAME records, are formatted as that value, and the other
+// records, such as SRV or SOA records, are formatted using their presentation format, without the header.
+func formatDNSAnswer(rr dns.RR) string {
+	switch record := rr.(type) {
+	case *dns.A:
+		return record.A.String()
+	case *dns.AAAA:
+		return record.AAAA.String()
+	case *dns.CNAME:
+		return record.Target
+	case *dns.MX:
+		return record.Mx
+	case *dns.NS:
+		return record.Ns
+	case *dns.PTR:
+		return record.Ptr
+	case *dns.TXT:
+		return strings.Join(record.Txt, "")
+	default:
+		return strings.TrimPrefix(rr.String(), rr.Header().String())
+	}
 }
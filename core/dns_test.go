@@ -1,9 +1,17 @@
 package core
 
 import (
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/TwinProduction/gatus/pattern"
+	"github.com/miekg/dns"
 )
 
 func TestIntegrationQuery(t *testing.T) {
@@ -81,8 +89,8 @@ func TestIntegrationQuery(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			dns := test.inputDNS
 			result := &Result{}
-			dns.query(test.inputURL, result)
-			if test.isErrExpected && len(result.Errors) == 0 {
+			err := dns.query(test.inputURL, result, nil, false, 0)
+			if test.isErrExpected && err == nil {
 				t.Errorf("there should be errors")
 			}
 			if result.DNSRCode != test.expectedDNSCode {
@@ -126,3 +134,260 @@ func TestService_ValidateAndSetDefaultsWithInvalidDNSQueryType(t *testing.T) {
 		t.Fatal("Should've returned an error because service`s dns query type is invalid, it needs to be a valid query name like A, AAAA, CNAME...")
 	}
 }
+
+// dnsTestRecords are the records served by the DNS servers started by startDNSServers
+var dnsTestRecords = []string{
+	"example.org. 300 IN A 192.0.2.1",
+	"example.org. 60 IN A 192.0.2.2",
+	"example.org. 3600 IN TXT \"v=spf1 \" \"-all\"",
+	"_sip._tcp.example.org. 86400 IN SRV 10 5 5060 sip.example.org.",
+	"1.2.0.192.in-addr.arpa. 3600 IN PTR example.org.",
+	"example.org. 3600 IN SOA ns1.example.org. admin.example.org. 2021010101 7200 3600 1209600 3600",
+	"www.example.org. 300 IN CNAME example.org.",
+}
+
+// newDNSTestResponse returns the response to a DNS query for the given records
+func newDNSTestResponse(records []dns.RR, request *dns.Msg) *dns.Msg {
+	response := new(dns.Msg)
+	response.SetReply(request)
+	question := request.Question[0]
+	for _, rr := range records {
+		if strings.EqualFold(rr.Header().Name, question.Name) && (rr.Header().Rrtype == question.Qtype || rr.Header().Rrtype == dns.TypeCNAME || (rr.Header().Rrtype == dns.TypeRRSIG && rr.(*dns.RRSIG).TypeCovered == question.Qtype)) {
+			response.Answer = append(response.Answer, rr)
+		}
+	}
+	if len(response.Answer) == 0 {
+		response.Rcode = dns.RcodeNameError
+	}
+	return response
+}
+
+func parseDNSTestRecords(t *testing.T, records []string) []dns.RR {
+	var rrs []dns.RR
+	for _, record := range records {
+		rr, err := dns.NewRR(record)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rrs = append(rrs, rr)
+	}
+	return rrs
+}
+
+// startDNSServers starts a DNS server for each transport and returns the url to use for each of them
+func startDNSServers(t *testing.T, records []dns.RR) map[string]string {
+	handler := dns.HandlerFunc(func(writer dns.ResponseWriter, request *dns.Msg) {
+		_ = writer.WriteMsg(newDNSTestResponse(records, request))
+	})
+	urls := make(map[string]string)
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	udpServer := &dns.Server{PacketConn: packetConn, Handler: handler}
+	go udpServer.ActivateAndServe()
+	t.Cleanup(func() { _ = udpServer.Shutdown() })
+	urls[DNSTransportUDP] = packetConn.LocalAddr().String()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tcpServer := &dns.Server{Listener: listener, Handler: handler}
+	go tcpServer.ActivateAndServe()
+	t.Cleanup(func() { _ = tcpServer.Shutdown() })
+	urls[DNSTransportTCP] = listener.Addr().String()
+	httpsServer := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := ioutil.ReadAll(request.Body)
+		m := new(dns.Msg)
+		if request.Method != http.MethodPost || request.Header.Get("Content-Type") != dnsMessageType || m.Unpack(body) != nil {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		response, _ := newDNSTestResponse(records, m).Pack()
+		writer.Header().Set("Content-Type", dnsMessageType)
+		_, _ = writer.Write(response)
+	}))
+	t.Cleanup(httpsServer.Close)
+	urls[DNSTransportHTTPS] = httpsServer.URL + "/dns-query"
+	listener, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tlsListener := tls.NewListener(listener, httpsServer.TLS)
+	tlsServer := &dns.Server{Listener: tlsListener, Net: "tcp-tls", Handler: handler}
+	go tlsServer.ActivateAndServe()
+	t.Cleanup(func() { _ = tlsServer.Shutdown() })
+	urls[DNSTransportTLS] = tlsListener.Addr().String()
+	return urls
+}
+
+func TestDNS_query(t *testing.T) {
+	urls := startDNSServers(t, parseDNSTestRecords(t, dnsTestRecords))
+	scenarios := []struct {
+		name                string
+		dns                 DNS
+		expectedDNSCode     string
+		expectedBody        string
+		expectedAnswers     string
+		expectedTTL         uint32
+		expectedAnswerCount int
+	}{
+		{
+			name:                "A",
+			dns:                 DNS{QueryType: "A", QueryName: "example.org."},
+			expectedDNSCode:     "NOERROR",
+			expectedBody:        "192.0.2.1",
+			expectedAnswers:     `["192.0.2.1","192.0.2.2"]`,
+			expectedTTL:         60,
+			expectedAnswerCount: 2,
+		},
+		{
+			name:                "A-through-CNAME",
+			dns:                 DNS{QueryType: "A", QueryName: "www.example.org."},
+			expectedDNSCode:     "NOERROR",
+			expectedBody:        "",
+			expectedAnswers:     `[]`,
+			expectedAnswerCount: 0,
+		},
+		{
+			name:                "CNAME",
+			dns:                 DNS{QueryType: "CNAME", QueryName: "www.example.org."},
+			expectedDNSCode:     "NOERROR",
+			expectedBody:        "example.org.",
+			expectedAnswers:     `["example.org."]`,
+			expectedTTL:         300,
+			expectedAnswerCount: 1,
+		},
+		{
+			name:                "TXT",
+			dns:                 DNS{QueryType: "TXT", QueryName: "example.org."},
+			expectedDNSCode:     "NOERROR",
+			expectedBody:        "v=spf1 -all",
+			expectedAnswers:     `["v=spf1 -all"]`,
+			expectedTTL:         3600,
+			expectedAnswerCount: 1,
+		},
+		{
+			name:                "SRV",
+			dns:                 DNS{QueryType: "SRV", QueryName: "_sip._tcp.example.org."},
+			expectedDNSCode:     "NOERROR",
+			expectedBody:        "10 5 5060 sip.example.org.",
+			expectedAnswers:     `["10 5 5060 sip.example.org."]`,
+			expectedTTL:         86400,
+			expectedAnswerCount: 1,
+		},
+		{
+			name:                "PTR",
+			dns:                 DNS{QueryType: "PTR", QueryName: "1.2.0.192.in-addr.arpa."},
+			expectedDNSCode:     "NOERROR",
+			expectedBody:        "example.org.",
+			expectedAnswers:     `["example.org."]`,
+			expectedTTL:         3600,
+			expectedAnswerCount: 1,
+		},
+		{
+			name:                "SOA",
+			dns:                 DNS{QueryType: "SOA", QueryName: "example.org."},
+			expectedDNSCode:     "NOERROR",
+			expectedBody:        "ns1.example.org. admin.example.org. 2021010101 7200 3600 1209600 3600",
+			expectedAnswers:     `["ns1.example.org. admin.example.org. 2021010101 7200 3600 1209600 3600"]`,
+			expectedTTL:         3600,
+			expectedAnswerCount: 1,
+		},
+		{
+			name:                "NXDOMAIN",
+			dns:                 DNS{QueryType: "A", QueryName: "nonexistent.example.org."},
+			expectedDNSCode:     "NXDOMAIN",
+			expectedBody:        "",
+			expectedAnswers:     `[]`,
+			expectedAnswerCount: 0,
+		},
+	}
+	for _, transport := range []string{DNSTransportUDP, DNSTransportTCP, DNSTransportTLS, DNSTransportHTTPS} {
+		for _, scenario := range scenarios {
+			t.Run(transport+"-"+scenario.name, func(t *testing.T) {
+				d := scenario.dns
+				d.Transport = transport
+				if err := d.validateAndSetDefault(); err != nil {
+					t.Fatal("expected no error, got", err.Error())
+				}
+				result := &Result{}
+				if err := d.query(urls[transport], result, nil, true, time.Second); err != nil {
+					t.Fatal("expected no error, got", err.Error())
+				}
+				if result.DNSRCode != scenario.expectedDNSCode {
+					t.Errorf("expected DNS code to be %s, got %s", scenario.expectedDNSCode, result.DNSRCode)
+				}
+				if string(result.body) != scenario.expectedBody {
+					t.Errorf("expected body to be %s, got %s", scenario.expectedBody, string(result.body))
+				}
+				if answers := string(result.dnsAnswersAsJSON()); answers != scenario.expectedAnswers {
+					t.Errorf("expected answers to be %s, got %s", scenario.expectedAnswers, answers)
+				}
+				if result.DNSTTL != scenario.expectedTTL {
+					t.Errorf("expected TTL to be %d, got %d", scenario.expectedTTL, result.DNSTTL)
+				}
+				if result.DNSAnswerCount != scenario.expectedAnswerCount {
+					t.Errorf("expected answer count to be %d, got %d", scenario.expectedAnswerCount, result.DNSAnswerCount)
+				}
+			})
+		}
+	}
+}
+
+func TestService_EvaluateHealthForDNSWithMultipleAnswers(t *testing.T) {
+	urls := startDNSServers(t, parseDNSTestRecords(t, dnsTestRecords))
+	conditions := []*Condition{}
+	for _, condition := range []Condition{
+		"[DNS_RCODE] == NOERROR",
+		"[BODY] == 192.0.2.1",
+		"len([DNS_ANSWERS]) == 2",
+		"[DNS_ANSWER_COUNT] == 2",
+		"[DNS_TTL] >= 60",
+		`[DNS_ANSWERS] == pat(*"192.0.2.2"*)`,
+		"[DNS_ANSWERS][0] == any(192.0.2.1, 192.0.2.2)",
+		"[DNS_ANSWERS][1] == any(192.0.2.1, 192.0.2.2)",
+	} {
+		condition := condition
+		conditions = append(conditions, &condition)
+	}
+	service := Service{
+		Name:       "dns",
+		URL:        urls[DNSTransportTCP],
+		DNS:        &DNS{QueryType: "A", QueryName: "example.org", Transport: DNSTransportTCP},
+		Conditions: conditions,
+	}
+	if err := service.ValidateAndSetDefaults(); err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	result := service.EvaluateHealth()
+	for _, conditionResult := range result.ConditionResults {
+		if !conditionResult.Success {
+			t.Errorf("expected condition '%s' to succeed", conditionResult.Condition)
+		}
+	}
+}
+
+func TestDNS_validateAndSetDefaultWithTransport(t *testing.T) {
+	d := &DNS{QueryType: "A", QueryName: "example.org"}
+	if err := d.validateAndSetDefault(); err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	if d.Transport != DNSTransportUDP {
+		t.Errorf("expected transport to default to %s, got %s", DNSTransportUDP, d.Transport)
+	}
+	d = &DNS{QueryType: "A", QueryName: "example.org", Transport: "quic"}
+	if err := d.validateAndSetDefault(); err != ErrDNSWithInvalidTransport {
+		t.Errorf("expected error %v, got %v", ErrDNSWithInvalidTransport, err)
+	}
+	condition := Condition("[DNS_RCODE] == NOERROR")
+	service := &Service{
+		Name:       "doh",
+		URL:        "1.1.1.1",
+		DNS:        &DNS{QueryType: "A", QueryName: "example.org", Transport: DNSTransportHTTPS},
+		Conditions: []*Condition{&condition},
+	}
+	if err := service.ValidateAndSetDefaults(); err != ErrDNSOverHTTPSWithInvalidURL {
+		t.Errorf("expected error %v, got %v", ErrDNSOverHTTPSWithInvalidURL, err)
+	}
+}
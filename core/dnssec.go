@@ -0,0 +1,187 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+var (
+	// rootTrustAnchors are the DS records of the key signing keys of the root zone, as published by IANA
+	rootTrustAnchors = []*dns.DS{
+		{Hdr: dns.RR_Header{Name: ".", Rrtype: dns.TypeDS, Class: dns.ClassINET}, KeyTag: 20326, Algorithm: dns.RSASHA256, DigestType: dns.SHA256, Digest: "E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D"},
+		{Hdr: dns.RR_Header{Name: ".", Rrtype: dns.TypeDS, Class: dns.ClassINET}, KeyTag: 38696, Algorithm: dns.RSASHA256, DigestType: dns.SHA256, Digest: "683D2D0ACB8C9B712A1948B27F741219298D0A450D612C483AF444A4C0FB2B16"},
+	}
+
+	errNoValidSignature = errors.New("no valid signature")
+)
+
+// dnssecValidator validates the DNSSEC signatures of resource records by building the chain of trust from the zone
+// that signed them up to the root zone
+type dnssecValidator struct {
+	// exchange sends a DNS message and returns the response
+	exchange func(*dns.Msg) (*dns.Msg, error)
+
+	// trustAnchors are the DS records of the key signing keys of the root zone
+	trustAnchors []*dns.DS
+
+	// verifiedKeys are the DNSKEY records that have already been verified, by zone
+	verifiedKeys map[string][]*dns.DNSKEY
+
+	// zonesBeingVerified are the zones whose DNSKEY records are being verified, which is used to detect a chain of
+	// trust that loops back to a zone instead of leading to the root zone
+	zonesBeingVerified map[string]bool
+}
+
+// newDNSSECValidator creates a dnssecValidator that sends its queries using the given exchange function
+func newDNSSECValidator(exchange func(*dns.Msg) (*dns.Msg, error), trustAnchors []*dns.DS) *dnssecValidator {
+	return &dnssecValidator{
+		exchange:           exchange,
+		trustAnchors:       trustAnchors,
+		verifiedKeys:       make(map[string][]*dns.DNSKEY),
+		zonesBeingVerified: make(map[string]bool),
+	}
+}
+
+// validate checks that the resource record set is signed by one of the given signatures, and that the key that
+// created the signature can be trusted
+//
+// Only the signatures created by a zone that is authoritative for the resource record set are taken into account,
+// as otherwise, any signed zone could vouch for the records of another zone.
+func (v *dnssecValidator) validate(rrset []dns.RR, signatures []*dns.RRSIG) error {
+	if len(signatures) == 0 {
+		return fmt.Errorf("no signature found for %s", rrset[0].Header().Name)
+	}
+	var lastErr error
+	for _, signature := range signatures {
+		if !isAuthoritativeSigner(signature.SignerName, rrset[0].Header()) {
+			lastErr = fmt.Errorf("%s is not allowed to sign the %s records of %s", signature.SignerName, dns.TypeToString[rrset[0].Header().Rrtype], rrset[0].Header().Name)
+			continue
+		}
+		keys, err := v.getVerifiedKeys(signature.SignerName)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if verifySignature(signature, keys, rrset) {
+			return nil
+		}
+	}
+	if lastErr != nil {
+		return lastErr
+	}
+	return fmt.Errorf("%w for %s", errNoValidSignature, rrset[0].Header().Name)
+}
+
+// getVerifiedKeys returns the DNSKEY records of a zone, after having verified that they are signed by a key signing
+// key whose digest is published by the parent zone, or, for the root zone, by one of the trust anchors
+func (v *dnssecValidator) getVerifiedKeys(zone string) ([]*dns.DNSKEY, error) {
+	zone = dns.CanonicalName(zone)
+	if keys, ok := v.verifiedKeys[zone]; ok {
+		return keys, nil
+	}
+	if v.zonesBeingVerified[zone] {
+		return nil, fmt.Errorf("the chain of trust of %s loops back to itself", zone)
+	}
+	v.zonesBeingVerified[zone] = true
+	defer delete(v.zonesBeingVerified, zone)
+	keyRRSet, keySignatures, err := v.lookup(zone, dns.TypeDNSKEY)
+	if err != nil {
+		return nil, err
+	}
+	var keys []*dns.DNSKEY
+	for _, rr := range keyRRSet {
+		keys = append(keys, rr.(*dns.DNSKEY))
+	}
+	var delegationSigners []*dns.DS
+	if zone == "." {
+		delegationSigners = v.trustAnchors
+	} else {
+		dsRRSet, dsSignatures, err := v.lookup(zone, dns.TypeDS)
+		if err != nil {
+			return nil, err
+		}
+		if len(dsRRSet) == 0 {
+			return nil, fmt.Errorf("no DS record found for %s", zone)
+		}
+		// The DS records are published and signed by the parent zone
+		if err = v.validate(dsRRSet, dsSignatures); err != nil {
+			return nil, err
+		}
+		for _, rr := range dsRRSet {
+			delegationSigners = append(delegationSigners, rr.(*dns.DS))
+		}
+	}
+	var keySigningKeys []*dns.DNSKEY
+	for _, key := range keys {
+		for _, ds := range delegationSigners {
+			if key.KeyTag() != ds.KeyTag || key.Algorithm != ds.Algorithm {
+				continue
+			}
+			if digest := key.ToDS(ds.DigestType); digest != nil && strings.EqualFold(digest.Digest, ds.Digest) {
+				keySigningKeys = append(keySigningKeys, key)
+			}
+		}
+	}
+	if len(keySigningKeys) == 0 {
+		return nil, fmt.Errorf("no DNSKEY of %s matches its DS records", zone)
+	}
+	for _, signature := range keySignatures {
+		if verifySignature(signature, keySigningKeys, keyRRSet) {
+			v.verifiedKeys[zone] = keys
+			return keys, nil
+		}
+	}
+	return nil, fmt.Errorf("%w for the DNSKEY records of %s", errNoValidSignature, zone)
+}
+
+// lookup queries the records of the given type for the given name, and returns them along with their signatures
+func (v *dnssecValidator) lookup(name string, queryType uint16) ([]dns.RR, []*dns.RRSIG, error) {
+	m := new(dns.Msg)
+	m.SetQuestion(name, queryType)
+	m.SetEdns0(dnsMaximumUDPSize, true)
+	r, err := v.exchange(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if r.Rcode != dns.RcodeSuccess {
+		return nil, nil, fmt.Errorf("failed to query %s records of %s: %s", dns.TypeToString[queryType], name, dns.RcodeToString[r.Rcode])
+	}
+	var rrset []dns.RR
+	var signatures []*dns.RRSIG
+	for _, rr := range r.Answer {
+		if rr.Header().Rrtype == queryType {
+			rrset = append(rrset, rr)
+		} else if rrsig, ok := rr.(*dns.RRSIG); ok && rrsig.TypeCovered == queryType {
+			signatures = append(signatures, rrsig)
+		}
+	}
+	return rrset, signatures, nil
+}
+
+// isAuthoritativeSigner checks whether the zone that created a signature is allowed to sign the resource records
+// with the given header, which is the case if the zone is the owner name of the records or one of its ancestors.
+// Because the DS records of a zone are published by its parent zone, they must be signed by one of its ancestors.
+func isAuthoritativeSigner(signerName string, header *dns.RR_Header) bool {
+	signer, owner := dns.CanonicalName(signerName), dns.CanonicalName(header.Name)
+	if !dns.IsSubDomain(signer, owner) {
+		return false
+	}
+	return header.Rrtype != dns.TypeDS || signer != owner
+}
+
+// verifySignature checks whether the signature is currently valid and was created by one of the keys
+func verifySignature(signature *dns.RRSIG, keys []*dns.DNSKEY, rrset []dns.RR) bool {
+	if !signature.ValidityPeriod(time.Now()) {
+		return false
+	}
+	for _, key := range keys {
+		if key.KeyTag() == signature.KeyTag && signature.Verify(key, rrset) == nil {
+			return true
+		}
+	}
+	return false
+}
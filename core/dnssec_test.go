@@ -0,0 +1,208 @@
+package core
+
+import (
+	"crypto"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// dnssecTestZone is a zone with a single key, which is used to sign all of its records
+type dnssecTestZone struct {
+	name       string
+	key        *dns.DNSKEY
+	privateKey crypto.Signer
+}
+
+func newDNSSECTestZone(t *testing.T, name string) *dnssecTestZone {
+	key := &dns.DNSKEY{
+		Hdr:       dns.RR_Header{Name: name, Rrtype: dns.TypeDNSKEY, Class: dns.ClassINET, Ttl: 3600},
+		Flags:     dns.ZONE | dns.SEP,
+		Protocol:  3,
+		Algorithm: dns.ECDSAP256SHA256,
+	}
+	privateKey, err := key.Generate(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &dnssecTestZone{name: name, key: key, privateKey: privateKey.(crypto.Signer)}
+}
+
+// sign returns the records along with their signature
+func (z *dnssecTestZone) sign(t *testing.T, rrset ...dns.RR) []dns.RR {
+	signature := &dns.RRSIG{
+		Hdr:        dns.RR_Header{Ttl: rrset[0].Header().Ttl},
+		Algorithm:  z.key.Algorithm,
+		Inception:  uint32(time.Now().Add(-time.Hour).Unix()),
+		Expiration: uint32(time.Now().Add(time.Hour).Unix()),
+		KeyTag:     z.key.KeyTag(),
+		SignerName: z.name,
+	}
+	if err := signature.Sign(z.privateKey, rrset); err != nil {
+		t.Fatal(err)
+	}
+	return append(rrset, signature)
+}
+
+// newDNSSECTestRecords returns the records of a root zone delegating example. to a child zone, in which
+// www.example. has a signed A record. The DS record of the root zone's key is returned as trust anchor.
+func newDNSSECTestRecords(t *testing.T) ([]dns.RR, *dns.DS) {
+	root := newDNSSECTestZone(t, ".")
+	example := newDNSSECTestZone(t, "example.")
+	ds := example.key.ToDS(dns.SHA256)
+	ds.Hdr = dns.RR_Header{Name: "example.", Rrtype: dns.TypeDS, Class: dns.ClassINET, Ttl: 3600}
+	a, _ := dns.NewRR("www.example. 300 IN A 192.0.2.1")
+	var records []dns.RR
+	records = append(records, root.sign(t, root.key)...)
+	records = append(records, root.sign(t, ds)...)
+	records = append(records, example.sign(t, example.key)...)
+	records = append(records, example.sign(t, a)...)
+	return records, root.key.ToDS(dns.SHA256)
+}
+
+func TestDNS_queryWithDNSSEC(t *testing.T) {
+	records, trustAnchor := newDNSSECTestRecords(t)
+	defaultRootTrustAnchors := rootTrustAnchors
+	defer func() { rootTrustAnchors = defaultRootTrustAnchors }()
+	d := &DNS{QueryType: "A", QueryName: "www.example.", Transport: DNSTransportTCP, DNSSEC: true}
+	// With the right trust anchor, the chain of trust can be built
+	rootTrustAnchors = []*dns.DS{trustAnchor}
+	result := &Result{Success: true}
+	if err := d.query(startDNSServers(t, records)[DNSTransportTCP], result, nil, false, time.Second); err != nil {
+		t.Error("expected no error, got", err.Error())
+	}
+	if !result.Success || string(result.body) != "192.0.2.1" {
+		t.Errorf("expected the answer to have been validated, got success=%v and body=%s", result.Success, string(result.body))
+	}
+	// With the default trust anchors, the chain of trust cannot be built
+	rootTrustAnchors = defaultRootTrustAnchors
+	result = &Result{Success: true}
+	if err := d.query(startDNSServers(t, records)[DNSTransportTCP], result, nil, false, time.Second); err == nil {
+		t.Error("expected an error, because the root key does not match the trust anchors")
+	}
+	if result.Success {
+		t.Error("expected the result to be unsuccessful")
+	}
+}
+
+func TestDNS_queryWithDNSSECAndTamperedAnswer(t *testing.T) {
+	records, trustAnchor := newDNSSECTestRecords(t)
+	defaultRootTrustAnchors := rootTrustAnchors
+	defer func() { rootTrustAnchors = defaultRootTrustAnchors }()
+	rootTrustAnchors = []*dns.DS{trustAnchor}
+	for _, rr := range records {
+		if a, ok := rr.(*dns.A); ok {
+			a.A = []byte{192, 0, 2, 66}
+		}
+	}
+	d := &DNS{QueryType: "A", QueryName: "www.example.", Transport: DNSTransportTCP, DNSSEC: true}
+	result := &Result{Success: true}
+	if err := d.query(startDNSServers(t, records)[DNSTransportTCP], result, nil, false, time.Second); err == nil {
+		t.Error("expected an error, because the answer does not match its signature")
+	}
+	if result.Success {
+		t.Error("expected the result to be unsuccessful")
+	}
+}
+
+func TestDNS_queryWithDNSSECAndUnsignedAnswer(t *testing.T) {
+	d := &DNS{QueryType: "A", QueryName: "example.org.", Transport: DNSTransportTCP, DNSSEC: true}
+	result := &Result{Success: true}
+	if err := d.query(startDNSServers(t, parseDNSTestRecords(t, dnsTestRecords))[DNSTransportTCP], result, nil, false, time.Second); err == nil {
+		t.Error("expected an error, because the answer is not signed")
+	}
+	if result.Success {
+		t.Error("expected the result to be unsuccessful")
+	}
+}
+
+func TestDNS_queryWithDNSSECAndAnswerSignedByAnotherZone(t *testing.T) {
+	root := newDNSSECTestZone(t, ".")
+	// attacker. is delegated by the root zone and has a valid chain of trust, but it isn't authoritative for
+	// www.example.
+	attacker := newDNSSECTestZone(t, "attacker.")
+	ds := attacker.key.ToDS(dns.SHA256)
+	ds.Hdr = dns.RR_Header{Name: "attacker.", Rrtype: dns.TypeDS, Class: dns.ClassINET, Ttl: 3600}
+	a, _ := dns.NewRR("www.example. 300 IN A 192.0.2.66")
+	var records []dns.RR
+	records = append(records, root.sign(t, root.key)...)
+	records = append(records, root.sign(t, ds)...)
+	records = append(records, attacker.sign(t, attacker.key)...)
+	records = append(records, attacker.sign(t, a)...)
+	defaultRootTrustAnchors := rootTrustAnchors
+	defer func() { rootTrustAnchors = defaultRootTrustAnchors }()
+	rootTrustAnchors = []*dns.DS{root.key.ToDS(dns.SHA256)}
+	d := &DNS{QueryType: "A", QueryName: "www.example.", Transport: DNSTransportTCP, DNSSEC: true}
+	result := &Result{Success: true}
+	if err := d.query(startDNSServers(t, records)[DNSTransportTCP], result, nil, false, time.Second); err == nil {
+		t.Error("expected an error, because the answer is signed by a zone that isn't authoritative for it")
+	}
+	if result.Success {
+		t.Error("expected the result to be unsuccessful")
+	}
+}
+
+func TestDNS_queryWithDNSSECAndSelfSignedDS(t *testing.T) {
+	root := newDNSSECTestZone(t, ".")
+	example := newDNSSECTestZone(t, "example.")
+	ds := example.key.ToDS(dns.SHA256)
+	ds.Hdr = dns.RR_Header{Name: "example.", Rrtype: dns.TypeDS, Class: dns.ClassINET, Ttl: 3600}
+	a, _ := dns.NewRR("www.example. 300 IN A 192.0.2.1")
+	var records []dns.RR
+	records = append(records, root.sign(t, root.key)...)
+	// The DS record of example. is signed by example. instead of by the root zone
+	records = append(records, example.sign(t, ds)...)
+	records = append(records, example.sign(t, example.key)...)
+	records = append(records, example.sign(t, a)...)
+	defaultRootTrustAnchors := rootTrustAnchors
+	defer func() { rootTrustAnchors = defaultRootTrustAnchors }()
+	rootTrustAnchors = []*dns.DS{root.key.ToDS(dns.SHA256)}
+	d := &DNS{QueryType: "A", QueryName: "www.example.", Transport: DNSTransportTCP, DNSSEC: true}
+	result := &Result{Success: true}
+	if err := d.query(startDNSServers(t, records)[DNSTransportTCP], result, nil, false, time.Second); err == nil {
+		t.Error("expected an error, because the DS record of example. must be signed by the root zone")
+	}
+	if result.Success {
+		t.Error("expected the result to be unsuccessful")
+	}
+}
+
+func TestDNSSECValidator_getVerifiedKeysWithLoop(t *testing.T) {
+	validator := newDNSSECValidator(func(*dns.Msg) (*dns.Msg, error) {
+		t.Fatal("expected no query to be sent")
+		return nil, nil
+	}, nil)
+	validator.zonesBeingVerified["example."] = true
+	if _, err := validator.getVerifiedKeys("example."); err == nil {
+		t.Error("expected an error, because the keys of example. are already being verified")
+	}
+}
+
+func TestIsAuthoritativeSigner(t *testing.T) {
+	scenarios := []struct {
+		signerName string
+		name       string
+		rrtype     uint16
+		expected   bool
+	}{
+		{signerName: "example.", name: "www.example.", rrtype: dns.TypeA, expected: true},
+		{signerName: "Example.", name: "www.example.", rrtype: dns.TypeA, expected: true},
+		{signerName: ".", name: "www.example.", rrtype: dns.TypeA, expected: true},
+		{signerName: "www.example.", name: "www.example.", rrtype: dns.TypeA, expected: true},
+		{signerName: "attacker.", name: "www.example.", rrtype: dns.TypeA, expected: false},
+		{signerName: "sub.www.example.", name: "www.example.", rrtype: dns.TypeA, expected: false},
+		{signerName: "ample.", name: "www.example.", rrtype: dns.TypeA, expected: false},
+		{signerName: ".", name: "example.", rrtype: dns.TypeDS, expected: true},
+		{signerName: "example.", name: "example.", rrtype: dns.TypeDS, expected: false},
+		{signerName: "org.", name: "example.", rrtype: dns.TypeDS, expected: false},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.signerName+"-"+scenario.name+"-"+dns.TypeToString[scenario.rrtype], func(t *testing.T) {
+			header := &dns.RR_Header{Name: scenario.name, Rrtype: scenario.rrtype, Class: dns.ClassINET}
+			if actual := isAuthoritativeSigner(scenario.signerName, header); actual != scenario.expected {
+				t.Errorf("expected %v, got %v", scenario.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package core
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// TimestampPlaceholder is a placeholder for the current Unix timestamp, in seconds, which can be used in the url,
+	// the headers and the body of a service.
+	//
+	// Values that could replace the placeholder: 1609459200, ...
+	TimestampPlaceholder = "[TIMESTAMP]"
+
+	// UUIDPlaceholder is a placeholder for a random (version 4) UUID, which can be used in the url, the headers and
+	// the body of a service.
+	//
+	// Values that could replace the placeholder: 0b9a5a5c-4cc4-4b0a-9a5e-7a6e8e4e0c3d, ...
+	UUIDPlaceholder = "[UUID]"
+
+	// RandomStringPlaceholderPrefix is the prefix of the placeholder for a random alphanumeric string, which must be
+	// followed by the length of the string and by "]". It can be used in the url, the headers and the body of a service.
+	//
+	// Usage: [RANDOM_STRING_16]
+	RandomStringPlaceholderPrefix = "[RANDOM_STRING_"
+
+	// DatePlaceholderPrefix is the prefix of the placeholder for the current date in UTC, formatted using a Go
+	// layout, which may be followed by a duration to add to the current date. It can be used in the url, the headers
+	// and the body of a service.
+	//
+	// Usage: [DATE(2006-01-02)], [DATE(2006-01-02T15:04:05Z07:00, -5m)]
+	DatePlaceholderPrefix = "[DATE("
+
+	// maximumRandomStringLength is the maximum length of the string replacing a random string placeholder
+	maximumRandomStringLength = 1024
+
+	// randomStringCharacters are the characters a random string is made of
+	randomStringCharacters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+var (
+	// ErrInvalidRandomStringLength is the error with which Gatus will panic if a service uses a random string
+	// placeholder whose length is 0 or exceeds the maximum length
+	ErrInvalidRandomStringLength = fmt.Errorf("the length of a random string placeholder must be between 1 and %d", maximumRandomStringLength)
+
+	// dynamicPlaceholderPattern is the pattern of the placeholders resolved every time a request is sent
+	dynamicPlaceholderPattern = regexp.MustCompile(`\[(?:TIMESTAMP|UUID|RANDOM_STRING_(\d+)|DATE\((.*?)\))\]`)
+
+	errInvalidDynamicPlaceholder = errors.New("invalid dynamic placeholder")
+)
+
+// withResolvedDynamicPlaceholders returns a copy of the service in which the dynamic placeholders of the url, the
+// headers and the body of the service and of its steps have been replaced by their value, or the service itself if
+// it doesn't use any.
+//
+// The placeholders are resolved once per call to EvaluateHealth, which ensures that each placeholder has the same
+// value wherever the url, the headers and the body are used, including in every attempt of a retried evaluation.
+func (service *Service) withResolvedDynamicPlaceholders() *Service {
+	if !usesDynamicPlaceholders(service.URL, service.Body, service.Headers) {
+		usesDynamicPlaceholdersInSteps := false
+		for _, step := range service.Steps {
+			usesDynamicPlaceholdersInSteps = usesDynamicPlaceholdersInSteps || usesDynamicPlaceholders(step.URL, step.Body, step.Headers)
+		}
+		if !usesDynamicPlaceholdersInSteps {
+			return service
+		}
+	}
+	resolvedService := *service
+	resolvedService.URL = resolveDynamicPlaceholders(service.URL)
+	resolvedService.Body = resolveDynamicPlaceholders(service.Body)
+	resolvedService.Headers = resolveDynamicPlaceholdersInHeaders(service.Headers)
+	if len(service.Steps) > 0 {
+		resolvedService.Steps = make([]*Step, 0, len(service.Steps))
+		for _, step := range service.Steps {
+			resolvedStep := *step
+			resolvedStep.URL = resolveDynamicPlaceholders(step.URL)
+			resolvedStep.Body = resolveDynamicPlaceholders(step.Body)
+			resolvedStep.Headers = resolveDynamicPlaceholdersInHeaders(step.Headers)
+			resolvedService.Steps = append(resolvedService.Steps, &resolvedStep)
+		}
+	}
+	return &resolvedService
+}
+
+// usesDynamicPlaceholders returns whether the url, the body or the headers of a request use dynamic placeholders
+func usesDynamicPlaceholders(url, body string, headers map[string]string) bool {
+	if dynamicPlaceholderPattern.MatchString(url) || dynamicPlaceholderPattern.MatchString(body) {
+		return true
+	}
+	for _, value := range headers {
+		if dynamicPlaceholderPattern.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveDynamicPlaceholdersInHeaders returns a copy of the headers in which the dynamic placeholders have been
+// replaced by their value
+func resolveDynamicPlaceholdersInHeaders(headers map[string]string) map[string]string {
+	if headers == nil {
+		return nil
+	}
+	resolvedHeaders := make(map[string]string, len(headers))
+	for name, value := range headers {
+		resolvedHeaders[name] = resolveDynamicPlaceholders(value)
+	}
+	return resolvedHeaders
+}
+
+// validateDynamicPlaceholders checks whether the dynamic placeholders of the url, the headers and the body of the
+// service and of its steps can be resolved
+func (service *Service) validateDynamicPlaceholders() error {
+	templates := []string{service.URL, service.Body}
+	for _, value := range service.Headers {
+		templates = append(templates, value)
+	}
+	for _, step := range service.Steps {
+		templates = append(templates, step.URL, step.Body)
+		for _, value := range step.Headers {
+			templates = append(templates, value)
+		}
+	}
+	for _, template := range templates {
+		if err := validateDynamicPlaceholders(template); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateDynamicPlaceholders checks whether the dynamic placeholders of a template can be resolved
+func validateDynamicPlaceholders(template string) error {
+	for _, match := range dynamicPlaceholderPattern.FindAllStringSubmatch(template, -1) {
+		if len(match[1]) > 0 {
+			if length, err := strconv.Atoi(match[1]); err != nil || length < 1 || length > maximumRandomStringLength {
+				return ErrInvalidRandomStringLength
+			}
+		}
+	}
+	return nil
+}
+
+// resolveDynamicPlaceholders replaces every dynamic placeholder of a template by its value
+func resolveDynamicPlaceholders(template string) string {
+	return dynamicPlaceholderPattern.ReplaceAllStringFunc(template, func(placeholder string) string {
+		value, err := resolveDynamicPlaceholder(placeholder)
+		if err != nil {
+			return placeholder
+		}
+		return value
+	})
+}
+
+// resolveDynamicPlaceholder returns the value of a single dynamic placeholder
+func resolveDynamicPlaceholder(placeholder string) (string, error) {
+	switch {
+	case placeholder == TimestampPlaceholder:
+		return strconv.FormatInt(time.Now().Unix(), 10), nil
+	case placeholder == UUIDPlaceholder:
+		return generateUUID()
+	case strings.HasPrefix(placeholder, RandomStringPlaceholderPrefix):
+		length, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(placeholder, RandomStringPlaceholderPrefix), "]"))
+		if err != nil || length < 1 || length > maximumRandomStringLength {
+			return "", errInvalidDynamicPlaceholder
+		}
+		return generateRandomString(length)
+	case strings.HasPrefix(placeholder, DatePlaceholderPrefix):
+		return formatDate(strings.TrimSuffix(strings.TrimPrefix(placeholder, DatePlaceholderPrefix), ")]"), time.Now()), nil
+	}
+	return "", errInvalidDynamicPlaceholder
+}
+
+// formatDate formats the date in UTC using the given arguments, which are a Go layout, optionally followed by a
+// comma and a duration to add to the date. Because a layout may itself contain commas, only the part after the last
+// comma is considered to be the duration, and only if it can be parsed as one.
+func formatDate(arguments string, date time.Time) string {
+	layout := arguments
+	if i := strings.LastIndex(arguments, ","); i != -1 {
+		if offset, err := time.ParseDuration(strings.TrimSpace(arguments[i+1:])); err == nil {
+			layout = arguments[:i]
+			date = date.Add(offset)
+		}
+	}
+	return date.UTC().Format(strings.TrimSpace(layout))
+}
+
+// generateUUID returns a random (version 4) UUID
+func generateUUID() (string, error) {
+	uuid := make([]byte, 16)
+	if _, err := rand.Read(uuid); err != nil {
+		return "", err
+	}
+	uuid[6] = (uuid[6] & 0x0f) | 0x40 // Version 4
+	uuid[8] = (uuid[8] & 0x3f) | 0x80 // Variant 10
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:16]), nil
+}
+
+// generateRandomString returns a random string of the given length made of randomStringCharacters
+func generateRandomString(length int) (string, error) {
+	randomBytes := make([]byte, length)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+	for i, randomByte := range randomBytes {
+		randomBytes[i] = randomStringCharacters[int(randomByte)%len(randomStringCharacters)]
+	}
+	return string(randomBytes), nil
+}
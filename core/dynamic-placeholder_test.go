@@ -0,0 +1,153 @@
+package core
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestResolveDynamicPlaceholders(t *testing.T) {
+	scenarios := []struct {
+		template        string
+		expectedPattern string
+	}{
+		{template: "no placeholders", expectedPattern: `^no placeholders$`},
+		{template: "https://example.org/?cache-buster=[TIMESTAMP]", expectedPattern: `^https://example\.org/\?cache-buster=\d{10,}$`},
+		{template: "[UUID]", expectedPattern: `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`},
+		{template: `{"nonce":"[RANDOM_STRING_16]"}`, expectedPattern: `^{"nonce":"[A-Za-z0-9]{16}"}$`},
+		{template: "[DATE(2006-01-02)]", expectedPattern: `^\d{4}-\d{2}-\d{2}$`},
+		{template: "[timestamp] [RANDOM_STRING_0] [DATE]", expectedPattern: `^\[timestamp\] \[RANDOM_STRING_0\] \[DATE\]$`},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.template, func(t *testing.T) {
+			if resolved := resolveDynamicPlaceholders(scenario.template); !regexp.MustCompile(scenario.expectedPattern).MatchString(resolved) {
+				t.Errorf("expected %s to match %s", resolved, scenario.expectedPattern)
+			}
+		})
+	}
+	if resolveDynamicPlaceholders("[UUID]") == resolveDynamicPlaceholders("[UUID]") {
+		t.Error("expected every UUID to be different")
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	date := time.Date(2021, time.January, 2, 15, 4, 5, 0, time.FixedZone("EST", -5*60*60))
+	scenarios := []struct {
+		arguments string
+		expected  string
+	}{
+		{arguments: "2006-01-02T15:04:05Z07:00", expected: "2021-01-02T20:04:05Z"},
+		{arguments: "2006-01-02T15:04:05Z07:00, -5m", expected: "2021-01-02T19:59:05Z"},
+		{arguments: "2006-01-02,24h", expected: "2021-01-03"},
+		{arguments: "Mon, 02 Jan 2006", expected: "Sat, 02 Jan 2021"},
+		{arguments: "Mon, 02 Jan 2006, -48h", expected: "Thu, 31 Dec 2020"},
+	}
+	for _, scenario := range scenarios {
+		if formatted := formatDate(scenario.arguments, date); formatted != scenario.expected {
+			t.Errorf("expected %s to be formatted as %s, got %s", scenario.arguments, scenario.expected, formatted)
+		}
+	}
+}
+
+func TestService_ValidateAndSetDefaultsWithInvalidRandomStringLength(t *testing.T) {
+	condition := Condition("[STATUS] == 200")
+	for _, length := range []int{0, maximumRandomStringLength + 1} {
+		service := Service{
+			Name:       "invalid-random-string",
+			URL:        "https://example.org/?nonce=[RANDOM_STRING_" + strconv.Itoa(length) + "]",
+			Conditions: []*Condition{&condition},
+		}
+		if err := service.ValidateAndSetDefaults(); err != ErrInvalidRandomStringLength {
+			t.Errorf("expected error %v for a length of %d, got %v", ErrInvalidRandomStringLength, length, err)
+		}
+	}
+}
+
+func TestService_EvaluateHealthWithDynamicPlaceholders(t *testing.T) {
+	var requests []*http.Request
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := ioutil.ReadAll(request.Body)
+		requests = append(requests, request)
+		bodies = append(bodies, string(body))
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	condition := Condition("[STATUS] == 200")
+	service := Service{
+		Name:       "dynamic-placeholders",
+		URL:        server.URL + "/orders?since=[DATE(2006-01-02T15:04:05Z07:00, -5m)]",
+		Method:     http.MethodPost,
+		Headers:    map[string]string{"Idempotency-Key": "[UUID]"},
+		Body:       `{"timestamp":[TIMESTAMP]}`,
+		Conditions: []*Condition{&condition},
+	}
+	if err := service.ValidateAndSetDefaults(); err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	for i := 0; i < 2; i++ {
+		if result := service.EvaluateHealth(); !result.Success {
+			t.Errorf("expected the evaluation to succeed, got %v", result.Errors)
+		}
+	}
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+	since, err := time.Parse(time.RFC3339, requests[0].URL.Query().Get("since"))
+	if err != nil || time.Since(since) < 5*time.Minute || time.Since(since) > 6*time.Minute {
+		t.Errorf("expected the date to be 5 minutes ago, got %s", requests[0].URL.Query().Get("since"))
+	}
+	if !regexp.MustCompile(`^{"timestamp":\d{10,}}$`).MatchString(bodies[0]) {
+		t.Errorf("expected the timestamp to have been injected into the body, got %s", bodies[0])
+	}
+	if requests[0].Header.Get("Idempotency-Key") == requests[1].Header.Get("Idempotency-Key") {
+		t.Error("expected the UUID to be different for every evaluation")
+	}
+	if service.URL != server.URL+"/orders?since=[DATE(2006-01-02T15:04:05Z07:00, -5m)]" || service.Headers["Idempotency-Key"] != "[UUID]" {
+		t.Error("expected the configuration of the service to have been left untouched")
+	}
+}
+
+func TestService_EvaluateHealthWithDynamicPlaceholdersAndRetry(t *testing.T) {
+	var idempotencyKeys, nonces []string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		idempotencyKeys = append(idempotencyKeys, request.Header.Get("Idempotency-Key"))
+		nonces = append(nonces, request.URL.Query().Get("nonce"))
+		writer.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	condition := Condition("[STATUS] == 200")
+	service := Service{
+		Name:       "dynamic-placeholders-with-retry",
+		URL:        server.URL + "/orders?nonce=[RANDOM_STRING_16]",
+		Method:     http.MethodPost,
+		Headers:    map[string]string{"Idempotency-Key": "[UUID]"},
+		Retry:      &Retry{Attempts: 3, Backoff: time.Millisecond},
+		Conditions: []*Condition{&condition},
+	}
+	if err := service.ValidateAndSetDefaults(); err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	result := service.EvaluateHealth()
+	if result.Success {
+		t.Error("expected the evaluation to fail")
+	}
+	if len(idempotencyKeys) != 3 || len(result.Attempts) != 2 {
+		t.Fatalf("expected 3 requests and 2 previous attempts, got %d requests and %d previous attempts", len(idempotencyKeys), len(result.Attempts))
+	}
+	for i := 1; i < len(idempotencyKeys); i++ {
+		if idempotencyKeys[i] != idempotencyKeys[0] || nonces[i] != nonces[0] {
+			t.Errorf("expected every attempt to send the same values, got %v and %v", idempotencyKeys, nonces)
+		}
+	}
+	if len(idempotencyKeys[0]) == 0 || idempotencyKeys[0] == "[UUID]" || len(nonces[0]) != 16 {
+		t.Errorf("expected the dynamic placeholders to have been resolved, got %s and %s", idempotencyKeys[0], nonces[0])
+	}
+	if result = service.EvaluateHealth(); len(idempotencyKeys) != 6 || idempotencyKeys[3] == idempotencyKeys[0] {
+		t.Error("expected the UUID to be different for every evaluation")
+	}
+}
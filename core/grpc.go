@@ -0,0 +1,24 @@
+package core
+
+// GRPC is the configuration for a Service of type gRPC
+type GRPC struct {
+	// Service is the name of the service whose health should be checked.
+	// If empty, the overall health of the server is checked.
+	Service string `yaml:"service,omitempty"`
+
+	// TLS is whether to connect to the server using TLS
+	TLS bool `yaml:"tls,omitempty"`
+}
+
+// getService returns the name of the service whose health should be checked. The GRPC configuration may be nil.
+func (g *GRPC) getService() string {
+	if g == nil {
+		return ""
+	}
+	return g.Service
+}
+
+// isTLSEnabled returns whether to connect to the server using TLS. The GRPC configuration may be nil.
+func (g *GRPC) isTLSEnabled() bool {
+	return g != nil && g.TLS
+}
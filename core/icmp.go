@@ -0,0 +1,77 @@
+package core
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// DefaultICMPCount is the default number of packets sent to an ICMP service
+	DefaultICMPCount = 1
+
+	// DefaultICMPInterval is the default duration to wait between each packet sent to an ICMP service
+	DefaultICMPInterval = time.Second
+)
+
+var (
+	// ErrICMPWithInvalidCount is the error with which gatus will panic if an ICMP service is configured with a
+	// negative number of packets
+	ErrICMPWithInvalidCount = errors.New("icmp count must be greater than or equal to 1")
+
+	// ErrICMPWithInvalidInterval is the error with which gatus will panic if an ICMP service is configured with a
+	// negative interval
+	ErrICMPWithInvalidInterval = errors.New("icmp interval must not be negative")
+
+	// ErrICMPWithTimeoutShorterThanPackets is the error with which gatus will panic if an ICMP service is configured
+	// with a timeout that doesn't leave enough time for all packets to be sent
+	ErrICMPWithTimeoutShorterThanPackets = errors.New("timeout must be greater than (icmp.count - 1) * icmp.interval for all packets to be sent")
+)
+
+// ICMP is the configuration for a Service of type ICMP
+type ICMP struct {
+	// Count is the number of packets to send.
+	// Defaults to 1.
+	Count int `yaml:"count,omitempty"`
+
+	// Interval is the duration to wait between each packet.
+	// Defaults to 1s.
+	Interval time.Duration `yaml:"interval,omitempty"`
+}
+
+func (i *ICMP) validateAndSetDefault() error {
+	if i.Count < 0 {
+		return ErrICMPWithInvalidCount
+	}
+	if i.Count == 0 {
+		i.Count = DefaultICMPCount
+	}
+	if i.Interval < 0 {
+		return ErrICMPWithInvalidInterval
+	}
+	if i.Interval == 0 {
+		i.Interval = DefaultICMPInterval
+	}
+	return nil
+}
+
+// getCount returns the number of packets to send. The ICMP configuration may be nil.
+func (i *ICMP) getCount() int {
+	if i == nil || i.Count == 0 {
+		return DefaultICMPCount
+	}
+	return i.Count
+}
+
+// getInterval returns the duration to wait between each packet. The ICMP configuration may be nil.
+func (i *ICMP) getInterval() time.Duration {
+	if i == nil || i.Interval == 0 {
+		return DefaultICMPInterval
+	}
+	return i.Interval
+}
+
+// getDurationOfPackets returns the duration it takes to send all packets, not counting the wait for the reply to
+// the last packet. The ICMP configuration may be nil.
+func (i *ICMP) getDurationOfPackets() time.Duration {
+	return time.Duration(i.getCount()-1) * i.getInterval()
+}
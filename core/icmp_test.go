@@ -0,0 +1,98 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestICMP_validateAndSetDefault(t *testing.T) {
+	scenarios := []struct {
+		name             string
+		icmp             ICMP
+		expectedCount    int
+		expectedInterval time.Duration
+		expectedError    error
+	}{
+		{
+			name:             "empty",
+			icmp:             ICMP{},
+			expectedCount:    DefaultICMPCount,
+			expectedInterval: DefaultICMPInterval,
+		},
+		{
+			name:             "count-and-interval",
+			icmp:             ICMP{Count: 5, Interval: 200 * time.Millisecond},
+			expectedCount:    5,
+			expectedInterval: 200 * time.Millisecond,
+		},
+		{
+			name:          "negative-count",
+			icmp:          ICMP{Count: -1},
+			expectedError: ErrICMPWithInvalidCount,
+		},
+		{
+			name:          "negative-interval",
+			icmp:          ICMP{Count: 3, Interval: -time.Second},
+			expectedError: ErrICMPWithInvalidInterval,
+		},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			if err := scenario.icmp.validateAndSetDefault(); err != scenario.expectedError {
+				t.Errorf("expected error %v, got %v", scenario.expectedError, err)
+			}
+			if scenario.expectedError != nil {
+				return
+			}
+			if scenario.icmp.Count != scenario.expectedCount {
+				t.Errorf("expected count to be %d, got %d", scenario.expectedCount, scenario.icmp.Count)
+			}
+			if scenario.icmp.Interval != scenario.expectedInterval {
+				t.Errorf("expected interval to be %s, got %s", scenario.expectedInterval, scenario.icmp.Interval)
+			}
+		})
+	}
+}
+
+func TestService_ValidateAndSetDefaultsWithICMP(t *testing.T) {
+	condition := Condition("[PACKET_LOSS] == 0")
+	service := Service{
+		Name:       "icmp",
+		URL:        "icmp://127.0.0.1",
+		ICMP:       &ICMP{Count: 5},
+		Conditions: []*Condition{&condition},
+	}
+	if err := service.ValidateAndSetDefaults(); err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	// The default timeout must leave enough time for the 4 intervals between the 5 packets
+	if expectedTimeout := 9 * time.Second; service.Timeout != expectedTimeout {
+		t.Errorf("expected timeout to be %s, got %s", expectedTimeout, service.Timeout)
+	}
+	service.Timeout = 4 * time.Second
+	if err := service.ValidateAndSetDefaults(); err != ErrICMPWithTimeoutShorterThanPackets {
+		t.Errorf("expected error %v, got %v", ErrICMPWithTimeoutShorterThanPackets, err)
+	}
+}
+
+func TestIntegrationEvaluateHealthForICMPWithMultiplePackets(t *testing.T) {
+	packetLossCondition := Condition("[PACKET_LOSS] == 0")
+	averageRTTCondition := Condition("[AVG_RTT] < 100")
+	jitterCondition := Condition("[JITTER] < 100")
+	service := Service{
+		Name:       "icmp-test",
+		URL:        "icmp://127.0.0.1",
+		ICMP:       &ICMP{Count: 3, Interval: 10 * time.Millisecond},
+		Conditions: []*Condition{&packetLossCondition, &averageRTTCondition, &jitterCondition},
+	}
+	if err := service.ValidateAndSetDefaults(); err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	result := service.EvaluateHealth()
+	if !result.Connected {
+		t.Error("Because the replies have been received, result.Connected should've been true")
+	}
+	if !result.Success {
+		t.Errorf("Because all conditions passed, this should have been a success, got %v", result.ConditionResults)
+	}
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+var (
+	// ErrInvalidOAuth2Config is the error with which Gatus will panic if a service is configured with an oauth2
+	// block that lacks the token url, the client id or the client secret
+	ErrInvalidOAuth2Config = errors.New("oauth2.token-url, oauth2.client-id and oauth2.client-secret must all be specified")
+)
+
+// OAuth2 is the configuration used to authenticate the requests of a Service with a bearer token obtained from an
+// OAuth2 token endpoint through the client credentials flow
+type OAuth2 struct {
+	// TokenURL is the URL of the token endpoint
+	TokenURL string `yaml:"token-url"`
+
+	// ClientID is the identifier of the client
+	ClientID string `yaml:"client-id"`
+
+	// ClientSecret is the secret of the client
+	ClientSecret string `yaml:"client-secret"`
+
+	// Scopes are the scopes to request
+	Scopes []string `yaml:"scopes,omitempty"`
+
+	// token is the token obtained from the token endpoint, which is reused until it expires
+	token *oauth2.Token
+
+	tokenLock sync.Mutex
+}
+
+func (o *OAuth2) validateAndSetDefault() error {
+	if len(o.TokenURL) == 0 || len(o.ClientID) == 0 || len(o.ClientSecret) == 0 {
+		return ErrInvalidOAuth2Config
+	}
+	return nil
+}
+
+// setAuthorizationHeader sets the Authorization header of the request to the cached token, or, if there's no
+// cached token or if it has expired, to a new token obtained from the token endpoint using the given HTTP client
+func (o *OAuth2) setAuthorizationHeader(request *http.Request, httpClient *http.Client) error {
+	o.tokenLock.Lock()
+	defer o.tokenLock.Unlock()
+	if !o.token.Valid() {
+		config := clientcredentials.Config{
+			ClientID:     o.ClientID,
+			ClientSecret: o.ClientSecret,
+			TokenURL:     o.TokenURL,
+			Scopes:       o.Scopes,
+		}
+		token, err := config.Token(context.WithValue(context.Background(), oauth2.HTTPClient, httpClient))
+		if err != nil {
+			return err
+		}
+		o.token = token
+	}
+	o.token.SetAuthHeader(request)
+	return nil
+}
+
+// invalidateToken discards the cached token, which forces a new token to be obtained on the next request
+func (o *OAuth2) invalidateToken() {
+	o.tokenLock.Lock()
+	defer o.tokenLock.Unlock()
+	o.token = nil
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newOAuth2TestServer returns a server with a token endpoint at /token, which issues a new token on every request,
+// and an API at /api, which only accepts the token that was issued last
+func newOAuth2TestServer(t *testing.T, expiresIn int) (*httptest.Server, *int32) {
+	var numberOfTokensIssued int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/token":
+			clientID, clientSecret, _ := request.BasicAuth()
+			if clientID != "gatus" || clientSecret != "secret" || request.FormValue("grant_type") != "client_credentials" {
+				writer.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			tokenNumber := atomic.AddInt32(&numberOfTokensIssued, 1)
+			writer.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(writer, `{"access_token":"token-%d","token_type":"bearer","expires_in":%d,"scope":"%s"}`, tokenNumber, expiresIn, request.FormValue("scope"))
+		case "/api":
+			if request.Header.Get("Authorization") != fmt.Sprintf("Bearer token-%d", atomic.LoadInt32(&numberOfTokensIssued)) {
+				writer.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			writer.WriteHeader(http.StatusOK)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &numberOfTokensIssued
+}
+
+func TestOAuth2_validateAndSetDefault(t *testing.T) {
+	if err := (&OAuth2{TokenURL: "https://example.org/token", ClientID: "gatus", ClientSecret: "secret"}).validateAndSetDefault(); err != nil {
+		t.Error("expected no error, got", err.Error())
+	}
+	if err := (&OAuth2{TokenURL: "https://example.org/token", ClientID: "gatus"}).validateAndSetDefault(); err != ErrInvalidOAuth2Config {
+		t.Errorf("expected error %v, got %v", ErrInvalidOAuth2Config, err)
+	}
+	if err := (&OAuth2{ClientID: "gatus", ClientSecret: "secret"}).validateAndSetDefault(); err != ErrInvalidOAuth2Config {
+		t.Errorf("expected error %v, got %v", ErrInvalidOAuth2Config, err)
+	}
+}
+
+func TestService_EvaluateHealthWithOAuth2(t *testing.T) {
+	server, numberOfTokensIssued := newOAuth2TestServer(t, 3600)
+	condition := Condition("[STATUS] == 200")
+	service := Service{
+		Name:       "oauth2",
+		URL:        server.URL + "/api",
+		OAuth2:     &OAuth2{TokenURL: server.URL + "/token", ClientID: "gatus", ClientSecret: "secret", Scopes: []string{"health"}},
+		Conditions: []*Condition{&condition},
+	}
+	if err := service.ValidateAndSetDefaults(); err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	for i := 0; i < 3; i++ {
+		if result := service.EvaluateHealth(); !result.Success {
+			t.Errorf("expected the evaluation to succeed, got %v", result.Errors)
+		}
+	}
+	if *numberOfTokensIssued != 1 {
+		t.Errorf("expected the token to have been reused, got %d tokens issued", *numberOfTokensIssued)
+	}
+	// If the token is rejected, a new token must be obtained and the request retried
+	atomic.AddInt32(numberOfTokensIssued, 1)
+	if result := service.EvaluateHealth(); !result.Success {
+		t.Errorf("expected the evaluation to succeed after having obtained a new token, got %v", result.Errors)
+	}
+	if *numberOfTokensIssued != 3 {
+		t.Errorf("expected a new token to have been obtained, got %d tokens issued", *numberOfTokensIssued)
+	}
+}
+
+func TestService_EvaluateHealthWithOAuth2AndExpiredToken(t *testing.T) {
+	// Tokens expiring within 10 seconds are considered expired, so a new token is obtained on every evaluation
+	server, numberOfTokensIssued := newOAuth2TestServer(t, 1)
+	condition := Condition("[STATUS] == 200")
+	service := Service{
+		Name:       "oauth2",
+		URL:        server.URL + "/api",
+		OAuth2:     &OAuth2{TokenURL: server.URL + "/token", ClientID: "gatus", ClientSecret: "secret"},
+		Conditions: []*Condition{&condition},
+	}
+	if err := service.ValidateAndSetDefaults(); err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	for i := 0; i < 2; i++ {
+		if result := service.EvaluateHealth(); !result.Success {
+			t.Errorf("expected the evaluation to succeed, got %v", result.Errors)
+		}
+	}
+	if *numberOfTokensIssued != 2 {
+		t.Errorf("expected a new token to have been obtained for every evaluation, got %d tokens issued", *numberOfTokensIssued)
+	}
+}
+
+func TestService_EvaluateHealthWithOAuth2AndInvalidCredentials(t *testing.T) {
+	server, _ := newOAuth2TestServer(t, 3600)
+	condition := Condition("[STATUS] == 200")
+	service := Service{
+		Name:       "oauth2",
+		URL:        server.URL + "/api",
+		OAuth2:     &OAuth2{TokenURL: server.URL + "/token", ClientID: "gatus", ClientSecret: "invalid"},
+		Conditions: []*Condition{&condition},
+	}
+	if err := service.ValidateAndSetDefaults(); err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	result := service.EvaluateHealth()
+	if result.Success || result.Connected {
+		t.Error("expected the evaluation to fail, because no token could be obtained")
+	}
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "cannot fetch token") {
+		t.Errorf("expected an error about the token, got %v", result.Errors)
+	}
+}
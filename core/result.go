@@ -1,17 +1,46 @@
 package core
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
 	"time"
+
+	"github.com/TwinProduction/gatus/client"
 )
 
+var tlsVersionNames = map[uint16]string{
+	tls.VersionTLS10: "TLS 1.0",
+	tls.VersionTLS11: "TLS 1.1",
+	tls.VersionTLS12: "TLS 1.2",
+	tls.VersionTLS13: "TLS 1.3",
+}
+
 // Result of the evaluation of a Service
 type Result struct {
 	// HTTPStatus is the HTTP response status code
 	HTTPStatus int `json:"status"`
 
+	// FinalURL is the URL of the last request made to an HTTP service, which differs from Service.URL if the service
+	// redirected the request
+	FinalURL string `json:"-"`
+
+	// RedirectCount is the number of redirects followed before reaching FinalURL
+	RedirectCount int `json:"-"`
+
 	// DNSRCode is the response code of a DNS query in a human readable format
 	DNSRCode string `json:"-"`
 
+	// DNSTTL is the lowest TTL of the answers of a DNS query, in seconds
+	DNSTTL uint32 `json:"-"`
+
+	// DNSAnswers are the answers of a DNS query whose type match the query type
+	DNSAnswers []string `json:"-"`
+
+	// DNSAnswerCount is the number of answers of a DNS query whose type match the query type
+	DNSAnswerCount int `json:"-"`
+
 	// Hostname extracted from Service.URL
 	Hostname string `json:"hostname"`
 
@@ -39,12 +68,60 @@ type Result struct {
 	// CertificateExpiration is the duration before the certificate expires
 	CertificateExpiration time.Duration `json:"-"`
 
+	// CertificateIssuer is the distinguished name of the issuer of the certificate
+	CertificateIssuer string `json:"-"`
+
+	// CertificateSubject is the distinguished name of the subject of the certificate
+	CertificateSubject string `json:"-"`
+
+	// CertificateSANs are the subject alternative names (DNS names and IP addresses) of the certificate
+	CertificateSANs []string `json:"-"`
+
+	// CertificateChainValid is whether the certificate chain could be verified, regardless of whether the service
+	// is configured to skip the verification (insecure)
+	CertificateChainValid bool `json:"-"`
+
+	// TLSVersion is the version of TLS negotiated with the server (e.g. TLS 1.3)
+	TLSVersion string `json:"-"`
+
+	// SSHServerVersion is the identification string sent by the SSH server (e.g. SSH-2.0-OpenSSH_8.4)
+	SSHServerVersion string `json:"-"`
+
+	// SSHHostKeyFingerprint is the SHA256 fingerprint of the host key of the SSH server
+	SSHHostKeyFingerprint string `json:"-"`
+
+	// SSHExitCode is the exit code of the command executed on the SSH server
+	SSHExitCode int `json:"-"`
+
+	// GRPCStatus is the serving status returned by the gRPC health checking service (e.g. SERVING)
+	GRPCStatus string `json:"-"`
+
+	// PacketLoss is the percentage of packets sent to an ICMP service for which no reply was received
+	PacketLoss float64 `json:"-"`
+
+	// AverageRTT is the average round-trip time of the packets sent to an ICMP service
+	AverageRTT time.Duration `json:"-"`
+
+	// Jitter is the average of the differences between the round-trip times of consecutive packets sent to an ICMP
+	// service
+	Jitter time.Duration `json:"-"`
+
+	// Attempts are the intermediate attempts that failed and were retried before this result.
+	// Only the outcome of the result itself, which is the final attempt, counts toward the uptime and the alerting.
+	Attempts []*Attempt `json:"attempts,omitempty"`
+
 	// body is the response body
 	//
 	// Note that this variable is only used during the evaluation of a service's health.
 	// This means that the call Service.EvaluateHealth both populates the body (if necessary)
 	// and sets it to nil after the evaluation has been completed.
 	body []byte
+
+	// httpHeaders are the headers of the HTTP response
+	//
+	// Like the body, they are only used during the evaluation of a service's health, and are set to nil after the
+	// evaluation has been completed.
+	httpHeaders http.Header
 }
 
 // AddError adds an error to the result's list of errors.
@@ -58,3 +135,57 @@ func (r *Result) AddError(error string) {
 	}
 	r.Errors = append(r.Errors, error)
 }
+
+// dnsAnswersAsJSON returns the answers of the DNS query as a JSON array of strings
+func (r *Result) dnsAnswersAsJSON() []byte {
+	if r.DNSAnswers == nil {
+		return []byte("[]")
+	}
+	answers, _ := json.Marshal(r.DNSAnswers)
+	return answers
+}
+
+// processPingStatistics populates the fields of the result related to the ICMP packets sent to the service
+//
+// The duration is the average round-trip time, and is left at 0 if no reply was received.
+func (r *Result) processPingStatistics(statistics *client.PingStatistics) {
+	r.Connected = statistics.PacketsReceived > 0
+	r.PacketLoss = statistics.PacketLoss
+	r.AverageRTT = statistics.AverageRTT
+	r.Jitter = statistics.Jitter
+	if r.Connected {
+		r.Duration = statistics.AverageRTT
+	}
+}
+
+// processTLSConnectionState populates the fields of the result related to the certificate presented by the server
+//
+// The certificate chain is verified against the root CAs of the TLS configuration and for its server name, even if
+// the TLS configuration skips the verification.
+func (r *Result) processTLSConnectionState(state *tls.ConnectionState, tlsConfig *tls.Config) {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return
+	}
+	certificate := state.PeerCertificates[0]
+	r.CertificateExpiration = time.Until(certificate.NotAfter)
+	r.CertificateIssuer = certificate.Issuer.String()
+	r.CertificateSubject = certificate.Subject.String()
+	r.CertificateSANs = append([]string{}, certificate.DNSNames...)
+	for _, ip := range certificate.IPAddresses {
+		r.CertificateSANs = append(r.CertificateSANs, ip.String())
+	}
+	if name, exists := tlsVersionNames[state.Version]; exists {
+		r.TLSVersion = name
+	}
+	intermediates := x509.NewCertPool()
+	for _, intermediate := range state.PeerCertificates[1:] {
+		intermediates.AddCert(intermediate)
+	}
+	options := x509.VerifyOptions{Intermediates: intermediates}
+	if tlsConfig != nil {
+		options.Roots = tlsConfig.RootCAs
+		options.DNSName = tlsConfig.ServerName
+	}
+	_, err := certificate.Verify(options)
+	r.CertificateChainValid = err == nil
+}
@@ -2,6 +2,9 @@ package core
 
 import (
 	"testing"
+	"time"
+
+	"github.com/TwinProduction/gatus/client"
 )
 
 func TestResult_AddError(t *testing.T) {
@@ -19,3 +22,42 @@ func TestResult_AddError(t *testing.T) {
 		t.Error("should've had 2 error")
 	}
 }
+
+func TestResult_processPingStatistics(t *testing.T) {
+	scenarios := []struct {
+		Name           string
+		Statistics     *client.PingStatistics
+		ExpectedResult Result
+	}{
+		{
+			Name:           "all-replies-received",
+			Statistics:     &client.PingStatistics{PacketsSent: 3, PacketsReceived: 3, AverageRTT: 20 * time.Millisecond, Jitter: 5 * time.Millisecond},
+			ExpectedResult: Result{Connected: true, Duration: 20 * time.Millisecond, AverageRTT: 20 * time.Millisecond, Jitter: 5 * time.Millisecond},
+		},
+		{
+			Name:           "some-replies-received",
+			Statistics:     &client.PingStatistics{PacketsSent: 4, PacketsReceived: 3, PacketLoss: 25, AverageRTT: 20 * time.Millisecond},
+			ExpectedResult: Result{Connected: true, Duration: 20 * time.Millisecond, PacketLoss: 25, AverageRTT: 20 * time.Millisecond},
+		},
+		{
+			Name:           "no-reply-received",
+			Statistics:     &client.PingStatistics{PacketsSent: 3, PacketsReceived: 0, PacketLoss: 100},
+			ExpectedResult: Result{Connected: false, Duration: 0, PacketLoss: 100},
+		},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			result := &Result{}
+			result.processPingStatistics(scenario.Statistics)
+			if result.Connected != scenario.ExpectedResult.Connected {
+				t.Errorf("expected connected to be %v, got %v", scenario.ExpectedResult.Connected, result.Connected)
+			}
+			if result.Duration != scenario.ExpectedResult.Duration {
+				t.Errorf("expected the duration to be %s, got %s", scenario.ExpectedResult.Duration, result.Duration)
+			}
+			if result.PacketLoss != scenario.ExpectedResult.PacketLoss || result.AverageRTT != scenario.ExpectedResult.AverageRTT || result.Jitter != scenario.ExpectedResult.Jitter {
+				t.Errorf("expected the statistics to have been copied, got packetLoss=%v, averageRTT=%s and jitter=%s", result.PacketLoss, result.AverageRTT, result.Jitter)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrRetryWithInvalidAttempts is the error with which Gatus will panic if a retry policy is configured with a
+	// negative number of attempts
+	ErrRetryWithInvalidAttempts = errors.New("retry.attempts cannot be negative")
+
+	// ErrRetryWithInvalidBackoff is the error with which Gatus will panic if a retry policy is configured with a
+	// negative backoff or a backoff multiplier lower than 1
+	ErrRetryWithInvalidBackoff = errors.New("retry.backoff cannot be negative and retry.backoff-multiplier cannot be lower than 1")
+)
+
+const (
+	// DefaultRetryBackoff is the default duration to wait for before retrying a failed attempt
+	DefaultRetryBackoff = time.Second
+)
+
+// Retry is the retry policy of a Service
+type Retry struct {
+	// Attempts is the maximum number of attempts, including the first one, before the evaluation of a service's
+	// health is considered a failure
+	Attempts int `yaml:"attempts"`
+
+	// Backoff is the duration to wait for before retrying a failed attempt
+	Backoff time.Duration `yaml:"backoff,omitempty"`
+
+	// BackoffMultiplier is the factor by which the backoff is multiplied after every failed attempt.
+	// Defaults to 1, meaning that the backoff is constant.
+	BackoffMultiplier float64 `yaml:"backoff-multiplier,omitempty"`
+}
+
+func (r *Retry) validateAndSetDefault() error {
+	if r.Attempts < 0 {
+		return ErrRetryWithInvalidAttempts
+	}
+	if r.Attempts == 0 {
+		r.Attempts = 1
+	}
+	if r.Backoff < 0 || (r.BackoffMultiplier != 0 && r.BackoffMultiplier < 1) {
+		return ErrRetryWithInvalidBackoff
+	}
+	if r.Backoff == 0 {
+		r.Backoff = DefaultRetryBackoff
+	}
+	if r.BackoffMultiplier == 0 {
+		r.BackoffMultiplier = 1
+	}
+	return nil
+}
+
+// getBackoff returns the duration to wait for before the next attempt, given the number of attempts made so far
+func (r *Retry) getBackoff(attempts int) time.Duration {
+	backoff := float64(r.Backoff)
+	for i := 1; i < attempts; i++ {
+		backoff *= r.BackoffMultiplier
+	}
+	return time.Duration(backoff)
+}
+
+// Attempt is an intermediate attempt to evaluate the health of a service, which failed and was therefore retried
+type Attempt struct {
+	// Errors encountered during the attempt
+	Errors []string `json:"errors,omitempty"`
+
+	// ConditionResults results of the service's conditions during the attempt
+	ConditionResults []*ConditionResult `json:"conditionResults,omitempty"`
+
+	// Duration time that the request took
+	Duration time.Duration `json:"duration"`
+
+	// Timestamp when the attempt was made
+	Timestamp time.Time `json:"timestamp"`
+}
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetry_validateAndSetDefault(t *testing.T) {
+	retry := &Retry{}
+	if err := retry.validateAndSetDefault(); err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	if retry.Attempts != 1 {
+		t.Errorf("expected attempts to default to 1, got %d", retry.Attempts)
+	}
+	if retry.Backoff != DefaultRetryBackoff {
+		t.Errorf("expected backoff to default to %s, got %s", DefaultRetryBackoff, retry.Backoff)
+	}
+	if retry.BackoffMultiplier != 1 {
+		t.Errorf("expected backoff multiplier to default to 1, got %f", retry.BackoffMultiplier)
+	}
+	if err := (&Retry{Attempts: -1}).validateAndSetDefault(); err != ErrRetryWithInvalidAttempts {
+		t.Errorf("expected error %v, got %v", ErrRetryWithInvalidAttempts, err)
+	}
+	if err := (&Retry{Attempts: 3, Backoff: -time.Second}).validateAndSetDefault(); err != ErrRetryWithInvalidBackoff {
+		t.Errorf("expected error %v, got %v", ErrRetryWithInvalidBackoff, err)
+	}
+	if err := (&Retry{Attempts: 3, BackoffMultiplier: 0.5}).validateAndSetDefault(); err != ErrRetryWithInvalidBackoff {
+		t.Errorf("expected error %v, got %v", ErrRetryWithInvalidBackoff, err)
+	}
+}
+
+func TestRetry_getBackoff(t *testing.T) {
+	retry := &Retry{Attempts: 4, Backoff: time.Second, BackoffMultiplier: 2}
+	expectedBackoffs := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+	for i, expectedBackoff := range expectedBackoffs {
+		if backoff := retry.getBackoff(i + 1); backoff != expectedBackoff {
+			t.Errorf("expected backoff after %d attempts to be %s, got %s", i+1, expectedBackoff, backoff)
+		}
+	}
+}
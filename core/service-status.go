@@ -82,8 +82,17 @@ func (ss ServiceStatus) WithResultPagination(page, pageSize int) *ServiceStatus
 }
 
 // AddResult adds a Result to ServiceStatus.Results and makes sure that there are
-// no more than 20 results in the Results slice
+// no more than MaximumNumberOfResults results in the Results slice
 func (ss *ServiceStatus) AddResult(result *Result) {
+	ss.AddResultWithRetention(result, MaximumNumberOfResults, 0)
+}
+
+// AddResultWithRetention adds a Result to ServiceStatus.Results and makes sure that there are
+// no more than maximumNumberOfResults results in the Results slice, and that none of the results
+// are older than maximumResultAge.
+//
+// A maximumNumberOfResults or a maximumResultAge of 0 means that there's no limit.
+func (ss *ServiceStatus) AddResultWithRetention(result *Result, maximumNumberOfResults int, maximumResultAge time.Duration) {
 	if len(ss.Results) > 0 {
 		// Check if there's any change since the last result
 		// OR there's only 1 event, which only happens when there's a start event
@@ -104,11 +113,32 @@ func (ss *ServiceStatus) AddResult(result *Result) {
 		}
 	}
 	ss.Results = append(ss.Results, result)
-	if len(ss.Results) > MaximumNumberOfResults {
+	if maximumNumberOfResults > 0 && len(ss.Results) > maximumNumberOfResults {
 		// Doing ss.Results[1:] would usually be sufficient, but in the case where for some reason, the slice has more
 		// than one extra element, we can get rid of all of them at once and thus returning the slice to a length of
-		// MaximumNumberOfResults by using ss.Results[len(ss.Results)-MaximumNumberOfResults:] instead
-		ss.Results = ss.Results[len(ss.Results)-MaximumNumberOfResults:]
+		// maximumNumberOfResults by using ss.Results[len(ss.Results)-maximumNumberOfResults:] instead
+		ss.Results = ss.Results[len(ss.Results)-maximumNumberOfResults:]
+	}
+	if maximumResultAge > 0 {
+		oldestTimestampAllowed := time.Now().Add(-maximumResultAge)
+		numberOfExpiredResults := 0
+		for numberOfExpiredResults < len(ss.Results) && ss.Results[numberOfExpiredResults].Timestamp.Before(oldestTimestampAllowed) {
+			numberOfExpiredResults++
+		}
+		if numberOfExpiredResults > 0 {
+			ss.Results = ss.Results[numberOfExpiredResults:]
+		}
 	}
 	ss.Uptime.ProcessResult(result)
 }
+
+// ResultsBetween returns the results with a timestamp between from and to, inclusively
+func (ss *ServiceStatus) ResultsBetween(from, to time.Time) []*Result {
+	results := make([]*Result, 0)
+	for _, result := range ss.Results {
+		if !result.Timestamp.Before(from) && !result.Timestamp.After(to) {
+			results = append(results, result)
+		}
+	}
+	return results
+}
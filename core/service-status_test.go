@@ -30,6 +30,44 @@ func TestServiceStatus_AddResult(t *testing.T) {
 	}
 }
 
+func TestServiceStatus_AddResultWithRetention(t *testing.T) {
+	service := &Service{Name: "name", Group: "group"}
+	serviceStatus := NewServiceStatus(service)
+	for i := 0; i < MaximumNumberOfResults+10; i++ {
+		serviceStatus.AddResultWithRetention(&Result{Timestamp: time.Now()}, 0, 0)
+	}
+	if len(serviceStatus.Results) != MaximumNumberOfResults+10 {
+		t.Errorf("expected serviceStatus.Results to have a length of %d, got %d", MaximumNumberOfResults+10, len(serviceStatus.Results))
+	}
+	serviceStatus.AddResultWithRetention(&Result{Timestamp: time.Now()}, 50, 0)
+	if len(serviceStatus.Results) != 50 {
+		t.Errorf("expected serviceStatus.Results to have a length of 50, got %d", len(serviceStatus.Results))
+	}
+	serviceStatus = NewServiceStatus(service)
+	now := time.Now()
+	for i := 10; i > 0; i-- {
+		serviceStatus.AddResultWithRetention(&Result{Timestamp: now.Add(-time.Duration(i) * time.Hour)}, 0, 5*time.Hour+30*time.Minute)
+	}
+	if len(serviceStatus.Results) != 5 {
+		t.Errorf("expected serviceStatus.Results to only have the 5 results from the last 5h30m, got %d", len(serviceStatus.Results))
+	}
+}
+
+func TestServiceStatus_ResultsBetween(t *testing.T) {
+	service := &Service{Name: "name", Group: "group"}
+	serviceStatus := NewServiceStatus(service)
+	now := time.Now()
+	for i := 10; i > 0; i-- {
+		serviceStatus.AddResult(&Result{Timestamp: now.Add(-time.Duration(i) * time.Hour)})
+	}
+	if results := serviceStatus.ResultsBetween(now.Add(-5*time.Hour), now.Add(-3*time.Hour)); len(results) != 3 {
+		t.Errorf("expected 3 results, got %d", len(results))
+	}
+	if results := serviceStatus.ResultsBetween(now.Add(-20*time.Hour), now.Add(-15*time.Hour)); len(results) != 0 {
+		t.Errorf("expected 0 results, got %d", len(results))
+	}
+}
+
 func TestServiceStatus_WithResultPagination(t *testing.T) {
 	service := &Service{Name: "name", Group: "group"}
 	serviceStatus := NewServiceStatus(service)
@@ -2,9 +2,10 @@ package core
 
 import (
 	"bytes"
-	"crypto/x509"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net"
 	"net/http"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/TwinProduction/gatus/alerting/alert"
 	"github.com/TwinProduction/gatus/client"
+	"github.com/TwinProduction/gatus/core/ui"
 )
 
 const (
@@ -39,6 +41,13 @@ var (
 
 	// ErrServiceWithNoName is the error with which Gatus will panic if a service is configured with no name
 	ErrServiceWithNoName = errors.New("you must specify a name for each service")
+
+	// ErrServiceWithInvalidTimeout is the error with which Gatus will panic if a service is configured with a negative timeout
+	ErrServiceWithInvalidTimeout = errors.New("the timeout of a service cannot be negative")
+
+	// ErrServiceWithInvalidMaximumRedirects is the error with which Gatus will panic if a service is configured with
+	// a negative maximum number of redirects
+	ErrServiceWithInvalidMaximumRedirects = errors.New("the maximum number of redirects of a service cannot be negative")
 )
 
 // Service is the configuration of a monitored endpoint
@@ -55,6 +64,15 @@ type Service struct {
 	// DNS is the configuration of DNS monitoring
 	DNS *DNS `yaml:"dns,omitempty"`
 
+	// SSH is the configuration of SSH monitoring, such as the credentials to authenticate with
+	SSH *SSH `yaml:"ssh,omitempty"`
+
+	// GRPC is the configuration of gRPC monitoring, such as the name of the service whose health should be checked
+	GRPC *GRPC `yaml:"grpc,omitempty"`
+
+	// ICMP is the configuration of ICMP monitoring, such as the number of packets to send
+	ICMP *ICMP `yaml:"icmp,omitempty"`
+
 	// Method of the request made to the url of the service
 	Method string `yaml:"method,omitempty"`
 
@@ -67,23 +85,69 @@ type Service struct {
 	// Headers of the request
 	Headers map[string]string `yaml:"headers,omitempty"`
 
+	// OAuth2 is the configuration used to obtain the bearer token sent in the Authorization header of the request
+	OAuth2 *OAuth2 `yaml:"oauth2,omitempty"`
+
 	// Interval is the duration to wait between every status check
 	Interval time.Duration `yaml:"interval,omitempty"`
 
+	// Retry is the retry policy of the service. If nil, the service's health is evaluated only once per interval
+	Retry *Retry `yaml:"retry,omitempty"`
+
+	// FollowRedirects is whether to follow the redirects returned by an HTTP service.
+	// Defaults to true.
+	//
+	// This is a pointer, because it is populated by YAML and we need to know whether it was explicitly set to a value
+	// or not. Use Service.isFollowingRedirects() for a non-pointer
+	FollowRedirects *bool `yaml:"follow-redirects,omitempty"`
+
+	// MaximumRedirects is the maximum number of redirects to follow before failing.
+	// Defaults to client.DefaultMaximumRedirects.
+	MaximumRedirects int `yaml:"max-redirects,omitempty"`
+
+	// Timeout is the maximum duration of a status check, regardless of the type of the service.
+	// Defaults to the default timeout of the type of the service (see Service.getTimeout)
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+
 	// Conditions used to determine the health of the service
 	Conditions []*Condition `yaml:"conditions"`
 
+	// Steps are the requests to make one after the other, such as logging in before calling an API that requires
+	// authentication. If there are steps, URL and Conditions must be specified in each step instead.
+	Steps []*Step `yaml:"steps,omitempty"`
+
 	// Alerts is the alerting configuration for the service in case of failure
 	Alerts []*alert.Alert `yaml:"alerts"`
 
 	// Insecure is whether to skip verifying the server's certificate chain and host name
 	Insecure bool `yaml:"insecure,omitempty"`
 
+	// Protocol is the protocol used to negotiate the upgrade to TLS of services using STARTTLS (starttls://).
+	// Defaults to smtp.
+	Protocol client.StartTLSProtocol `yaml:"protocol,omitempty"`
+
+	// ClientConfig is the configuration of the client used to establish connections, such as the client certificate
+	// to present to the server. Applies to HTTP, STARTTLS, TLS, gRPC and WebSocket services, as well as to DNS services
+	// using the dot or doh transport.
+	ClientConfig *client.Config `yaml:"client,omitempty"`
+
+	// Exclusive is whether no other service may be evaluated while this service is being evaluated.
+	// This is useful for services with conditions on the response time, as evaluating multiple services at the same
+	// time may impact the response time.
+	Exclusive bool `yaml:"exclusive,omitempty"`
+
+	// UIConfig is the configuration for the UI, such as the badges of the service
+	UIConfig *ui.Config `yaml:"ui,omitempty"`
+
 	// NumberOfFailuresInARow is the number of unsuccessful evaluations in a row
 	NumberOfFailuresInARow int
 
 	// NumberOfSuccessesInARow is the number of successful evaluations in a row
 	NumberOfSuccessesInARow int
+
+	// alwaysReadBody is whether to read the body of the response even if no condition uses the BodyPlaceholder,
+	// which is the case for the steps that extract variables from the body (see Step.toService)
+	alwaysReadBody bool
 }
 
 // ValidateAndSetDefaults validates the service's configuration and sets the default value of fields that have one
@@ -118,14 +182,81 @@ func (service *Service) ValidateAndSetDefaults() error {
 	if len(service.Name) == 0 {
 		return ErrServiceWithNoName
 	}
-	if len(service.URL) == 0 {
-		return ErrServiceWithNoURL
+	if len(service.Steps) > 0 {
+		if len(service.URL) > 0 || len(service.Conditions) > 0 {
+			return ErrServiceWithStepsAndURL
+		}
+		if err := service.validateAndSetDefaultsOfSteps(); err != nil {
+			return err
+		}
+	} else {
+		if len(service.URL) == 0 {
+			return ErrServiceWithNoURL
+		}
+		if len(service.Conditions) == 0 {
+			return ErrServiceWithNoCondition
+		}
+	}
+	if service.Timeout < 0 {
+		return ErrServiceWithInvalidTimeout
+	}
+	if err := service.validateDynamicPlaceholders(); err != nil {
+		return err
+	}
+	if service.MaximumRedirects < 0 {
+		return ErrServiceWithInvalidMaximumRedirects
+	}
+	if service.MaximumRedirects == 0 {
+		service.MaximumRedirects = client.DefaultMaximumRedirects
+	}
+	if service.ICMP != nil {
+		if err := service.ICMP.validateAndSetDefault(); err != nil {
+			return err
+		}
+		if service.Timeout > 0 && service.Timeout <= service.ICMP.getDurationOfPackets() {
+			return ErrICMPWithTimeoutShorterThanPackets
+		}
+	}
+	service.Timeout = service.getTimeout()
+	if service.Retry != nil {
+		if err := service.Retry.validateAndSetDefault(); err != nil {
+			return err
+		}
+	}
+	if !service.Protocol.IsValid() {
+		return client.ErrInvalidStartTLSProtocol
+	}
+	if service.ClientConfig != nil {
+		if err := service.ClientConfig.ValidateAndSetDefaults(); err != nil {
+			return err
+		}
 	}
-	if len(service.Conditions) == 0 {
-		return ErrServiceWithNoCondition
+	if service.SSH != nil {
+		if err := service.SSH.validateAndSetDefault(); err != nil {
+			return err
+		}
+	}
+	if service.OAuth2 != nil {
+		if err := service.OAuth2.validateAndSetDefault(); err != nil {
+			return err
+		}
+	}
+	if service.UIConfig == nil {
+		service.UIConfig = ui.GetDefaultConfig()
+	} else if err := service.UIConfig.ValidateAndSetDefaults(); err != nil {
+		return err
 	}
 	if service.DNS != nil {
-		return service.DNS.validateAndSetDefault()
+		if err := service.DNS.validateAndSetDefault(); err != nil {
+			return err
+		}
+		if service.DNS.Transport == DNSTransportHTTPS && !strings.HasPrefix(service.URL, "https://") {
+			return ErrDNSOverHTTPSWithInvalidURL
+		}
+		return nil
+	}
+	if len(service.Steps) > 0 {
+		return nil
 	}
 	// Make sure that the request can be created
 	_, err := http.NewRequest(service.Method, service.URL, bytes.NewBuffer([]byte(service.Body)))
@@ -135,9 +266,67 @@ func (service *Service) ValidateAndSetDefaults() error {
 	return nil
 }
 
+// validateAndSetDefaultsOfSteps validates the steps of the service, making sure that every step only uses the
+// variables extracted by the steps before it
+func (service *Service) validateAndSetDefaultsOfSteps() error {
+	names := make(map[string]bool)
+	definedVariables := make(map[string]bool)
+	for _, step := range service.Steps {
+		if err := step.validateAndSetDefault(definedVariables); err != nil {
+			return err
+		}
+		if names[step.Name] {
+			return ErrStepWithDuplicateName
+		}
+		names[step.Name] = true
+		for name := range step.Variables {
+			definedVariables[name] = true
+		}
+	}
+	return nil
+}
+
 // EvaluateHealth sends a request to the service's URL and evaluates the conditions of the service.
+//
+// If the service has a retry policy, failed attempts are retried until the maximum number of attempts is reached.
+// Only the last attempt is returned as the result, and the previous ones are recorded in Result.Attempts.
+// The dynamic placeholders are resolved before the first attempt, so every attempt sends the same values.
 func (service *Service) EvaluateHealth() *Result {
+	resolvedService := service.withResolvedDynamicPlaceholders()
+	var attempts []*Attempt
+	for {
+		result := resolvedService.evaluateHealthOnce()
+		if result.Success || service.Retry == nil || len(attempts)+1 >= service.Retry.Attempts {
+			result.Attempts = attempts
+			return result
+		}
+		attempts = append(attempts, &Attempt{
+			Errors:           result.Errors,
+			ConditionResults: result.ConditionResults,
+			Duration:         result.Duration,
+			Timestamp:        result.Timestamp,
+		})
+		time.Sleep(service.Retry.getBackoff(len(attempts)))
+	}
+}
+
+// evaluateHealthOnce makes a single attempt at evaluating the health of the service
+func (service *Service) evaluateHealthOnce() *Result {
 	result := &Result{Success: true, Errors: []string{}}
+	if len(service.Steps) > 0 {
+		service.evaluateSteps(result)
+	} else {
+		service.evaluate(result)
+	}
+	result.Timestamp = time.Now()
+	// No need to keep the body and the headers after the service has been evaluated
+	result.body = nil
+	result.httpHeaders = nil
+	return result
+}
+
+// evaluate sends the request of the service and evaluates its conditions
+func (service *Service) evaluate(result *Result) {
 	service.getIP(result)
 	if len(result.Errors) == 0 {
 		service.call(result)
@@ -150,15 +339,49 @@ func (service *Service) EvaluateHealth() *Result {
 			result.Success = false
 		}
 	}
-	result.Timestamp = time.Now()
-	// No need to keep the body after the service has been evaluated
-	result.body = nil
-	return result
+}
+
+// evaluateSteps evaluates the steps of the service one after the other, until one of them fails.
+//
+// The condition results and the errors of every step are added to the result, prefixed by the name of the step, and
+// the duration of the result is the sum of the duration of the steps. Everything else is taken from the last
+// step that was evaluated.
+func (service *Service) evaluateSteps(result *Result) {
+	variables := make(map[string]string)
+	var conditionResults []*ConditionResult
+	errs := []string{}
+	var duration time.Duration
+	for _, step := range service.Steps {
+		stepResult := &Result{Success: true, Errors: []string{}}
+		step.toService(service, variables).evaluate(stepResult)
+		if stepResult.Success {
+			if err := step.extractVariables(stepResult, variables); err != nil {
+				stepResult.AddError(err.Error())
+				stepResult.Success = false
+			}
+		}
+		for _, conditionResult := range stepResult.ConditionResults {
+			conditionResult.Condition = step.Name + ": " + conditionResult.Condition
+			conditionResults = append(conditionResults, conditionResult)
+		}
+		for _, err := range stepResult.Errors {
+			errs = append(errs, step.Name+": "+err)
+		}
+		duration += stepResult.Duration
+		*result = *stepResult
+		result.ConditionResults, result.Errors, result.Duration = conditionResults, errs, duration
+		if !stepResult.Success {
+			break
+		}
+	}
 }
 
 func (service *Service) getIP(result *Result) {
-	if service.DNS != nil {
-		result.Hostname = strings.TrimSuffix(service.URL, ":53")
+	if service.DNS != nil && service.DNS.Transport != DNSTransportHTTPS {
+		result.Hostname = service.URL
+		if host, _, err := net.SplitHostPort(service.URL); err == nil {
+			result.Hostname = host
+		}
 	} else {
 		urlObject, err := url.Parse(service.URL)
 		if err != nil {
@@ -175,55 +398,212 @@ func (service *Service) getIP(result *Result) {
 	result.IP = ips[0].String()
 }
 
+// isFollowingRedirects returns whether to follow the redirects returned by an HTTP service
+func (service *Service) isFollowingRedirects() bool {
+	return service.FollowRedirects == nil || *service.FollowRedirects
+}
+
+// getMaximumRedirects returns the maximum number of redirects to follow, or the default maximum number of redirects
+// if none has been configured
+func (service *Service) getMaximumRedirects() int {
+	if service.MaximumRedirects > 0 {
+		return service.MaximumRedirects
+	}
+	return client.DefaultMaximumRedirects
+}
+
+// getTimeout returns the timeout of the service, or the default timeout for the type of the service if no timeout
+// has been configured
+func (service *Service) getTimeout() time.Duration {
+	if service.Timeout > 0 {
+		return service.Timeout
+	}
+	switch {
+	case service.DNS != nil:
+		return DefaultDNSTimeout
+	case strings.HasPrefix(service.URL, "tcp://"), strings.HasPrefix(service.URL, "starttls://"), strings.HasPrefix(service.URL, "tls://"):
+		return client.DefaultTCPTimeout
+	case strings.HasPrefix(service.URL, "icmp://"):
+		// Leave enough time for all packets to be sent
+		return client.DefaultICMPTimeout + service.ICMP.getDurationOfPackets()
+	case strings.HasPrefix(service.URL, "udp://"):
+		return client.DefaultUDPTimeout
+	case strings.HasPrefix(service.URL, "ssh://"):
+		return client.DefaultSSHTimeout
+	case strings.HasPrefix(service.URL, "grpc://"):
+		return client.DefaultGRPCTimeout
+	default:
+		return client.GetDefaultHTTPTimeout()
+	}
+}
+
+// addError adds an error to the result. If the error was caused by a timeout, the timeout of the service is included
+// in the error, so that timeouts can easily be told apart from other errors
+func (service *Service) addError(result *Result, err error) {
+	if isTimeout(err) {
+		result.AddError(fmt.Sprintf("%s (timeout=%s)", err.Error(), service.getTimeout()))
+	} else {
+		result.AddError(err.Error())
+	}
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
 func (service *Service) call(result *Result) {
 	var request *http.Request
 	var response *http.Response
 	var err error
-	var certificate *x509.Certificate
+	var tlsConnectionState *tls.ConnectionState
 	isServiceDNS := service.DNS != nil
 	isServiceTCP := strings.HasPrefix(service.URL, "tcp://")
 	isServiceICMP := strings.HasPrefix(service.URL, "icmp://")
 	isServiceStartTLS := strings.HasPrefix(service.URL, "starttls://")
-	isServiceHTTP := !isServiceDNS && !isServiceTCP && !isServiceICMP && !isServiceStartTLS
+	isServiceTLS := strings.HasPrefix(service.URL, "tls://")
+	isServiceUDP := strings.HasPrefix(service.URL, "udp://")
+	isServiceSSH := strings.HasPrefix(service.URL, "ssh://")
+	isServiceGRPC := strings.HasPrefix(service.URL, "grpc://")
+	isServiceWebSocket := strings.HasPrefix(service.URL, "ws://") || strings.HasPrefix(service.URL, "wss://")
+	isServiceHTTP := !isServiceDNS && !isServiceTCP && !isServiceICMP && !isServiceStartTLS && !isServiceTLS && !isServiceUDP && !isServiceSSH && !isServiceGRPC && !isServiceWebSocket
 	if isServiceHTTP {
 		request = service.buildHTTPRequest()
+		if service.OAuth2 != nil {
+			if err = service.OAuth2.setAuthorizationHeader(request, service.ClientConfig.GetHTTPClient(service.Insecure, service.getTimeout())); err != nil {
+				service.addError(result, err)
+				return
+			}
+		}
 	}
+	timeout := service.getTimeout()
 	startTime := time.Now()
 	if isServiceDNS {
-		service.DNS.query(service.URL, result)
+		if err = service.DNS.query(service.URL, result, service.ClientConfig, service.Insecure, timeout); err != nil {
+			service.addError(result, err)
+		}
 		result.Duration = time.Since(startTime)
 	} else if isServiceStartTLS {
-		result.Connected, certificate, err = client.CanPerformStartTLS(strings.TrimPrefix(service.URL, "starttls://"), service.Insecure)
+		tlsConfig := service.ClientConfig.GetTLSConfig(service.Insecure, result.Hostname)
+		result.Connected, tlsConnectionState, err = client.CanPerformStartTLS(strings.TrimPrefix(service.URL, "starttls://"), service.Protocol, tlsConfig, timeout)
 		if err != nil {
-			result.AddError(err.Error())
+			service.addError(result, err)
+			return
+		}
+		result.Duration = time.Since(startTime)
+		result.processTLSConnectionState(tlsConnectionState, tlsConfig)
+	} else if isServiceTLS {
+		tlsConfig := service.ClientConfig.GetTLSConfig(service.Insecure, result.Hostname)
+		result.Connected, tlsConnectionState, err = client.CanPerformTLS(strings.TrimPrefix(service.URL, "tls://"), tlsConfig, timeout)
+		if err != nil {
+			service.addError(result, err)
 			return
 		}
 		result.Duration = time.Since(startTime)
-		result.CertificateExpiration = time.Until(certificate.NotAfter)
+		result.processTLSConnectionState(tlsConnectionState, tlsConfig)
 	} else if isServiceTCP {
-		result.Connected = client.CanCreateTCPConnection(strings.TrimPrefix(service.URL, "tcp://"))
+		// Only exchange data with the service if there's a payload to send or a condition that uses the BodyPlaceholder
+		if len(service.Body) > 0 || service.needsToReadBody() {
+			result.Connected, result.body, err = client.SendTCPPayload(strings.TrimPrefix(service.URL, "tcp://"), []byte(service.Body), timeout)
+		} else {
+			result.Connected, err = client.CanCreateTCPConnection(strings.TrimPrefix(service.URL, "tcp://"), timeout)
+		}
 		result.Duration = time.Since(startTime)
+		if err != nil {
+			service.addError(result, err)
+		}
+	} else if isServiceUDP {
+		result.body, err = client.SendUDPPacket(strings.TrimPrefix(service.URL, "udp://"), []byte(service.Body), timeout)
+		result.Duration = time.Since(startTime)
+		if err != nil {
+			service.addError(result, err)
+			return
+		}
+		result.Connected = true
+	} else if isServiceSSH {
+		var sshResult *client.SSHResult
+		result.Connected, sshResult, err = client.CanPerformSSH(strings.TrimPrefix(service.URL, "ssh://"), service.SSH.getUsername(), service.SSH.getAuthMethods(), service.SSH.getCommand(), timeout)
+		result.Duration = time.Since(startTime)
+		if sshResult != nil {
+			result.SSHServerVersion = sshResult.ServerVersion
+			result.SSHHostKeyFingerprint = sshResult.HostKeyFingerprint
+			result.SSHExitCode = sshResult.ExitCode
+			result.body = sshResult.Output
+		}
+		if err != nil {
+			service.addError(result, err)
+		}
+	} else if isServiceGRPC {
+		var tlsConfig *tls.Config
+		if service.GRPC.isTLSEnabled() {
+			tlsConfig = service.ClientConfig.GetTLSConfig(service.Insecure, result.Hostname)
+		}
+		result.Connected, result.GRPCStatus, tlsConnectionState, err = client.CheckGRPCHealth(strings.TrimPrefix(service.URL, "grpc://"), service.GRPC.getService(), tlsConfig, timeout)
+		result.Duration = time.Since(startTime)
+		if err != nil {
+			service.addError(result, err)
+		}
+		if tlsConnectionState != nil {
+			result.processTLSConnectionState(tlsConnectionState, tlsConfig)
+		}
+	} else if isServiceWebSocket {
+		tlsConfig := service.ClientConfig.GetTLSConfig(service.Insecure, result.Hostname)
+		// Only wait for a message if there's a condition that uses the BodyPlaceholder
+		result.Connected, result.body, tlsConnectionState, err = client.QueryWebSocket(service.URL, service.buildWebSocketHeaders(), []byte(service.Body), service.needsToReadBody(), tlsConfig, timeout)
+		result.Duration = time.Since(startTime)
+		if err != nil {
+			service.addError(result, err)
+		}
+		if tlsConnectionState != nil {
+			result.processTLSConnectionState(tlsConnectionState, tlsConfig)
+		}
 	} else if isServiceICMP {
-		result.Connected, result.Duration = client.Ping(strings.TrimPrefix(service.URL, "icmp://"))
+		address := strings.TrimPrefix(service.URL, "icmp://")
+		statistics, err := client.PingWithStatistics(address, service.ICMP.getCount(), service.ICMP.getInterval(), timeout)
+		if err != nil {
+			result.Duration = time.Since(startTime)
+			service.addError(result, err)
+			return
+		}
+		result.processPingStatistics(statistics)
+		if !result.Connected {
+			result.AddError(fmt.Sprintf("no reply received from %s (timeout=%s)", address, timeout))
+		}
 	} else {
-		response, err = client.GetHTTPClient(service.Insecure).Do(request)
+		httpClient := client.WithRedirectPolicy(service.ClientConfig.GetHTTPClient(service.Insecure, timeout), service.isFollowingRedirects(), service.getMaximumRedirects())
+		response, err = httpClient.Do(request)
+		if err == nil && response.StatusCode == http.StatusUnauthorized && service.OAuth2 != nil {
+			// The token may have been revoked before it expired, so a new token is obtained and the request is retried
+			_ = response.Body.Close()
+			service.OAuth2.invalidateToken()
+			request = service.buildHTTPRequest()
+			if err = service.OAuth2.setAuthorizationHeader(request, service.ClientConfig.GetHTTPClient(service.Insecure, timeout)); err == nil {
+				startTime = time.Now()
+				response, err = httpClient.Do(request)
+			}
+		}
 		result.Duration = time.Since(startTime)
 		if err != nil {
-			result.AddError(err.Error())
+			service.addError(result, err)
 			return
 		}
 		defer response.Body.Close()
-		if response.TLS != nil && len(response.TLS.PeerCertificates) > 0 {
-			certificate = response.TLS.PeerCertificates[0]
-			result.CertificateExpiration = time.Until(certificate.NotAfter)
+		result.FinalURL = response.Request.URL.String()
+		// Every request that was made because of a redirect keeps a reference to the response of the redirect
+		for redirectedRequest := response.Request; redirectedRequest.Response != nil; redirectedRequest = redirectedRequest.Response.Request {
+			result.RedirectCount++
+		}
+		if response.TLS != nil {
+			result.processTLSConnectionState(response.TLS, service.ClientConfig.GetTLSConfig(service.Insecure, response.Request.URL.Hostname()))
 		}
 		result.HTTPStatus = response.StatusCode
+		result.httpHeaders = response.Header
 		result.Connected = response.StatusCode > 0
 		// Only read the body if there's a condition that uses the BodyPlaceholder
 		if service.needsToReadBody() {
 			result.body, err = ioutil.ReadAll(response.Body)
 			if err != nil {
-				result.AddError(err.Error())
+				service.addError(result, err)
 			}
 		}
 	}
@@ -250,8 +630,23 @@ func (service *Service) buildHTTPRequest() *http.Request {
 	return request
 }
 
+// buildWebSocketHeaders returns the headers to send during the opening handshake of a WebSocket service.
+// The Host header is excluded, because it is always derived from the URL of the service.
+func (service *Service) buildWebSocketHeaders() http.Header {
+	headers := make(http.Header, len(service.Headers))
+	for k, v := range service.Headers {
+		if k != HostHeader {
+			headers.Set(k, v)
+		}
+	}
+	return headers
+}
+
 // needsToReadBody checks if there's any conditions that requires the response body to be read
 func (service *Service) needsToReadBody() bool {
+	if service.alwaysReadBody {
+		return true
+	}
 	for _, condition := range service.Conditions {
 		if condition.hasBodyPlaceholder() {
 			return true
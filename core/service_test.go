@@ -2,11 +2,18 @@ package core
 
 import (
 	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/TwinProduction/gatus/alerting/alert"
+	"github.com/TwinProduction/gatus/client"
+	"github.com/TwinProduction/gatus/core/ui"
+	"golang.org/x/net/websocket"
 )
 
 func TestService_ValidateAndSetDefaults(t *testing.T) {
@@ -39,6 +46,22 @@ func TestService_ValidateAndSetDefaults(t *testing.T) {
 	if service.Alerts[0].FailureThreshold != 3 {
 		t.Error("Service alert should've defaulted to a failure threshold of 3")
 	}
+	if service.UIConfig == nil || len(service.UIConfig.Badge.ResponseTime.Thresholds) != len(ui.DefaultResponseTimeBadgeThresholds) {
+		t.Error("Service UI config should've defaulted to the default response time badge thresholds")
+	}
+}
+
+func TestService_ValidateAndSetDefaultsWithInvalidResponseTimeBadgeThresholds(t *testing.T) {
+	condition := Condition("[STATUS] == 200")
+	service := Service{
+		Name:       "website-health",
+		URL:        "https://twinnation.org/health",
+		Conditions: []*Condition{&condition},
+		UIConfig:   &ui.Config{Badge: &ui.Badge{ResponseTime: &ui.ResponseTime{Thresholds: []int{500, 400, 300, 200, 100}}}},
+	}
+	if err := service.ValidateAndSetDefaults(); err != ui.ErrInvalidResponseTimeBadgeThresholds {
+		t.Errorf("expected error %v, got %v", ui.ErrInvalidResponseTimeBadgeThresholds, err)
+	}
 }
 
 func TestService_ValidateAndSetDefaultsWithNoName(t *testing.T) {
@@ -316,3 +339,392 @@ func TestService_NeedsToReadBody(t *testing.T) {
 		t.Error("expected true, got false")
 	}
 }
+
+func TestService_getTimeout(t *testing.T) {
+	scenarios := []struct {
+		Service         Service
+		ExpectedTimeout time.Duration
+	}{
+		{Service: Service{URL: "https://example.org"}, ExpectedTimeout: client.GetDefaultHTTPTimeout()},
+		{Service: Service{URL: "tcp://127.0.0.1:22"}, ExpectedTimeout: client.DefaultTCPTimeout},
+		{Service: Service{URL: "starttls://smtp.gmail.com:587"}, ExpectedTimeout: client.DefaultTCPTimeout},
+		{Service: Service{URL: "icmp://127.0.0.1"}, ExpectedTimeout: client.DefaultICMPTimeout},
+		{Service: Service{URL: "icmp://127.0.0.1", ICMP: &ICMP{Count: 3, Interval: 2 * time.Second}}, ExpectedTimeout: client.DefaultICMPTimeout + 4*time.Second},
+		{Service: Service{URL: "8.8.8.8", DNS: &DNS{}}, ExpectedTimeout: DefaultDNSTimeout},
+		{Service: Service{URL: "https://example.org", Timeout: 3 * time.Second}, ExpectedTimeout: 3 * time.Second},
+		{Service: Service{URL: "tcp://127.0.0.1:22", Timeout: 3 * time.Second}, ExpectedTimeout: 3 * time.Second},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.Service.URL, func(t *testing.T) {
+			if timeout := scenario.Service.getTimeout(); timeout != scenario.ExpectedTimeout {
+				t.Errorf("expected timeout to be %s, got %s", scenario.ExpectedTimeout, timeout)
+			}
+		})
+	}
+}
+
+func TestService_ValidateAndSetDefaultsWithInvalidTimeout(t *testing.T) {
+	condition := Condition("[STATUS] == 200")
+	service := Service{
+		Name:       "invalid-timeout",
+		URL:        "https://twinnation.org/health",
+		Timeout:    -time.Second,
+		Conditions: []*Condition{&condition},
+	}
+	if err := service.ValidateAndSetDefaults(); err != ErrServiceWithInvalidTimeout {
+		t.Errorf("expected error %v, got %v", ErrServiceWithInvalidTimeout, err)
+	}
+}
+
+func TestService_ValidateAndSetDefaultsWithInvalidStartTLSProtocol(t *testing.T) {
+	condition := Condition("[CONNECTED] == true")
+	service := Service{
+		Name:       "invalid-protocol",
+		URL:        "starttls://example.org:143",
+		Protocol:   "gopher",
+		Conditions: []*Condition{&condition},
+	}
+	if err := service.ValidateAndSetDefaults(); err != client.ErrInvalidStartTLSProtocol {
+		t.Errorf("expected error %v, got %v", client.ErrInvalidStartTLSProtocol, err)
+	}
+}
+
+func TestService_EvaluateHealthWithTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	condition := Condition("[STATUS] == 200")
+	service := Service{
+		Name:       "slow-service",
+		URL:        server.URL,
+		Timeout:    50 * time.Millisecond,
+		Conditions: []*Condition{&condition},
+	}
+	result := service.EvaluateHealth()
+	if result.Success {
+		t.Error("expected the evaluation to fail, because the service took longer than its timeout to respond")
+	}
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "(timeout=50ms)") {
+		t.Errorf("expected the error to contain the timeout, got %v", result.Errors)
+	}
+}
+
+func TestService_EvaluateHealthWithHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.Header().Set("Cache-Control", "no-store")
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	contentTypeCondition := Condition("[HEADER].content-type == application/json")
+	cacheControlCondition := Condition("[HEADER].Cache-Control == any(no-cache, no-store)")
+	service := Service{
+		Name:       "headers",
+		URL:        server.URL,
+		Conditions: []*Condition{&contentTypeCondition, &cacheControlCondition},
+	}
+	result := service.EvaluateHealth()
+	if !result.Success {
+		t.Errorf("expected the evaluation to succeed, got %v", result.ConditionResults)
+	}
+	if result.httpHeaders != nil {
+		t.Error("expected the headers to have been discarded after the evaluation")
+	}
+}
+
+func TestService_EvaluateHealthWithRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/":
+			http.Redirect(writer, request, "/login", http.StatusFound)
+		case "/login":
+			http.Redirect(writer, request, "/login/", http.StatusMovedPermanently)
+		default:
+			writer.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+	statusCondition := Condition("[STATUS] == 200")
+	finalURLCondition := Condition("[FINAL_URL] == " + server.URL + "/login/")
+	redirectCountCondition := Condition("[REDIRECT_COUNT] == 2")
+	service := Service{
+		Name:       "redirects",
+		URL:        server.URL,
+		Conditions: []*Condition{&statusCondition, &finalURLCondition, &redirectCountCondition},
+	}
+	if err := service.ValidateAndSetDefaults(); err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	result := service.EvaluateHealth()
+	if !result.Success {
+		t.Errorf("expected the evaluation to succeed, got %v", result.Errors)
+	}
+	// With a lower maximum number of redirects, the evaluation must fail
+	service.MaximumRedirects = 1
+	result = service.EvaluateHealth()
+	if result.Success || len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "stopped after 1 redirects") {
+		t.Errorf("expected the evaluation to fail because of the number of redirects, got %v", result.Errors)
+	}
+	// Without following redirects, the redirect itself must be returned
+	followRedirects := false
+	service.FollowRedirects = &followRedirects
+	redirectStatusCondition := Condition("[STATUS] == 302")
+	locationCondition := Condition("[HEADER].Location == /login")
+	finalURLCondition = Condition("[FINAL_URL] == " + server.URL)
+	redirectCountCondition = Condition("[REDIRECT_COUNT] == 0")
+	service.Conditions = []*Condition{&redirectStatusCondition, &locationCondition, &finalURLCondition, &redirectCountCondition}
+	result = service.EvaluateHealth()
+	if !result.Success {
+		t.Errorf("expected the evaluation to succeed, got %v", result.Errors)
+	}
+}
+
+func TestService_ValidateAndSetDefaultsWithInvalidMaximumRedirects(t *testing.T) {
+	condition := Condition("[STATUS] == 200")
+	service := Service{
+		Name:             "invalid-maximum-redirects",
+		URL:              "https://twinnation.org/health",
+		MaximumRedirects: -1,
+		Conditions:       []*Condition{&condition},
+	}
+	if err := service.ValidateAndSetDefaults(); err != ErrServiceWithInvalidMaximumRedirects {
+		t.Errorf("expected error %v, got %v", ErrServiceWithInvalidMaximumRedirects, err)
+	}
+}
+
+func TestService_EvaluateHealthForTCPWithConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	_ = listener.Close()
+	condition := Condition("[CONNECTED] == true")
+	service := Service{
+		Name:       "closed-port",
+		URL:        "tcp://" + address,
+		Timeout:    time.Second,
+		Conditions: []*Condition{&condition},
+	}
+	result := service.EvaluateHealth()
+	if result.Success {
+		t.Error("expected the evaluation to fail, because nothing is listening on the port")
+	}
+	if len(result.Errors) != 1 || strings.Contains(result.Errors[0], "timeout=") {
+		t.Errorf("expected a single error that isn't a timeout, got %v", result.Errors)
+	}
+}
+
+func TestService_EvaluateHealthWithRetry(t *testing.T) {
+	var numberOfRequests int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if atomic.AddInt32(&numberOfRequests, 1) < 3 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	condition := Condition("[STATUS] == 200")
+	service := Service{
+		Name:       "flaky-service",
+		URL:        server.URL,
+		Retry:      &Retry{Attempts: 3, Backoff: time.Millisecond, BackoffMultiplier: 1},
+		Conditions: []*Condition{&condition},
+	}
+	result := service.EvaluateHealth()
+	if !result.Success {
+		t.Error("expected the evaluation to succeed, because the last attempt was successful")
+	}
+	if n := atomic.LoadInt32(&numberOfRequests); n != 3 {
+		t.Errorf("expected 3 requests to have been sent, got %d", n)
+	}
+	if len(result.Attempts) != 2 {
+		t.Fatalf("expected 2 intermediate attempts to have been recorded, got %d", len(result.Attempts))
+	}
+	if result.Attempts[0].ConditionResults[0].Success {
+		t.Error("expected the condition of the first attempt to have failed")
+	}
+	// The retries are exhausted before the service recovers
+	atomic.StoreInt32(&numberOfRequests, 0)
+	service.Retry.Attempts = 2
+	result = service.EvaluateHealth()
+	if result.Success {
+		t.Error("expected the evaluation to fail, because every attempt failed")
+	}
+	if n := atomic.LoadInt32(&numberOfRequests); n != 2 || len(result.Attempts) != 1 {
+		t.Errorf("expected 2 requests and 1 intermediate attempt, got %d requests and %d intermediate attempts", n, len(result.Attempts))
+	}
+}
+
+func TestService_EvaluateHealthWithoutRetry(t *testing.T) {
+	var numberOfRequests int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		atomic.AddInt32(&numberOfRequests, 1)
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	condition := Condition("[STATUS] == 200")
+	service := Service{
+		Name:       "failing-service",
+		URL:        server.URL,
+		Conditions: []*Condition{&condition},
+	}
+	result := service.EvaluateHealth()
+	if n := atomic.LoadInt32(&numberOfRequests); result.Success || n != 1 || len(result.Attempts) != 0 {
+		t.Errorf("expected a single failed attempt, got success=%v, requests=%d, attempts=%d", result.Success, n, len(result.Attempts))
+	}
+}
+
+func TestService_EvaluateHealthForTCPWithBody(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			buffer := make([]byte, 1024)
+			if n, err := conn.Read(buffer); err == nil && string(buffer[:n]) == "PING\r\n" {
+				_, _ = conn.Write([]byte("+PONG\r\n"))
+			}
+			_ = conn.Close()
+		}
+	}()
+	connectedCondition := Condition("[CONNECTED] == true")
+	bodyCondition := Condition("[BODY] == pat(+PONG*)")
+	service := Service{
+		Name:       "redis",
+		URL:        "tcp://" + listener.Addr().String(),
+		Body:       "PING\r\n",
+		Conditions: []*Condition{&connectedCondition, &bodyCondition},
+	}
+	result := service.EvaluateHealth()
+	if !result.Success {
+		t.Errorf("expected the result to be successful, got errors=%v and conditionResults=%v", result.Errors, result.ConditionResults)
+	}
+	service.Body = "QUIT\r\n"
+	result = service.EvaluateHealth()
+	if result.Success {
+		t.Error("expected the result to be unsuccessful, because the service did not reply with +PONG")
+	}
+	if !result.Connected {
+		t.Error("expected the connection to have been established")
+	}
+}
+
+func TestService_EvaluateHealthForWebSocket(t *testing.T) {
+	server := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		var message string
+		if websocket.Message.Receive(ws, &message) == nil && message == "status" {
+			_ = websocket.Message.Send(ws, `{"status":"UP","connections":3}`)
+		}
+	}))
+	defer server.Close()
+	connectedCondition := Condition("[CONNECTED] == true")
+	statusCondition := Condition("[BODY].status == UP")
+	connectionsCondition := Condition("[BODY].connections > 0")
+	service := Service{
+		Name:       "websocket",
+		URL:        strings.Replace(server.URL, "http", "ws", 1),
+		Body:       "status",
+		Conditions: []*Condition{&connectedCondition, &statusCondition, &connectionsCondition},
+	}
+	if err := service.ValidateAndSetDefaults(); err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	result := service.EvaluateHealth()
+	if !result.Success {
+		t.Errorf("expected the result to be successful, got errors=%v and conditionResults=%v", result.Errors, result.ConditionResults)
+	}
+}
+
+func TestService_EvaluateHealthForUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	go func() {
+		buffer := make([]byte, 1024)
+		for {
+			n, address, err := conn.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+			_, _ = conn.WriteTo([]byte(strings.ToUpper(string(buffer[:n]))), address)
+		}
+	}()
+	connectedCondition := Condition("[CONNECTED] == true")
+	bodyCondition := Condition("[BODY] == HELLO")
+	service := Service{
+		Name:       "udp",
+		URL:        "udp://" + conn.LocalAddr().String(),
+		Body:       "hello",
+		Conditions: []*Condition{&connectedCondition, &bodyCondition},
+	}
+	result := service.EvaluateHealth()
+	if !result.Success {
+		t.Errorf("expected the result to be successful, got errors=%v and conditionResults=%v", result.Errors, result.ConditionResults)
+	}
+	// Nothing should be listening on the port once the connection is closed, so no reply should be received
+	_ = conn.Close()
+	service.Timeout = 100 * time.Millisecond
+	result = service.EvaluateHealth()
+	if result.Success || result.Connected {
+		t.Error("expected the result to be unsuccessful, because no reply was received")
+	}
+	if len(result.Errors) == 0 {
+		t.Error("expected an error, because no reply was received")
+	}
+}
+
+func TestService_EvaluateHealthForTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	conditions := []*Condition{}
+	for _, condition := range []Condition{
+		"[CONNECTED] == true",
+		"[CERTIFICATE_EXPIRATION] > 1h",
+		"[CERTIFICATE_ISSUER] == pat(*O=Acme Co*)",
+		"[CERTIFICATE_SUBJECT] == pat(*O=Acme Co*)",
+		"[CERTIFICATE_SANS] == pat(*127.0.0.1*)",
+		"[TLS_VERSION] == TLS 1.3",
+		"[CERTIFICATE_CHAIN_VALID] == false",
+	} {
+		condition := condition
+		conditions = append(conditions, &condition)
+	}
+	service := Service{
+		Name:       "tls",
+		URL:        "tls://" + server.Listener.Addr().String(),
+		Insecure:   true,
+		Conditions: conditions,
+	}
+	result := service.EvaluateHealth()
+	for _, conditionResult := range result.ConditionResults {
+		if !conditionResult.Success {
+			t.Errorf("expected condition '%s' to succeed", conditionResult.Condition)
+		}
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("expected no errors, got %v", result.Errors)
+	}
+	// The HTTPS placeholders must also be populated
+	service.URL = server.URL
+	service.Conditions = conditions[1:]
+	result = service.EvaluateHealth()
+	for _, conditionResult := range result.ConditionResults {
+		if !conditionResult.Success {
+			t.Errorf("expected condition '%s' to succeed", conditionResult.Condition)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"errors"
+	"io/ioutil"
+
+	"golang.org/x/crypto/ssh"
+)
+
+var (
+	// ErrSSHWithoutUsername is the error with which gatus will panic if an SSH service is configured with a password,
+	// a private key or a command, but without a username
+	ErrSSHWithoutUsername = errors.New("you must specify a username for SSH when using a password, a private key or a command")
+
+	// ErrSSHCommandWithoutAuthentication is the error with which gatus will panic if an SSH service is configured
+	// with a command, but with neither a password nor a private key
+	ErrSSHCommandWithoutAuthentication = errors.New("you must specify a password or a private key for SSH when using a command")
+
+	// ErrSSHWithRSAPrivateKey is the error with which gatus will panic if an SSH service is configured with an RSA
+	// private key. Such keys can only sign using ssh-rsa, which relies on SHA-1 and is rejected by OpenSSH 8.8+.
+	ErrSSHWithRSAPrivateKey = errors.New("RSA private keys are not supported for SSH, use an Ed25519 or ECDSA key instead")
+)
+
+// SSH is the configuration for a Service of type SSH
+type SSH struct {
+	// Username is the name of the user to authenticate as
+	Username string `yaml:"username,omitempty"`
+
+	// Password is the password used to authenticate
+	Password string `yaml:"password,omitempty"`
+
+	// PrivateKeyFile is the path to a file containing the PEM encoded private key used to authenticate
+	PrivateKeyFile string `yaml:"private-key-file,omitempty"`
+
+	// Command is the command to execute once authenticated
+	Command string `yaml:"command,omitempty"`
+
+	signer ssh.Signer
+}
+
+func (s *SSH) validateAndSetDefault() error {
+	hasAuthentication := len(s.Password) > 0 || len(s.PrivateKeyFile) > 0
+	if len(s.Username) == 0 && (hasAuthentication || len(s.Command) > 0) {
+		return ErrSSHWithoutUsername
+	}
+	if len(s.Command) > 0 && !hasAuthentication {
+		return ErrSSHCommandWithoutAuthentication
+	}
+	if len(s.PrivateKeyFile) > 0 {
+		privateKey, err := ioutil.ReadFile(s.PrivateKeyFile)
+		if err != nil {
+			return err
+		}
+		if s.signer, err = ssh.ParsePrivateKey(privateKey); err != nil {
+			return err
+		}
+		if s.signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+			s.signer = nil
+			return ErrSSHWithRSAPrivateKey
+		}
+	}
+	return nil
+}
+
+// getUsername returns the name of the user to authenticate as. The SSH configuration may be nil.
+func (s *SSH) getUsername() string {
+	if s == nil {
+		return ""
+	}
+	return s.Username
+}
+
+// getCommand returns the command to execute once authenticated. The SSH configuration may be nil.
+func (s *SSH) getCommand() string {
+	if s == nil {
+		return ""
+	}
+	return s.Command
+}
+
+// getAuthMethods returns the methods to authenticate with. The SSH configuration may be nil, in which case no
+// authentication is performed.
+func (s *SSH) getAuthMethods() []ssh.AuthMethod {
+	if s == nil {
+		return nil
+	}
+	var authMethods []ssh.AuthMethod
+	if s.signer != nil {
+		authMethods = append(authMethods, ssh.PublicKeys(s.signer))
+	}
+	if len(s.Password) > 0 {
+		authMethods = append(authMethods, ssh.Password(s.Password))
+	}
+	return authMethods
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestSSH_validateAndSetDefault(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		ssh           SSH
+		expectedError error
+	}{
+		{
+			name: "empty",
+			ssh:  SSH{},
+		},
+		{
+			name: "password-and-command",
+			ssh:  SSH{Username: "gatus", Password: "password", Command: "uptime"},
+		},
+		{
+			name:          "password-without-username",
+			ssh:           SSH{Password: "password"},
+			expectedError: ErrSSHWithoutUsername,
+		},
+		{
+			name:          "command-without-username",
+			ssh:           SSH{Command: "uptime"},
+			expectedError: ErrSSHWithoutUsername,
+		},
+		{
+			name:          "command-without-authentication",
+			ssh:           SSH{Username: "gatus", Command: "uptime"},
+			expectedError: ErrSSHCommandWithoutAuthentication,
+		},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			if err := scenario.ssh.validateAndSetDefault(); err != scenario.expectedError {
+				t.Errorf("expected error %v, got %v", scenario.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestSSH_validateAndSetDefaultWithPrivateKeyFile(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKeyBytes, err := x509.MarshalECPrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKeyFile := filepath.Join(t.TempDir(), "id_ecdsa")
+	if err = ioutil.WriteFile(privateKeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: privateKeyBytes}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	ssh := &SSH{Username: "gatus", PrivateKeyFile: privateKeyFile}
+	if err = ssh.validateAndSetDefault(); err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	if len(ssh.getAuthMethods()) != 1 {
+		t.Errorf("expected 1 authentication method, got %d", len(ssh.getAuthMethods()))
+	}
+	invalidPrivateKeyFile := filepath.Join(t.TempDir(), "invalid")
+	if err = ioutil.WriteFile(invalidPrivateKeyFile, []byte("not a private key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	ssh = &SSH{Username: "gatus", PrivateKeyFile: invalidPrivateKeyFile}
+	if err = ssh.validateAndSetDefault(); err == nil {
+		t.Error("expected an error, because the private key file is invalid")
+	}
+}
+
+func TestSSH_validateAndSetDefaultWithRSAPrivateKeyFile(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKeyFile := filepath.Join(t.TempDir(), "id_rsa")
+	if err = ioutil.WriteFile(privateKeyFile, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	ssh := &SSH{Username: "gatus", PrivateKeyFile: privateKeyFile}
+	if err = ssh.validateAndSetDefault(); err != ErrSSHWithRSAPrivateKey {
+		t.Errorf("expected error %v, got %v", ErrSSHWithRSAPrivateKey, err)
+	}
+}
+
+func TestSSH_getAuthMethodsWhenNil(t *testing.T) {
+	var ssh *SSH
+	if len(ssh.getAuthMethods()) != 0 || len(ssh.getUsername()) != 0 || len(ssh.getCommand()) != 0 {
+		t.Error("expected a nil SSH configuration to result in no authentication")
+	}
+}
@@ -0,0 +1,164 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const (
+	// VariablePlaceholder is a placeholder for the value of a variable extracted by a previous step. It must be
+	// followed by the name of the variable, and can be used in the url, the headers and the body of a step.
+	//
+	// Usage: Authorization: "Bearer [VARIABLE].token"
+	VariablePlaceholder = "[VARIABLE]"
+)
+
+var (
+	// ErrServiceWithStepsAndURL is the error with which Gatus will panic if a service is configured with steps, but
+	// also with a url or conditions, which must be specified in each step instead
+	ErrServiceWithStepsAndURL = errors.New("a service with steps cannot have a url or conditions, they must be specified in each step")
+
+	// ErrStepWithNoName is the error with which Gatus will panic if a step is configured with no name
+	ErrStepWithNoName = errors.New("you must specify a name for each step")
+
+	// ErrStepWithDuplicateName is the error with which Gatus will panic if two steps of a service have the same name
+	ErrStepWithDuplicateName = errors.New("the name of each step of a service must be unique")
+
+	// ErrStepWithNoURL is the error with which Gatus will panic if a step is configured with no url
+	ErrStepWithNoURL = errors.New("you must specify an url for each step")
+
+	// ErrStepWithNoCondition is the error with which Gatus will panic if a step is configured with no conditions
+	ErrStepWithNoCondition = errors.New("you must specify at least one condition per step")
+
+	// ErrStepWithUndefinedVariable is the error with which Gatus will panic if a step uses a variable that isn't
+	// extracted by any of the steps before it
+	ErrStepWithUndefinedVariable = errors.New("a step can only use the variables extracted by the steps before it")
+
+	// variablePattern is the pattern of the VariablePlaceholder followed by the name of a variable
+	variablePattern = regexp.MustCompile(`(?i)\[VARIABLE\]\.([A-Za-z0-9_-]+)`)
+)
+
+// Step is a request made as part of a Service with multiple steps, such as logging in before calling an API
+// that requires authentication
+type Step struct {
+	// Name of the step
+	Name string `yaml:"name"`
+
+	// URL to send the request to
+	URL string `yaml:"url"`
+
+	// Method of the request
+	Method string `yaml:"method,omitempty"`
+
+	// Body of the request
+	Body string `yaml:"body,omitempty"`
+
+	// GraphQL is whether to wrap the body in a query param ({"query":"$body"})
+	GraphQL bool `yaml:"graphql,omitempty"`
+
+	// Headers of the request, which are added to the headers of the service
+	Headers map[string]string `yaml:"headers,omitempty"`
+
+	// Conditions used to determine whether the step was successful
+	Conditions []*Condition `yaml:"conditions"`
+
+	// Variables to extract from the response, by name. The value of each variable is the placeholder to resolve,
+	// such as [BODY].token or [HEADER].Location, and can be used by the next steps through the VariablePlaceholder.
+	Variables map[string]string `yaml:"variables,omitempty"`
+}
+
+// validateAndSetDefault validates the step and sets the default values of its fields.
+// definedVariables are the names of the variables extracted by the steps before this one.
+func (step *Step) validateAndSetDefault(definedVariables map[string]bool) error {
+	if len(step.Name) == 0 {
+		return ErrStepWithNoName
+	}
+	if len(step.URL) == 0 {
+		return ErrStepWithNoURL
+	}
+	if len(step.Conditions) == 0 {
+		return ErrStepWithNoCondition
+	}
+	if len(step.Method) == 0 {
+		step.Method = http.MethodGet
+	}
+	templates := []string{step.URL, step.Body}
+	for _, value := range step.Headers {
+		templates = append(templates, value)
+	}
+	for _, template := range templates {
+		for _, match := range variablePattern.FindAllStringSubmatch(template, -1) {
+			if !definedVariables[match[1]] {
+				return fmt.Errorf("%w: %s", ErrStepWithUndefinedVariable, match[1])
+			}
+		}
+	}
+	// Make sure that the request can be created
+	_, err := http.NewRequest(step.Method, step.URL, bytes.NewBuffer([]byte(step.Body)))
+	return err
+}
+
+// toService returns the service that sends the request of the step, which inherits the configuration of the
+// service the step is part of, with the variables replaced by their value
+func (step *Step) toService(service *Service, variables map[string]string) *Service {
+	headers := make(map[string]string, len(service.Headers)+len(step.Headers))
+	for name, value := range service.Headers {
+		headers[name] = value
+	}
+	for name, value := range step.Headers {
+		headers[name] = injectVariables(value, variables)
+	}
+	if _, contentTypeHeaderExists := headers[ContentTypeHeader]; !contentTypeHeaderExists && step.GraphQL {
+		headers[ContentTypeHeader] = "application/json"
+	}
+	return &Service{
+		Name:             service.Name,
+		URL:              injectVariables(step.URL, variables),
+		Method:           step.Method,
+		Body:             injectVariables(step.Body, variables),
+		GraphQL:          step.GraphQL,
+		Headers:          headers,
+		OAuth2:           service.OAuth2,
+		Timeout:          service.Timeout,
+		Conditions:       step.Conditions,
+		Insecure:         service.Insecure,
+		ClientConfig:     service.ClientConfig,
+		FollowRedirects:  service.FollowRedirects,
+		MaximumRedirects: service.MaximumRedirects,
+		alwaysReadBody:   step.needsToReadBody(),
+	}
+}
+
+// extractVariables resolves the variables of the step from its result, and adds them to the given variables
+func (step *Step) extractVariables(result *Result, variables map[string]string) error {
+	for name, placeholder := range step.Variables {
+		_, resolvedParameters := sanitizeAndResolve([]string{placeholder}, result)
+		value := resolvedParameters[0]
+		if len(value) == 0 || strings.HasSuffix(value, InvalidConditionElementSuffix) {
+			return fmt.Errorf("failed to extract variable %s from %s", name, placeholder)
+		}
+		variables[name] = value
+	}
+	return nil
+}
+
+// needsToReadBody checks whether any of the variables of the step are extracted from the body of the response
+func (step *Step) needsToReadBody() bool {
+	for _, placeholder := range step.Variables {
+		if strings.Contains(placeholder, BodyPlaceholder) {
+			return true
+		}
+	}
+	return false
+}
+
+// injectVariables replaces every VariablePlaceholder followed by the name of a variable by the value of the variable
+func injectVariables(template string, variables map[string]string) string {
+	return variablePattern.ReplaceAllStringFunc(template, func(match string) string {
+		return variables[variablePattern.FindStringSubmatch(match)[1]]
+	})
+}
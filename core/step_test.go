@@ -0,0 +1,197 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestService_ValidateAndSetDefaultsWithSteps(t *testing.T) {
+	condition := Condition("[STATUS] == 200")
+	scenarios := []struct {
+		name          string
+		service       Service
+		expectedError error
+	}{
+		{
+			name: "valid",
+			service: Service{Name: "steps", Steps: []*Step{
+				{Name: "login", URL: "https://example.org/login", Conditions: []*Condition{&condition}, Variables: map[string]string{"token": "[BODY].token"}},
+				{Name: "profile", URL: "https://example.org/users/[VARIABLE].token", Headers: map[string]string{"Authorization": "Bearer [VARIABLE].token"}, Conditions: []*Condition{&condition}},
+			}},
+		},
+		{
+			name: "steps-and-url",
+			service: Service{Name: "steps", URL: "https://example.org", Steps: []*Step{
+				{Name: "login", URL: "https://example.org/login", Conditions: []*Condition{&condition}},
+			}},
+			expectedError: ErrServiceWithStepsAndURL,
+		},
+		{
+			name: "step-with-no-name",
+			service: Service{Name: "steps", Steps: []*Step{
+				{URL: "https://example.org/login", Conditions: []*Condition{&condition}},
+			}},
+			expectedError: ErrStepWithNoName,
+		},
+		{
+			name: "step-with-no-url",
+			service: Service{Name: "steps", Steps: []*Step{
+				{Name: "login", Conditions: []*Condition{&condition}},
+			}},
+			expectedError: ErrStepWithNoURL,
+		},
+		{
+			name: "step-with-no-condition",
+			service: Service{Name: "steps", Steps: []*Step{
+				{Name: "login", URL: "https://example.org/login"},
+			}},
+			expectedError: ErrStepWithNoCondition,
+		},
+		{
+			name: "steps-with-duplicate-name",
+			service: Service{Name: "steps", Steps: []*Step{
+				{Name: "login", URL: "https://example.org/login", Conditions: []*Condition{&condition}},
+				{Name: "login", URL: "https://example.org/login", Conditions: []*Condition{&condition}},
+			}},
+			expectedError: ErrStepWithDuplicateName,
+		},
+		{
+			name: "step-with-variable-defined-by-a-later-step",
+			service: Service{Name: "steps", Steps: []*Step{
+				{Name: "profile", URL: "https://example.org/me", Body: `{"token":"[VARIABLE].token"}`, Conditions: []*Condition{&condition}},
+				{Name: "login", URL: "https://example.org/login", Conditions: []*Condition{&condition}, Variables: map[string]string{"token": "[BODY].token"}},
+			}},
+			expectedError: ErrStepWithUndefinedVariable,
+		},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			if err := scenario.service.ValidateAndSetDefaults(); !errors.Is(err, scenario.expectedError) {
+				t.Errorf("expected error %v, got %v", scenario.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestInjectVariables(t *testing.T) {
+	variables := map[string]string{"token": "abc", "user-id": "42"}
+	scenarios := []struct {
+		template string
+		expected string
+	}{
+		{template: "https://example.org/users/[VARIABLE].user-id", expected: "https://example.org/users/42"},
+		{template: "Bearer [VARIABLE].token", expected: "Bearer abc"},
+		{template: `{"token":"[variable].token","id":[VARIABLE].user-id}`, expected: `{"token":"abc","id":42}`},
+		{template: "[VARIABLE].token.[VARIABLE].user-id", expected: "abc.42"},
+		{template: "no variables", expected: "no variables"},
+	}
+	for _, scenario := range scenarios {
+		if injected := injectVariables(scenario.template, variables); injected != scenario.expected {
+			t.Errorf("expected %s to be injected into %s, got %s", scenario.template, scenario.expected, injected)
+		}
+	}
+}
+
+func TestService_EvaluateHealthWithSteps(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/login":
+			if request.Method != http.MethodPost || request.Header.Get("User-Agent") != GatusUserAgent {
+				writer.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			writer.Header().Set("X-Session", "session-1")
+			_, _ = writer.Write([]byte(`{"token":"secret","user":{"id":42}}`))
+		case "/users/42":
+			if request.Header.Get("Authorization") != "Bearer secret" || request.Header.Get("Cookie") != "session=session-1" {
+				writer.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = writer.Write([]byte(`{"name":"john"}`))
+		default:
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	statusCondition := Condition("[STATUS] == 200")
+	tokenCondition := Condition("has([BODY].token) == true")
+	nameCondition := Condition("[BODY].name == john")
+	service := Service{
+		Name: "login-flow",
+		Steps: []*Step{
+			{
+				Name:       "login",
+				URL:        server.URL + "/login",
+				Method:     http.MethodPost,
+				Conditions: []*Condition{&statusCondition, &tokenCondition},
+				Variables:  map[string]string{"token": "[BODY].token", "user-id": "[BODY].user.id", "session": "[HEADER].X-Session"},
+			},
+			{
+				Name:       "profile",
+				URL:        server.URL + "/users/[VARIABLE].user-id",
+				Headers:    map[string]string{"Authorization": "Bearer [VARIABLE].token", "Cookie": "session=[VARIABLE].session"},
+				Conditions: []*Condition{&statusCondition, &nameCondition},
+			},
+		},
+	}
+	if err := service.ValidateAndSetDefaults(); err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	result := service.EvaluateHealth()
+	if !result.Success {
+		t.Errorf("expected the evaluation to succeed, got errors %v and condition results %v", result.Errors, result.ConditionResults)
+	}
+	if len(result.ConditionResults) != 4 {
+		t.Fatalf("expected the condition results of both steps, got %d", len(result.ConditionResults))
+	}
+	if result.ConditionResults[0].Condition != "login: [STATUS] == 200" || result.ConditionResults[3].Condition != "profile: [BODY].name == john" {
+		t.Errorf("expected the condition results to be prefixed by the name of their step, got %s and %s", result.ConditionResults[0].Condition, result.ConditionResults[3].Condition)
+	}
+	if result.HTTPStatus != http.StatusOK || result.Hostname != "127.0.0.1" {
+		t.Errorf("expected the result to be the one of the last step, got status %d and hostname %s", result.HTTPStatus, result.Hostname)
+	}
+	// If a step fails, the next steps must not be evaluated
+	service.Steps[0].URL = server.URL + "/invalid"
+	result = service.EvaluateHealth()
+	if result.Success {
+		t.Error("expected the evaluation to fail, because the first step failed")
+	}
+	if len(result.ConditionResults) != 2 || result.HTTPStatus != http.StatusNotFound {
+		t.Errorf("expected only the condition results of the first step, got %d", len(result.ConditionResults))
+	}
+}
+
+func TestService_EvaluateHealthWithStepsAndMissingVariable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(`{"error":"invalid credentials"}`))
+	}))
+	defer server.Close()
+	statusCondition := Condition("[STATUS] == 200")
+	service := Service{
+		Name: "login-flow",
+		Steps: []*Step{
+			{Name: "login", URL: server.URL, Conditions: []*Condition{&statusCondition}, Variables: map[string]string{"token": "[BODY].token"}},
+			{Name: "profile", URL: server.URL, Headers: map[string]string{"Authorization": "Bearer [VARIABLE].token"}, Conditions: []*Condition{&statusCondition}},
+		},
+	}
+	if err := service.ValidateAndSetDefaults(); err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	result := service.EvaluateHealth()
+	if result.Success {
+		t.Error("expected the evaluation to fail, because the token could not be extracted")
+	}
+	if len(result.ConditionResults) != 1 {
+		t.Errorf("expected the second step not to have been evaluated, got %d condition results", len(result.ConditionResults))
+	}
+	expectedError := "login: failed to extract variable token from [BODY].token"
+	found := false
+	for _, err := range result.Errors {
+		found = found || err == expectedError
+	}
+	if !found {
+		t.Errorf("expected error %s, got %v", expectedError, result.Errors)
+	}
+}
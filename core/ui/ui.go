@@ -0,0 +1,65 @@
+package ui
+
+import "errors"
+
+var (
+	// ErrInvalidResponseTimeBadgeThresholds is the error returned when the response time badge thresholds are invalid
+	ErrInvalidResponseTimeBadgeThresholds = errors.New("ui.badge.response-time.thresholds must have exactly 5 values in ascending order")
+
+	// DefaultResponseTimeBadgeThresholds are the thresholds, in milliseconds, used for the response time badge when none are specified
+	DefaultResponseTimeBadgeThresholds = []int{50, 200, 300, 500, 750}
+)
+
+// Config is the UI configuration of a service
+type Config struct {
+	// Badge is the configuration of the badges of the service
+	Badge *Badge `yaml:"badge,omitempty"`
+}
+
+// Badge is the configuration of the badges of a service
+type Badge struct {
+	// ResponseTime is the configuration of the response time badge
+	ResponseTime *ResponseTime `yaml:"response-time,omitempty"`
+}
+
+// ResponseTime is the configuration of the response time badge
+type ResponseTime struct {
+	// Thresholds are the response times, in milliseconds, at which the color of the badge changes.
+	//
+	// A response time lower than the first threshold is green, while a response time higher than the last
+	// threshold is red.
+	Thresholds []int `yaml:"thresholds,omitempty"`
+}
+
+// GetDefaultConfig returns the default UI configuration
+func GetDefaultConfig() *Config {
+	return &Config{
+		Badge: &Badge{
+			ResponseTime: &ResponseTime{
+				Thresholds: DefaultResponseTimeBadgeThresholds,
+			},
+		},
+	}
+}
+
+// ValidateAndSetDefaults validates the UI configuration and sets the default value of fields that have one
+func (config *Config) ValidateAndSetDefaults() error {
+	if config.Badge == nil {
+		config.Badge = &Badge{}
+	}
+	if config.Badge.ResponseTime == nil {
+		config.Badge.ResponseTime = &ResponseTime{}
+	}
+	if len(config.Badge.ResponseTime.Thresholds) == 0 {
+		config.Badge.ResponseTime.Thresholds = DefaultResponseTimeBadgeThresholds
+	}
+	if len(config.Badge.ResponseTime.Thresholds) != len(DefaultResponseTimeBadgeThresholds) {
+		return ErrInvalidResponseTimeBadgeThresholds
+	}
+	for i := 1; i < len(config.Badge.ResponseTime.Thresholds); i++ {
+		if config.Badge.ResponseTime.Thresholds[i-1] > config.Badge.ResponseTime.Thresholds[i] {
+			return ErrInvalidResponseTimeBadgeThresholds
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package ui
+
+import "testing"
+
+func TestConfig_ValidateAndSetDefaults(t *testing.T) {
+	scenarios := []struct {
+		Name               string
+		Config             *Config
+		ExpectedErr        error
+		ExpectedThresholds []int
+	}{
+		{
+			Name:               "empty",
+			Config:             &Config{},
+			ExpectedErr:        nil,
+			ExpectedThresholds: DefaultResponseTimeBadgeThresholds,
+		},
+		{
+			Name:               "custom-thresholds",
+			Config:             &Config{Badge: &Badge{ResponseTime: &ResponseTime{Thresholds: []int{100, 200, 400, 800, 1600}}}},
+			ExpectedErr:        nil,
+			ExpectedThresholds: []int{100, 200, 400, 800, 1600},
+		},
+		{
+			Name:        "too-few-thresholds",
+			Config:      &Config{Badge: &Badge{ResponseTime: &ResponseTime{Thresholds: []int{100, 200}}}},
+			ExpectedErr: ErrInvalidResponseTimeBadgeThresholds,
+		},
+		{
+			Name:        "thresholds-not-in-ascending-order",
+			Config:      &Config{Badge: &Badge{ResponseTime: &ResponseTime{Thresholds: []int{100, 200, 50, 800, 1600}}}},
+			ExpectedErr: ErrInvalidResponseTimeBadgeThresholds,
+		},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			if err := scenario.Config.ValidateAndSetDefaults(); err != scenario.ExpectedErr {
+				t.Fatalf("expected error %v, got %v", scenario.ExpectedErr, err)
+			}
+			if scenario.ExpectedErr != nil {
+				return
+			}
+			thresholds := scenario.Config.Badge.ResponseTime.Thresholds
+			if len(thresholds) != len(scenario.ExpectedThresholds) {
+				t.Fatalf("expected thresholds %v, got %v", scenario.ExpectedThresholds, thresholds)
+			}
+			for i := range thresholds {
+				if thresholds[i] != scenario.ExpectedThresholds[i] {
+					t.Errorf("expected thresholds %v, got %v", scenario.ExpectedThresholds, thresholds)
+				}
+			}
+		})
+	}
+}
@@ -8,11 +8,21 @@ import (
 const (
 	numberOfHoursInTenDays = 10 * 24
 	sevenDays              = 7 * 24 * time.Hour
+	thirtyDays             = 30 * 24 * time.Hour
+	ninetyDays             = 90 * 24 * time.Hour
+
+	// hourlyStatisticsRetention is how long hourly statistics are kept before being rolled up into daily statistics
+	hourlyStatisticsRetention = sevenDays
+
+	// dailyStatisticsRetention is how long daily statistics are kept
+	dailyStatisticsRetention = ninetyDays
 )
 
 // Uptime is the struct that contains the relevant data for calculating the uptime as well as the uptime itself
 // and some other statistics
 type Uptime struct {
+	LastNinetyDays      float64 `json:"90d"` // Uptime percentage over the past 90 days
+	LastThirtyDays      float64 `json:"30d"` // Uptime percentage over the past 30 days
 	LastSevenDays       float64 `json:"7d"`  // Uptime percentage over the past 7 days
 	LastTwentyFourHours float64 `json:"24h"` // Uptime percentage over the past 24 hours
 	LastHour            float64 `json:"1h"`  // Uptime percentage over the past hour
@@ -28,7 +38,30 @@ type Uptime struct {
 	TotalExecutionsPerHour map[int64]uint64 `json:"-"`
 
 	// HourlyStatistics is a map containing metrics collected (value) for every hourly unix timestamps (key)
+	//
+	// Hourly statistics older than hourlyStatisticsRetention are rolled up into DailyStatistics
 	HourlyStatistics map[int64]*HourlyUptimeStatistics `json:"-"`
+
+	// DailyStatistics is a map containing metrics collected (value) for every daily unix timestamps (key)
+	//
+	// Note that a given hour is either part of HourlyStatistics or of DailyStatistics, never both.
+	DailyStatistics map[int64]*HourlyUptimeStatistics `json:"-"`
+
+	// AverageResponseTime is the average response time over the same windows as the uptime
+	//
+	// We don't expose this through JSON, because it is not part of the uptime.
+	// However, the detailed service page does leverage this by including it to a map that will be
+	// marshalled alongside the Uptime.
+	AverageResponseTime AverageResponseTime `json:"-"`
+}
+
+// AverageResponseTime contains the average response time, in milliseconds, over several windows
+type AverageResponseTime struct {
+	LastNinetyDays      int64 `json:"90d"` // Average response time over the past 90 days
+	LastThirtyDays      int64 `json:"30d"` // Average response time over the past 30 days
+	LastSevenDays       int64 `json:"7d"`  // Average response time over the past 7 days
+	LastTwentyFourHours int64 `json:"24h"` // Average response time over the past 24 hours
+	LastHour            int64 `json:"1h"`  // Average response time over the past hour
 }
 
 // HourlyUptimeStatistics is a struct containing all metrics collected over the course of an hour
@@ -42,6 +75,7 @@ type HourlyUptimeStatistics struct {
 func NewUptime() *Uptime {
 	return &Uptime{
 		HourlyStatistics: make(map[int64]*HourlyUptimeStatistics),
+		DailyStatistics:  make(map[int64]*HourlyUptimeStatistics),
 	}
 }
 
@@ -66,68 +100,112 @@ func (uptime *Uptime) ProcessResult(result *Result) {
 	}
 	hourlyStats.TotalExecutions++
 	hourlyStats.TotalExecutionsResponseTime += uint64(result.Duration.Milliseconds())
-	// Clean up only when we're starting to have too many useless keys
+	// Roll up only when we're starting to have too many hourly keys
 	// Note that this is only triggered when there are more entries than there should be after
-	// 10 days, despite the fact that we are deleting everything that's older than 7 days.
+	// 10 days, despite the fact that we are rolling up everything that's older than 7 days.
 	// This is to prevent re-iterating on every `ProcessResult` as soon as the uptime has been logged for 7 days.
 	if len(uptime.HourlyStatistics) > numberOfHoursInTenDays {
-		sevenDaysAgo := time.Now().Add(-(sevenDays + time.Hour)).Unix()
-		for hourlyUnixTimestamp := range uptime.HourlyStatistics {
-			if sevenDaysAgo > hourlyUnixTimestamp {
-				delete(uptime.HourlyStatistics, hourlyUnixTimestamp)
+		uptime.rollUpHourlyStatistics()
+	}
+	// Unlike the uptime, which doesn't need to be recalculated if it's already 100% and the result is a success (or
+	// if it's already 0% and the result is a failure), the average response time changes with every result, so we
+	// always have to recalculate
+	uptime.recalculate()
+}
+
+// rollUpHourlyStatistics moves the hourly statistics older than hourlyStatisticsRetention into the daily statistics,
+// and deletes the daily statistics older than dailyStatisticsRetention
+func (uptime *Uptime) rollUpHourlyStatistics() {
+	if uptime.DailyStatistics == nil {
+		uptime.DailyStatistics = make(map[int64]*HourlyUptimeStatistics)
+	}
+	now := time.Now()
+	oldestHourlyUnixTimestampAllowed := now.Add(-(hourlyStatisticsRetention + time.Hour)).Unix()
+	for hourlyUnixTimestamp, hourlyStats := range uptime.HourlyStatistics {
+		if oldestHourlyUnixTimestampAllowed > hourlyUnixTimestamp {
+			dailyUnixTimestamp := hourlyUnixTimestamp - (hourlyUnixTimestamp % 86400)
+			dailyStats := uptime.DailyStatistics[dailyUnixTimestamp]
+			if dailyStats == nil {
+				dailyStats = &HourlyUptimeStatistics{}
+				uptime.DailyStatistics[dailyUnixTimestamp] = dailyStats
 			}
+			dailyStats.TotalExecutions += hourlyStats.TotalExecutions
+			dailyStats.SuccessfulExecutions += hourlyStats.SuccessfulExecutions
+			dailyStats.TotalExecutionsResponseTime += hourlyStats.TotalExecutionsResponseTime
+			delete(uptime.HourlyStatistics, hourlyUnixTimestamp)
 		}
 	}
-	if result.Success {
-		// Recalculate uptime if at least one of the 1h, 24h or 7d uptime are not 100%
-		// If they're all 100%, then recalculating the uptime would be useless unless
-		// the result added was a failure (!result.Success)
-		if uptime.LastSevenDays != 1 || uptime.LastTwentyFourHours != 1 || uptime.LastHour != 1 {
-			uptime.recalculate()
-		}
-	} else {
-		// Recalculate uptime if at least one of the 1h, 24h or 7d uptime are not 0%
-		// If they're all 0%, then recalculating the uptime would be useless unless
-		// the result added was a success (result.Success)
-		if uptime.LastSevenDays != 0 || uptime.LastTwentyFourHours != 0 || uptime.LastHour != 0 {
-			uptime.recalculate()
+	oldestDailyUnixTimestampAllowed := now.Add(-(dailyStatisticsRetention + 24*time.Hour)).Unix()
+	for dailyUnixTimestamp := range uptime.DailyStatistics {
+		if oldestDailyUnixTimestampAllowed > dailyUnixTimestamp {
+			delete(uptime.DailyStatistics, dailyUnixTimestamp)
 		}
 	}
 }
 
 func (uptime *Uptime) recalculate() {
-	uptimeBrackets := make(map[string]uint64)
 	now := time.Now()
-	// The oldest uptime bracket starts 7 days ago, so we'll start from there
-	timestamp := now.Add(-sevenDays)
-	for now.Sub(timestamp) >= 0 {
-		hourlyUnixTimestamp := timestamp.Unix() - (timestamp.Unix() % 3600)
-		hourlyStats := uptime.HourlyStatistics[hourlyUnixTimestamp]
-		if hourlyStats == nil || hourlyStats.TotalExecutions == 0 {
-			timestamp = timestamp.Add(time.Hour)
-			continue
-		}
-		uptimeBrackets["7d_success"] += hourlyStats.SuccessfulExecutions
-		uptimeBrackets["7d_total"] += hourlyStats.TotalExecutions
-		if now.Sub(timestamp) <= 24*time.Hour {
-			uptimeBrackets["24h_success"] += hourlyStats.SuccessfulExecutions
-			uptimeBrackets["24h_total"] += hourlyStats.TotalExecutions
-		}
-		if now.Sub(timestamp) <= time.Hour {
-			uptimeBrackets["1h_success"] += hourlyStats.SuccessfulExecutions
-			uptimeBrackets["1h_total"] += hourlyStats.TotalExecutions
-		}
-		timestamp = timestamp.Add(time.Hour)
+	// If there are no executions within a window, the previous uptime and average response time for that window are kept
+	if value, averageResponseTime, ok := uptime.calculate(now, ninetyDays); ok {
+		uptime.LastNinetyDays = value
+		uptime.AverageResponseTime.LastNinetyDays = averageResponseTime
+	}
+	if value, averageResponseTime, ok := uptime.calculate(now, thirtyDays); ok {
+		uptime.LastThirtyDays = value
+		uptime.AverageResponseTime.LastThirtyDays = averageResponseTime
 	}
-	if uptimeBrackets["7d_total"] > 0 {
-		uptime.LastSevenDays = float64(uptimeBrackets["7d_success"]) / float64(uptimeBrackets["7d_total"])
+	if value, averageResponseTime, ok := uptime.calculate(now, sevenDays); ok {
+		uptime.LastSevenDays = value
+		uptime.AverageResponseTime.LastSevenDays = averageResponseTime
 	}
-	if uptimeBrackets["24h_total"] > 0 {
-		uptime.LastTwentyFourHours = float64(uptimeBrackets["24h_success"]) / float64(uptimeBrackets["24h_total"])
+	if value, averageResponseTime, ok := uptime.calculate(now, 24*time.Hour); ok {
+		uptime.LastTwentyFourHours = value
+		uptime.AverageResponseTime.LastTwentyFourHours = averageResponseTime
+	}
+	if value, averageResponseTime, ok := uptime.calculate(now, time.Hour); ok {
+		uptime.LastHour = value
+		uptime.AverageResponseTime.LastHour = averageResponseTime
+	}
+}
+
+// GetUptimeForWindow returns the uptime over an arbitrary window ending now, such as the last 3 hours or the last 14
+// days, as well as whether there was at least one execution within that window
+//
+// Like LastThirtyDays and LastNinetyDays, the uptime for windows longer than hourlyStatisticsRetention is only accurate
+// to the day, and executions older than dailyStatisticsRetention are not taken into account.
+func (uptime *Uptime) GetUptimeForWindow(window time.Duration) (float64, bool) {
+	value, _, ok := uptime.calculate(time.Now(), window)
+	return value, ok
+}
+
+// calculate returns the uptime and the average response time in milliseconds over an arbitrary window ending at the
+// given time, as well as whether there was at least one execution within that window
+//
+// Hourly statistics are used for the hours that have not been rolled up yet, and daily statistics are used for
+// everything else. As a result, the uptime for windows longer than hourlyStatisticsRetention is only accurate to the day.
+func (uptime *Uptime) calculate(now time.Time, window time.Duration) (float64, int64, bool) {
+	var successfulExecutions, totalExecutions, totalExecutionsResponseTime uint64
+	start := now.Add(-window).Unix()
+	hourlyStart := start - (start % 3600)
+	for hourlyUnixTimestamp, hourlyStats := range uptime.HourlyStatistics {
+		if hourlyUnixTimestamp >= hourlyStart && hourlyUnixTimestamp <= now.Unix() {
+			successfulExecutions += hourlyStats.SuccessfulExecutions
+			totalExecutions += hourlyStats.TotalExecutions
+			totalExecutionsResponseTime += hourlyStats.TotalExecutionsResponseTime
+		}
+	}
+	dailyStart := start - (start % 86400)
+	for dailyUnixTimestamp, dailyStats := range uptime.DailyStatistics {
+		if dailyUnixTimestamp >= dailyStart && dailyUnixTimestamp <= now.Unix() {
+			successfulExecutions += dailyStats.SuccessfulExecutions
+			totalExecutions += dailyStats.TotalExecutions
+			totalExecutionsResponseTime += dailyStats.TotalExecutionsResponseTime
+		}
 	}
-	if uptimeBrackets["1h_total"] > 0 {
-		uptime.LastHour = float64(uptimeBrackets["1h_success"]) / float64(uptimeBrackets["1h_total"])
+	if totalExecutions == 0 {
+		return 0, 0, false
 	}
+	return float64(successfulExecutions) / float64(totalExecutions), int64(totalExecutionsResponseTime / totalExecutions), true
 }
 
 // XXX: Remove this on v3.0.0
@@ -36,6 +36,12 @@ func TestUptime_ProcessResult(t *testing.T) {
 
 	uptime.ProcessResult(&Result{Timestamp: now.Add(-10 * time.Minute), Success: false})
 	checkUptimes(t, serviceStatus, 0.50, 0.50, 0.25)
+	if uptime.AverageResponseTime.LastHour != 133 {
+		t.Errorf("expected average response time over the last hour to be 133ms, got %dms", uptime.AverageResponseTime.LastHour)
+	}
+	if uptime.AverageResponseTime.LastSevenDays != 66 {
+		t.Errorf("expected average response time over the last 7 days to be 66ms, got %dms", uptime.AverageResponseTime.LastSevenDays)
+	}
 
 	uptime.ProcessResult(&Result{Timestamp: now.Add(-120 * time.Hour), Success: true})
 	uptime.ProcessResult(&Result{Timestamp: now.Add(-119 * time.Hour), Success: true})
@@ -71,6 +77,69 @@ func TestServiceStatus_AddResultUptimeIsCleaningUpAfterItself(t *testing.T) {
 	}
 }
 
+func TestUptime_ProcessResultWithLongerWindows(t *testing.T) {
+	uptime := NewUptime()
+	now := time.Now()
+	now = time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, now.Location())
+	// Simulate a service with an interval of 1 hour that has been failing for the first 60 days out of the last 100 days
+	for timestamp := now.Add(-100 * 24 * time.Hour); !timestamp.After(now); timestamp = timestamp.Add(time.Hour) {
+		uptime.ProcessResult(&Result{Timestamp: timestamp, Success: now.Sub(timestamp) < 40*24*time.Hour, Duration: 10 * time.Millisecond})
+		if len(uptime.HourlyStatistics) > numberOfHoursInTenDays {
+			t.Fatalf("At no point in time should there be more than %d entries in uptime.HourlyStatistics, but there are %d", numberOfHoursInTenDays, len(uptime.HourlyStatistics))
+		}
+	}
+	if len(uptime.DailyStatistics) == 0 {
+		t.Error("expected hourly statistics to have been rolled up into daily statistics")
+	}
+	if len(uptime.DailyStatistics) > 91 {
+		t.Errorf("expected daily statistics older than 90 days to have been deleted, but there are %d entries", len(uptime.DailyStatistics))
+	}
+	var totalExecutions uint64
+	for _, hourlyStats := range uptime.HourlyStatistics {
+		totalExecutions += hourlyStats.TotalExecutions
+	}
+	for _, dailyStats := range uptime.DailyStatistics {
+		totalExecutions += dailyStats.TotalExecutions
+	}
+	if totalExecutions < 90*24 {
+		t.Errorf("expected at least 90 days worth of executions to have been retained, got %d executions", totalExecutions)
+	}
+	if uptime.LastHour != 1 || uptime.LastTwentyFourHours != 1 || uptime.LastSevenDays != 1 || uptime.LastThirtyDays != 1 {
+		t.Errorf("expected uptime to be 100%% over the last 1h, 24h, 7d and 30d, got %f, %f, %f and %f", uptime.LastHour, uptime.LastTwentyFourHours, uptime.LastSevenDays, uptime.LastThirtyDays)
+	}
+	// The last 90 days include 40 days of successes and 50 days of failures, give or take a day
+	if uptime.LastNinetyDays < 0.43 || uptime.LastNinetyDays > 0.46 {
+		t.Errorf("expected uptime over the last 90d to be approximately 44%%, got %f", uptime.LastNinetyDays)
+	}
+}
+
+func TestUptime_GetUptimeForWindow(t *testing.T) {
+	uptime := NewUptime()
+	if _, ok := uptime.GetUptimeForWindow(3 * time.Hour); ok {
+		t.Error("expected no uptime, because there are no executions")
+	}
+	now := time.Now()
+	now = time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, now.Location())
+	// Simulate a service with an interval of 1 hour that has been failing for the first 20 days out of the last 40 days
+	for timestamp := now.Add(-40 * 24 * time.Hour); !timestamp.After(now); timestamp = timestamp.Add(time.Hour) {
+		uptime.ProcessResult(&Result{Timestamp: timestamp, Success: now.Sub(timestamp) < 20*24*time.Hour})
+	}
+	if len(uptime.DailyStatistics) == 0 {
+		t.Fatal("expected hourly statistics to have been rolled up into daily statistics")
+	}
+	if value, ok := uptime.GetUptimeForWindow(3 * time.Hour); !ok || value != 1 {
+		t.Errorf("expected uptime over the last 3h to be 100%%, got %f", value)
+	}
+	// The last 14 days are covered by both hourly and daily statistics
+	if value, ok := uptime.GetUptimeForWindow(14 * 24 * time.Hour); !ok || value != 1 {
+		t.Errorf("expected uptime over the last 14d to be 100%%, got %f", value)
+	}
+	// The last 40 days include 20 days of successes and 20 days of failures, give or take a day
+	if value, ok := uptime.GetUptimeForWindow(40 * 24 * time.Hour); !ok || value < 0.48 || value > 0.52 {
+		t.Errorf("expected uptime over the last 40d to be approximately 50%%, got %f", value)
+	}
+}
+
 func checkUptimes(t *testing.T, status *ServiceStatus, expectedUptimeDuringLastSevenDays, expectedUptimeDuringLastTwentyFourHours, expectedUptimeDuringLastHour float64) {
 	if status.Uptime.LastSevenDays != expectedUptimeDuringLastSevenDays {
 		t.Errorf("expected status.Uptime.LastSevenDays to be %f, got %f", expectedUptimeDuringLastHour, status.Uptime.LastSevenDays)
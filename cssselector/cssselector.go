@@ -0,0 +1,56 @@
+package cssselector
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Eval evaluates a CSS selector against an HTML document, and returns the text of the first element matched as well
+// as the number of elements matched.
+//
+// The selector can be a list of comma-separated selectors using the type, universal, class, id and attribute
+// selectors, the descendant (" "), child (">"), next-sibling ("+") and subsequent-sibling ("~") combinators, and the
+// structural pseudo-classes of Selectors Level 3 (:first-child, :nth-child(2n+1), :not(.hidden), ...), as well as
+// :contains("text"), which matches the elements whose text contains the given text.
+//
+// The text of an element is the text of all of its descendants, with sequences of whitespace replaced by a single
+// space and leading and trailing whitespace removed. If no element is matched, the text is empty.
+func Eval(selector string, b []byte) (string, int, error) {
+	selectors, err := parse(selector)
+	if err != nil {
+		return "", 0, err
+	}
+	document, err := html.Parse(bytes.NewReader(b))
+	if err != nil {
+		return "", 0, err
+	}
+	var matches []*html.Node
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && selectors.matches(n) {
+			matches = append(matches, n)
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(document)
+	if len(matches) == 0 {
+		return "", 0, nil
+	}
+	return strings.Join(strings.Fields(text(matches[0])), " "), len(matches), nil
+}
+
+// text returns the concatenation of the text nodes the node contains
+func text(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var builder strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		builder.WriteString(text(child))
+	}
+	return builder.String()
+}
@@ -0,0 +1,38 @@
+//go:build go1.18
+// +build go1.18
+
+package cssselector
+
+import (
+	"testing"
+)
+
+// FuzzEvalWithSelectorList checks that a selector list matches the union of the elements matched by its selectors,
+// regardless of the order of the selectors
+func FuzzEvalWithSelectorList(f *testing.F) {
+	f.Add("#status", "li.hidden", statusPage)
+	f.Add("body ul > li:nth-child(2n+1):not(.hidden, [data-status=up i])", "li", statusPage)
+	f.Add("#status ~ p + footer > :last-child", `a[href^='https://'], li:contains("Data")`, statusPage)
+	f.Add(`.a\:b`, `.\31 0`, `<span class="a:b">x</span><span class="10">y</span>`)
+	f.Fuzz(func(t *testing.T, first, second, data string) {
+		_, firstLength, err := Eval(first, []byte(data))
+		if err != nil {
+			return
+		}
+		_, secondLength, err := Eval(second, []byte(data))
+		if err != nil {
+			return
+		}
+		output, length, err := Eval(first+", "+second, []byte(data))
+		if err != nil {
+			t.Fatalf("expected no error, because both selectors are valid, got %v", err)
+		}
+		if length < firstLength || length < secondLength || length > firstLength+secondLength {
+			t.Errorf("expected the list to match at least %d and %d elements and at most %d elements, got %d", firstLength, secondLength, firstLength+secondLength, length)
+		}
+		reversedOutput, reversedLength, err := Eval(second+", "+first, []byte(data))
+		if err != nil || output != reversedOutput || length != reversedLength {
+			t.Errorf("expected the order of the selectors not to matter, got '%s' (%d) and '%s' (%d)", output, length, reversedOutput, reversedLength)
+		}
+	})
+}
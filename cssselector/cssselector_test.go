@@ -0,0 +1,190 @@
+package cssselector
+
+import (
+	"testing"
+)
+
+const statusPage = `<!DOCTYPE html>
+<html lang="en-US">
+<head><title>Status</title></head>
+<body>
+  <div id="status" class="banner  green">
+    All systems
+    <b>operational</b>
+  </div>
+  <ul class="components">
+    <li class="component" data-status="up">API</li>
+    <li class="component" data-status="degraded">Database</li>
+    <li class="component hidden" data-status="up">Legacy</li>
+    <li class="component" data-status="UP">Queue</li>
+  </ul>
+  <p></p>
+  <footer><a href="https://example.org/incidents">Incidents</a><span>v1.2.3</span></footer>
+</body>
+</html>`
+
+func TestEval(t *testing.T) {
+	type Scenario struct {
+		Name                 string
+		Selector             string
+		Data                 string
+		ExpectedOutput       string
+		ExpectedOutputLength int
+		ExpectedError        bool
+	}
+	scenarios := []Scenario{
+		{
+			Name:                 "id",
+			Selector:             "#status",
+			Data:                 statusPage,
+			ExpectedOutput:       "All systems operational",
+			ExpectedOutputLength: 1,
+		},
+		{
+			Name:                 "type-and-class",
+			Selector:             "DIV.green",
+			Data:                 statusPage,
+			ExpectedOutput:       "All systems operational",
+			ExpectedOutputLength: 1,
+		},
+		{
+			Name:                 "multiple-elements",
+			Selector:             "li.component",
+			Data:                 statusPage,
+			ExpectedOutput:       "API",
+			ExpectedOutputLength: 4,
+		},
+		{
+			Name:                 "descendant-and-child-combinators",
+			Selector:             "body ul > li:nth-child(2)",
+			Data:                 statusPage,
+			ExpectedOutput:       "Database",
+			ExpectedOutputLength: 1,
+		},
+		{
+			Name:                 "sibling-combinators",
+			Selector:             "#status ~ p + footer > :last-child",
+			Data:                 statusPage,
+			ExpectedOutput:       "v1.2.3",
+			ExpectedOutputLength: 1,
+		},
+		{
+			Name:                 "attribute",
+			Selector:             `li[data-status="up"]`,
+			Data:                 statusPage,
+			ExpectedOutput:       "API",
+			ExpectedOutputLength: 2,
+		},
+		{
+			Name:                 "attribute-case-insensitive",
+			Selector:             "li[data-status=up i]",
+			Data:                 statusPage,
+			ExpectedOutput:       "API",
+			ExpectedOutputLength: 3,
+		},
+		{
+			Name:                 "attribute-operators",
+			Selector:             "a[href^='https://'][href$=incidents][href*=example], html[lang|=en]",
+			Data:                 statusPage,
+			ExpectedOutput:       "Status All systems operational API Database Legacy Queue Incidentsv1.2.3",
+			ExpectedOutputLength: 2,
+		},
+		{
+			Name:                 "not",
+			Selector:             "li:not(.hidden, [data-status=up])",
+			Data:                 statusPage,
+			ExpectedOutput:       "Database",
+			ExpectedOutputLength: 2,
+		},
+		{
+			Name:                 "nth-child-odd",
+			Selector:             "li:nth-child(odd)",
+			Data:                 statusPage,
+			ExpectedOutput:       "API",
+			ExpectedOutputLength: 2,
+		},
+		{
+			Name:                 "nth-last-of-type",
+			Selector:             "li:nth-last-of-type(-n + 2)",
+			Data:                 statusPage,
+			ExpectedOutput:       "Legacy",
+			ExpectedOutputLength: 2,
+		},
+		{
+			Name:                 "contains",
+			Selector:             "li:contains('Data')",
+			Data:                 statusPage,
+			ExpectedOutput:       "Database",
+			ExpectedOutputLength: 1,
+		},
+		{
+			Name:                 "empty",
+			Selector:             "body > :empty",
+			Data:                 statusPage,
+			ExpectedOutput:       "",
+			ExpectedOutputLength: 1,
+		},
+		{
+			Name:                 "escaped-class",
+			Selector:             `.a\:b, .\31 0`,
+			Data:                 `<span class="a:b">x</span><span class="10">y</span>`,
+			ExpectedOutput:       "x",
+			ExpectedOutputLength: 2,
+		},
+		{
+			Name:                 "no-match",
+			Selector:             ".missing",
+			Data:                 statusPage,
+			ExpectedOutput:       "",
+			ExpectedOutputLength: 0,
+		},
+		{
+			Name:          "unsupported-pseudo-class",
+			Selector:      "a:hover",
+			Data:          statusPage,
+			ExpectedError: true,
+		},
+		{
+			Name:          "pseudo-element",
+			Selector:      "p::before",
+			Data:          statusPage,
+			ExpectedError: true,
+		},
+		{
+			Name:          "invalid-nth",
+			Selector:      "li:nth-child(n2)",
+			Data:          statusPage,
+			ExpectedError: true,
+		},
+		{
+			Name:          "unterminated-attribute",
+			Selector:      "li[data-status=up",
+			Data:          statusPage,
+			ExpectedError: true,
+		},
+		{
+			Name:          "dangling-combinator",
+			Selector:      "ul >",
+			Data:          statusPage,
+			ExpectedError: true,
+		},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			output, outputLength, err := Eval(scenario.Selector, []byte(scenario.Data))
+			if (err != nil) != scenario.ExpectedError {
+				if scenario.ExpectedError {
+					t.Errorf("Expected error, got '%v'", err)
+				} else {
+					t.Errorf("Expected no error, got '%v'", err)
+				}
+			}
+			if outputLength != scenario.ExpectedOutputLength {
+				t.Errorf("Expected output length to be %v, but was %v", scenario.ExpectedOutputLength, outputLength)
+			}
+			if output != scenario.ExpectedOutput {
+				t.Errorf("Expected output to be %v, but was %v", scenario.ExpectedOutput, output)
+			}
+		})
+	}
+}
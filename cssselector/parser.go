@@ -0,0 +1,378 @@
+package cssselector
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// nthPattern is the pattern of the argument of the :nth-* pseudo-classes, once stripped of whitespace
+var nthPattern = regexp.MustCompile(`^(?:([+-]?\d*)n([+-]\d+)?|([+-]?\d+))$`)
+
+// parser parses CSS selectors
+type parser struct {
+	selector string
+	position int
+}
+
+// parse parses a list of comma-separated selectors
+func parse(selector string) (selectorList, error) {
+	p := &parser{selector: selector}
+	selectors, err := p.parseSelectorList()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, p.errorf("unexpected character %q", p.peekRune())
+	}
+	return selectors, nil
+}
+
+func (p *parser) parseSelectorList() (selectorList, error) {
+	var selectors selectorList
+	for {
+		p.skipWhitespace()
+		selector, err := p.parseComplexSelector()
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+		p.skipWhitespace()
+		if !p.consume(',') {
+			return selectors, nil
+		}
+	}
+}
+
+func (p *parser) parseComplexSelector() (*complexSelector, error) {
+	selector := &complexSelector{}
+	for {
+		compound, err := p.parseCompoundSelector()
+		if err != nil {
+			return nil, err
+		}
+		selector.compounds = append(selector.compounds, compound)
+		hasWhitespace := p.skipWhitespace()
+		combinator := p.peek()
+		switch {
+		case combinator == '>' || combinator == '+' || combinator == '~':
+			p.position++
+			p.skipWhitespace()
+		case hasWhitespace && !p.done() && combinator != ',' && combinator != ')':
+			combinator = ' '
+		default:
+			return selector, nil
+		}
+		selector.combinators = append(selector.combinators, combinator)
+	}
+}
+
+func (p *parser) parseCompoundSelector() (*compoundSelector, error) {
+	compound := &compoundSelector{}
+	start := p.position
+	if p.consume('*') {
+		// The universal selector matches any element, which is the default
+	} else if p.startsIdentifier() {
+		tag, err := p.parseIdentifier()
+		if err != nil {
+			return nil, err
+		}
+		compound.tag = strings.ToLower(tag)
+	}
+	for {
+		var c condition
+		var err error
+		switch {
+		case p.consume('#'):
+			var id string
+			if id, err = p.parseIdentifier(); err == nil {
+				c = attributeCondition{name: "id", operator: "=", value: id}
+			}
+		case p.consume('.'):
+			var class string
+			if class, err = p.parseIdentifier(); err == nil {
+				c = attributeCondition{name: "class", operator: "~=", value: class}
+			}
+		case p.consume('['):
+			c, err = p.parseAttributeCondition()
+		case p.consume(':'):
+			c, err = p.parsePseudoClass()
+		default:
+			if p.position == start {
+				return nil, p.errorf("expected a selector")
+			}
+			return compound, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		compound.conditions = append(compound.conditions, c)
+	}
+}
+
+// parseAttributeCondition parses an attribute selector following a '['
+func (p *parser) parseAttributeCondition() (condition, error) {
+	p.skipWhitespace()
+	name, err := p.parseIdentifier()
+	if err != nil {
+		return nil, err
+	}
+	c := attributeCondition{name: strings.ToLower(name)}
+	p.skipWhitespace()
+	if p.consume(']') {
+		return c, nil
+	}
+	for _, operator := range []string{"=", "~=", "|=", "^=", "$=", "*="} {
+		if strings.HasPrefix(p.selector[p.position:], operator) {
+			c.operator = operator
+			p.position += len(operator)
+			break
+		}
+	}
+	if len(c.operator) == 0 {
+		return nil, p.errorf("expected an attribute operator")
+	}
+	p.skipWhitespace()
+	if p.peek() == '"' || p.peek() == '\'' {
+		c.value, err = p.parseString()
+	} else {
+		c.value, err = p.parseIdentifier()
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.skipWhitespace()
+	if p.consume('i') || p.consume('I') {
+		c.caseInsensitive = true
+		p.skipWhitespace()
+	} else if p.consume('s') || p.consume('S') {
+		p.skipWhitespace()
+	}
+	if !p.consume(']') {
+		return nil, p.errorf("expected ']'")
+	}
+	return c, nil
+}
+
+// parsePseudoClass parses a pseudo-class following a ':'
+func (p *parser) parsePseudoClass() (condition, error) {
+	if p.peek() == ':' {
+		return nil, p.errorf("pseudo-elements are not supported")
+	}
+	start := p.position
+	name, err := p.parseIdentifier()
+	if err != nil {
+		return nil, err
+	}
+	name = strings.ToLower(name)
+	if !p.consume('(') {
+		if c, exists := pseudoClasses[name]; exists {
+			return c, nil
+		}
+		p.position = start
+		return nil, p.errorf("unsupported pseudo-class ':%s'", name)
+	}
+	var c condition
+	switch name {
+	case "not":
+		var selectors selectorList
+		if selectors, err = p.parseSelectorList(); err == nil {
+			c = notCondition{selectors: selectors}
+		}
+	case "contains":
+		p.skipWhitespace()
+		var text string
+		if p.peek() == '"' || p.peek() == '\'' {
+			text, err = p.parseString()
+		} else {
+			text, err = p.parseIdentifier()
+		}
+		c = containsCondition{text: text}
+	case "nth-child", "nth-last-child", "nth-of-type", "nth-last-of-type":
+		end := strings.IndexByte(p.selector[p.position:], ')')
+		if end == -1 {
+			return nil, p.errorf("expected ')'")
+		}
+		nth := nthCondition{fromEnd: strings.Contains(name, "last"), ofType: strings.HasSuffix(name, "of-type")}
+		if nth.a, nth.b, err = parseNth(p.selector[p.position : p.position+end]); err != nil {
+			return nil, p.errorf("%s", err.Error())
+		}
+		p.position += end
+		c = nth
+	default:
+		p.position = start
+		return nil, p.errorf("unsupported pseudo-class ':%s()'", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.skipWhitespace()
+	if !p.consume(')') {
+		return nil, p.errorf("expected ')'")
+	}
+	return c, nil
+}
+
+// parseNth parses the argument of the :nth-* pseudo-classes, which is "odd", "even", or of the form an+b
+func parseNth(argument string) (int, int, error) {
+	argument = strings.ToLower(strings.Join(strings.Fields(argument), ""))
+	switch argument {
+	case "odd":
+		return 2, 1, nil
+	case "even":
+		return 2, 0, nil
+	}
+	match := nthPattern.FindStringSubmatch(argument)
+	if match == nil {
+		return 0, 0, fmt.Errorf("invalid argument '%s'", argument)
+	}
+	if len(match[3]) > 0 {
+		b, err := strconv.Atoi(match[3])
+		return 0, b, err
+	}
+	a, b := 1, 0
+	var err error
+	switch match[1] {
+	case "", "+":
+	case "-":
+		a = -1
+	default:
+		if a, err = strconv.Atoi(match[1]); err != nil {
+			return 0, 0, err
+		}
+	}
+	if len(match[2]) > 0 {
+		if b, err = strconv.Atoi(match[2]); err != nil {
+			return 0, 0, err
+		}
+	}
+	return a, b, nil
+}
+
+// startsIdentifier checks whether an identifier starts at the current position
+func (p *parser) startsIdentifier() bool {
+	c := p.peek()
+	return c == '-' || c == '_' || c == '\\' || c >= 0x80 || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// parseIdentifier parses an identifier, such as the name of an element or of a class, which may contain escaped
+// characters
+func (p *parser) parseIdentifier() (string, error) {
+	var builder strings.Builder
+	for !p.done() {
+		c := p.peek()
+		switch {
+		case c == '\\':
+			p.position++
+			if p.done() {
+				return "", p.errorf("unexpected end of selector")
+			}
+			builder.WriteRune(p.parseEscape())
+		case c == '-' || c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9'):
+			builder.WriteByte(c)
+			p.position++
+		case c >= 0x80:
+			r, size := utf8.DecodeRuneInString(p.selector[p.position:])
+			builder.WriteRune(r)
+			p.position += size
+		default:
+			if builder.Len() == 0 {
+				return "", p.errorf("expected an identifier")
+			}
+			return builder.String(), nil
+		}
+	}
+	if builder.Len() == 0 {
+		return "", p.errorf("expected an identifier")
+	}
+	return builder.String(), nil
+}
+
+// parseEscape parses the character following a backslash, which is either up to 6 hexadecimal digits optionally
+// followed by a whitespace, or any other character
+func (p *parser) parseEscape() rune {
+	end := p.position
+	for end < len(p.selector) && end-p.position < 6 && strings.IndexByte("0123456789abcdefABCDEF", p.selector[end]) != -1 {
+		end++
+	}
+	if end == p.position {
+		r, size := utf8.DecodeRuneInString(p.selector[p.position:])
+		p.position += size
+		return r
+	}
+	codePoint, _ := strconv.ParseUint(p.selector[p.position:end], 16, 32)
+	p.position = end
+	if !p.done() && strings.IndexByte(" \t\n\r\f", p.peek()) != -1 {
+		p.position++
+	}
+	if codePoint == 0 || codePoint > utf8.MaxRune || (codePoint >= 0xD800 && codePoint <= 0xDFFF) {
+		return utf8.RuneError
+	}
+	return rune(codePoint)
+}
+
+// parseString parses a string delimited by single or double quotes
+func (p *parser) parseString() (string, error) {
+	quote := p.peek()
+	p.position++
+	var builder strings.Builder
+	for !p.done() {
+		c := p.peek()
+		switch c {
+		case quote:
+			p.position++
+			return builder.String(), nil
+		case '\\':
+			p.position++
+			if p.done() {
+				return "", p.errorf("unterminated string")
+			}
+			builder.WriteRune(p.parseEscape())
+		default:
+			builder.WriteByte(c)
+			p.position++
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *parser) done() bool {
+	return p.position >= len(p.selector)
+}
+
+// peek returns the character at the current position, or 0 if the end of the selector has been reached
+func (p *parser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.selector[p.position]
+}
+
+func (p *parser) peekRune() rune {
+	r, _ := utf8.DecodeRuneInString(p.selector[p.position:])
+	return r
+}
+
+// consume advances past the given character if the selector continues with it
+func (p *parser) consume(c byte) bool {
+	if p.peek() == c && !p.done() {
+		p.position++
+		return true
+	}
+	return false
+}
+
+// skipWhitespace advances past any whitespace, and returns whether there was any
+func (p *parser) skipWhitespace() bool {
+	start := p.position
+	for !p.done() && strings.IndexByte(" \t\n\r\f", p.peek()) != -1 {
+		p.position++
+	}
+	return p.position != start
+}
+
+func (p *parser) errorf(format string, arguments ...interface{}) error {
+	return fmt.Errorf("invalid selector '%s' at position %d: %s", p.selector, p.position+1, fmt.Sprintf(format, arguments...))
+}
@@ -0,0 +1,250 @@
+package cssselector
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// selectorList is a list of selectors, which matches the elements matched by any of its selectors
+type selectorList []*complexSelector
+
+func (s selectorList) matches(n *html.Node) bool {
+	for _, selector := range s {
+		if selector.matches(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// complexSelector is a sequence of compound selectors separated by combinators, such as "ul.menu > li a"
+type complexSelector struct {
+	compounds []*compoundSelector
+
+	// combinators are the combinators between each compound selector, which are ' ', '>', '+' or '~'
+	combinators []byte
+}
+
+func (s *complexSelector) matches(n *html.Node) bool {
+	return s.matchesFrom(n, len(s.compounds)-1, make(map[match]bool))
+}
+
+// match is an element and the index of a compound selector, which is used to memoize the result of matchesFrom
+type match struct {
+	node  *html.Node
+	index int
+}
+
+// matchesFrom checks whether the element matches the compound selector at the given index, and whether the elements
+// it is related to by the combinators match the compound selectors before it. The results are memoized, because
+// without it, the number of combinations of ancestors to try grows exponentially with the number of descendant
+// combinators.
+func (s *complexSelector) matchesFrom(n *html.Node, index int, memo map[match]bool) bool {
+	if matched, exists := memo[match{n, index}]; exists {
+		return matched
+	}
+	matched := s.compounds[index].matches(n)
+	if matched && index > 0 {
+		matched = false
+		switch s.combinators[index-1] {
+		case ' ':
+			for ancestor := parentElement(n); ancestor != nil && !matched; ancestor = parentElement(ancestor) {
+				matched = s.matchesFrom(ancestor, index-1, memo)
+			}
+		case '>':
+			if parent := parentElement(n); parent != nil {
+				matched = s.matchesFrom(parent, index-1, memo)
+			}
+		case '+':
+			if sibling := previousElementSibling(n); sibling != nil {
+				matched = s.matchesFrom(sibling, index-1, memo)
+			}
+		case '~':
+			for sibling := previousElementSibling(n); sibling != nil && !matched; sibling = previousElementSibling(sibling) {
+				matched = s.matchesFrom(sibling, index-1, memo)
+			}
+		}
+	}
+	memo[match{n, index}] = matched
+	return matched
+}
+
+// compoundSelector is a sequence of simple selectors that all apply to the same element, such as "a.external[href]"
+type compoundSelector struct {
+	// tag is the name of the element, or an empty string for any element
+	tag string
+
+	conditions []condition
+}
+
+func (s *compoundSelector) matches(n *html.Node) bool {
+	if len(s.tag) > 0 && s.tag != n.Data {
+		return false
+	}
+	for _, c := range s.conditions {
+		if !c.matches(n) {
+			return false
+		}
+	}
+	return true
+}
+
+// condition is a simple selector other than a type selector, such as a class selector or a pseudo-class
+type condition interface {
+	matches(n *html.Node) bool
+}
+
+// attributeCondition matches elements with an attribute, optionally with a value matching the operator
+type attributeCondition struct {
+	name     string
+	operator string
+	value    string
+
+	caseInsensitive bool
+}
+
+func (c attributeCondition) matches(n *html.Node) bool {
+	value, exists := attribute(n, c.name)
+	if !exists {
+		return false
+	}
+	expected := c.value
+	if c.caseInsensitive {
+		value, expected = strings.ToLower(value), strings.ToLower(expected)
+	}
+	switch c.operator {
+	case "":
+		return true
+	case "=":
+		return value == expected
+	case "~=":
+		for _, word := range strings.Fields(value) {
+			if word == expected {
+				return true
+			}
+		}
+		return false
+	case "|=":
+		return value == expected || strings.HasPrefix(value, expected+"-")
+	case "^=":
+		return len(expected) > 0 && strings.HasPrefix(value, expected)
+	case "$=":
+		return len(expected) > 0 && strings.HasSuffix(value, expected)
+	case "*=":
+		return len(expected) > 0 && strings.Contains(value, expected)
+	}
+	return false
+}
+
+// nthCondition matches elements whose position among their siblings is an+b for some non-negative integer n
+type nthCondition struct {
+	a, b int
+
+	// fromEnd is whether the position is counted from the last sibling
+	fromEnd bool
+
+	// ofType is whether only the siblings with the same name as the element are counted
+	ofType bool
+}
+
+func (c nthCondition) matches(n *html.Node) bool {
+	position := 1
+	next := previousElementSibling
+	if c.fromEnd {
+		next = nextElementSibling
+	}
+	for sibling := next(n); sibling != nil; sibling = next(sibling) {
+		if !c.ofType || sibling.Data == n.Data {
+			position++
+		}
+	}
+	if c.a == 0 {
+		return position == c.b
+	}
+	return (position-c.b)/c.a >= 0 && (position-c.b)%c.a == 0
+}
+
+// functionCondition is a pseudo-class that doesn't take any argument, such as :empty
+type functionCondition func(n *html.Node) bool
+
+func (c functionCondition) matches(n *html.Node) bool {
+	return c(n)
+}
+
+// notCondition matches elements that don't match any of the selectors
+type notCondition struct {
+	selectors selectorList
+}
+
+func (c notCondition) matches(n *html.Node) bool {
+	return !c.selectors.matches(n)
+}
+
+// containsCondition matches elements whose text contains the given text
+type containsCondition struct {
+	text string
+}
+
+func (c containsCondition) matches(n *html.Node) bool {
+	return strings.Contains(text(n), c.text)
+}
+
+// pseudoClasses are the pseudo-classes that don't take any argument
+var pseudoClasses = map[string]condition{
+	"root": functionCondition(func(n *html.Node) bool {
+		return n.Parent != nil && n.Parent.Type == html.DocumentNode
+	}),
+	"empty": functionCondition(func(n *html.Node) bool {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == html.ElementNode || (child.Type == html.TextNode && len(child.Data) > 0) {
+				return false
+			}
+		}
+		return true
+	}),
+	"first-child":   nthCondition{b: 1},
+	"last-child":    nthCondition{b: 1, fromEnd: true},
+	"first-of-type": nthCondition{b: 1, ofType: true},
+	"last-of-type":  nthCondition{b: 1, fromEnd: true, ofType: true},
+	"only-child": functionCondition(func(n *html.Node) bool {
+		return previousElementSibling(n) == nil && nextElementSibling(n) == nil
+	}),
+	"only-of-type": functionCondition(func(n *html.Node) bool {
+		return nthCondition{b: 1, ofType: true}.matches(n) && nthCondition{b: 1, fromEnd: true, ofType: true}.matches(n)
+	}),
+}
+
+func attribute(n *html.Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if len(a.Namespace) == 0 && a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func parentElement(n *html.Node) *html.Node {
+	if n.Parent != nil && n.Parent.Type == html.ElementNode {
+		return n.Parent
+	}
+	return nil
+}
+
+func previousElementSibling(n *html.Node) *html.Node {
+	for sibling := n.PrevSibling; sibling != nil; sibling = sibling.PrevSibling {
+		if sibling.Type == html.ElementNode {
+			return sibling
+		}
+	}
+	return nil
+}
+
+func nextElementSibling(n *html.Node) *html.Node {
+	for sibling := n.NextSibling; sibling != nil; sibling = sibling.NextSibling {
+		if sibling.Type == html.ElementNode {
+			return sibling
+		}
+	}
+	return nil
+}
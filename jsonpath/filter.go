@@ -0,0 +1,265 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// expression is the logical expression of a filter, which is evaluated for each candidate node
+type expression interface {
+	evaluate(node, root interface{}) bool
+}
+
+type orExpression struct {
+	left, right expression
+}
+
+func (e orExpression) evaluate(node, root interface{}) bool {
+	return e.left.evaluate(node, root) || e.right.evaluate(node, root)
+}
+
+type andExpression struct {
+	left, right expression
+}
+
+func (e andExpression) evaluate(node, root interface{}) bool {
+	return e.left.evaluate(node, root) && e.right.evaluate(node, root)
+}
+
+type notExpression struct {
+	expression expression
+}
+
+func (e notExpression) evaluate(node, root interface{}) bool {
+	return !e.expression.evaluate(node, root)
+}
+
+// existenceExpression is true if the query selects at least one node, such as in $.items[?(@.id)]
+type existenceExpression struct {
+	query queryOperand
+}
+
+func (e existenceExpression) evaluate(node, root interface{}) bool {
+	return len(e.query.nodes(node, root)) > 0
+}
+
+// comparisonExpression compares two operands, such as in $.items[?(@.status == 'up')]
+type comparisonExpression struct {
+	left, right operand
+	operator    string
+}
+
+func (e comparisonExpression) evaluate(node, root interface{}) bool {
+	left, leftExists := e.left.value(node, root)
+	right, rightExists := e.right.value(node, root)
+	switch e.operator {
+	case "==":
+		return equal(left, leftExists, right, rightExists)
+	case "!=":
+		return !equal(left, leftExists, right, rightExists)
+	case "<":
+		return less(left, leftExists, right, rightExists)
+	case "<=":
+		return less(left, leftExists, right, rightExists) || equal(left, leftExists, right, rightExists)
+	case ">":
+		return less(right, rightExists, left, leftExists)
+	case ">=":
+		return less(right, rightExists, left, leftExists) || equal(left, leftExists, right, rightExists)
+	}
+	return false
+}
+
+// operand is one side of a comparison
+type operand interface {
+	// value returns the value of the operand, and whether it has one
+	value(node, root interface{}) (interface{}, bool)
+}
+
+type literalOperand struct {
+	literal interface{}
+}
+
+func (o literalOperand) value(_, _ interface{}) (interface{}, bool) {
+	return o.literal, true
+}
+
+// queryOperand is a query relative to the current node (@) or to the root node ($)
+type queryOperand struct {
+	query    *query
+	relative bool
+}
+
+func (o queryOperand) nodes(node, root interface{}) []interface{} {
+	if o.relative {
+		return o.query.evaluate(node, root)
+	}
+	return o.query.evaluate(root, root)
+}
+
+// value returns the node selected by the query, but only if the query selected exactly one node
+func (o queryOperand) value(node, root interface{}) (interface{}, bool) {
+	if nodes := o.nodes(node, root); len(nodes) == 1 {
+		return nodes[0], true
+	}
+	return nil, false
+}
+
+// equal checks whether two values are equal. Two values that don't exist are considered equal.
+func equal(left interface{}, leftExists bool, right interface{}, rightExists bool) bool {
+	if !leftExists || !rightExists {
+		return !leftExists && !rightExists
+	}
+	leftNumber, leftIsNumber := toNumber(left)
+	rightNumber, rightIsNumber := toNumber(right)
+	if leftIsNumber || rightIsNumber {
+		return leftIsNumber && rightIsNumber && leftNumber == rightNumber
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+// less checks whether the left value is lower than the right value. Only numbers and strings can be ordered.
+func less(left interface{}, leftExists bool, right interface{}, rightExists bool) bool {
+	if !leftExists || !rightExists {
+		return false
+	}
+	leftNumber, leftIsNumber := toNumber(left)
+	rightNumber, rightIsNumber := toNumber(right)
+	if leftIsNumber && rightIsNumber {
+		return leftNumber < rightNumber
+	}
+	leftString, leftIsString := left.(string)
+	rightString, rightIsString := right.(string)
+	return leftIsString && rightIsString && leftString < rightString
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch value := value.(type) {
+	case json.Number:
+		number, err := value.Float64()
+		return number, err == nil
+	case float64:
+		return value, true
+	}
+	return 0, false
+}
+
+// parseFilter parses the logical expression following the '?' of a filter selector
+func (p *parser) parseFilter() (expression, error) {
+	return p.parseOrExpression()
+}
+
+func (p *parser) parseOrExpression() (expression, error) {
+	left, err := p.parseAndExpression()
+	if err != nil {
+		return nil, err
+	}
+	for p.skipSpaces(); p.consume("||"); p.skipSpaces() {
+		right, err := p.parseAndExpression()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpression{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAndExpression() (expression, error) {
+	left, err := p.parseUnaryExpression()
+	if err != nil {
+		return nil, err
+	}
+	for p.skipSpaces(); p.consume("&&"); p.skipSpaces() {
+		right, err := p.parseUnaryExpression()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpression{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnaryExpression() (expression, error) {
+	p.skipSpaces()
+	switch {
+	case p.peek() == '!' && !strings.HasPrefix(p.path[p.position:], "!="):
+		p.position++
+		e, err := p.parseUnaryExpression()
+		if err != nil {
+			return nil, err
+		}
+		return notExpression{expression: e}, nil
+	case p.consume("("):
+		e, err := p.parseOrExpression()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if !p.consume(")") {
+			return nil, p.errorf("expected ')'")
+		}
+		return e, nil
+	}
+	return p.parseComparisonExpression()
+}
+
+// parseComparisonExpression parses a comparison, or a query on its own, which tests the existence of a node
+func (p *parser) parseComparisonExpression() (expression, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	for _, operator := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(operator) {
+			right, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			return comparisonExpression{left: left, right: right, operator: operator}, nil
+		}
+	}
+	if query, ok := left.(queryOperand); ok {
+		return existenceExpression{query: query}, nil
+	}
+	return nil, p.errorf("expected a comparison operator")
+}
+
+// parseOperand parses a query or a literal
+func (p *parser) parseOperand() (operand, error) {
+	p.skipSpaces()
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		p.position++
+		q, err := p.parseSegments()
+		if err != nil {
+			return nil, err
+		}
+		return queryOperand{query: q, relative: c == '@'}, nil
+	case c == '\'' || c == '"':
+		value, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return literalOperand{literal: value}, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		start := p.position
+		for !p.done() && strings.IndexByte("0123456789+-.eE", p.peek()) != -1 {
+			p.position++
+		}
+		number, err := strconv.ParseFloat(p.path[start:p.position], 64)
+		if err != nil {
+			p.position = start
+			return nil, p.errorf("invalid number")
+		}
+		return literalOperand{literal: number}, nil
+	case p.consume("true"):
+		return literalOperand{literal: true}, nil
+	case p.consume("false"):
+		return literalOperand{literal: false}, nil
+	case p.consume("null"):
+		return literalOperand{literal: nil}, nil
+	}
+	return nil, p.errorf("expected a query or a literal")
+}
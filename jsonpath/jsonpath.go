@@ -1,77 +1,165 @@
 package jsonpath
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 )
 
-// Eval is a half-baked json path implementation that needs some love
+// Eval evaluates a JSONPath expression against a JSON document, and returns the value the expression resolves to as
+// well as the length of that value.
+//
+// The syntax is the one of RFC 9535, which includes the dot and the bracket notations ($.data.name,
+// $['key.with.dots']), wildcards ($.items[*]), negative indexes ($.items[-1]), slices ($.items[0:2]), recursive
+// descent ($..name) and filters ($.items[?(@.status == 'up')]). For convenience, the leading "$" as well as the "."
+// before the first name may be omitted, which means that "data.name", ".data.name" and "$.data.name" are equivalent.
+//
+// If the expression can only ever select a single value, which is the case when it only consists of names and
+// indexes, that value is returned: strings are returned as is, with their length, and objects and arrays are
+// returned as JSON, with their number of members and elements. An error is returned if there's no such value.
+// Otherwise, the values selected are returned as a JSON array, with the number of values selected as length.
 func Eval(path string, b []byte) (string, int, error) {
-	var object interface{}
-	err := json.Unmarshal(b, &object)
+	q, nodes, err := evaluate(path, b)
 	if err != nil {
-		// Try to unmarshal it into an array instead
 		return "", 0, err
 	}
-	return walk(path, object)
+	if q.isSingular() {
+		if len(nodes) == 0 {
+			return "", 0, fmt.Errorf("no value found at '%s'", path)
+		}
+		return format(nodes[0])
+	}
+	output, err := marshal(nodes)
+	return output, len(nodes), err
 }
 
-func walk(path string, object interface{}) (string, int, error) {
-	keys := strings.Split(path, ".")
-	currentKey := keys[0]
-	switch value := extractValue(currentKey, object).(type) {
-	case map[string]interface{}:
-		return walk(strings.Replace(path, fmt.Sprintf("%s.", currentKey), "", 1), value)
-	case string:
-		if len(keys) > 1 {
-			return "", 0, fmt.Errorf("couldn't walk through '%s', because '%s' was a string instead of an object", keys[1], currentKey)
+// Exists checks whether a JSONPath expression selects at least one value other than null from a JSON document
+//
+// Unlike RFC 9535, which considers that a member whose value is null exists, a null value is treated as absent, which
+// is how the has function has always behaved.
+func Exists(path string, b []byte) bool {
+	_, nodes, err := evaluate(path, b)
+	if err != nil {
+		return false
+	}
+	for _, node := range nodes {
+		if node != nil {
+			return true
 		}
+	}
+	return false
+}
+
+// evaluate parses a JSONPath expression and returns the values it selects from a JSON document
+func evaluate(path string, b []byte) (*query, []interface{}, error) {
+	q, err := parse(normalize(path))
+	if err != nil {
+		return nil, nil, err
+	}
+	document, err := decode(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	return q, q.evaluate(document, document), nil
+}
+
+// normalize prepends the root identifier to paths that omit it
+func normalize(path string) string {
+	switch {
+	case len(path) == 0:
+		return "$"
+	case path[0] == '$' && (len(path) == 1 || path[1] == '.' || path[1] == '['):
+		return path
+	case path[0] == '.' || path[0] == '[':
+		return "$" + path
+	default:
+		return "$." + path
+	}
+}
+
+// decode unmarshals a JSON document, preserving the representation of its numbers
+func decode(b []byte) (interface{}, error) {
+	if !json.Valid(b) {
+		// Unmarshal is only used to get a meaningful error
+		var document interface{}
+		if err := json.Unmarshal(b, &document); err != nil {
+			return nil, err
+		}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// format returns the string representation of a value and its length
+func format(value interface{}) (string, int, error) {
+	switch value := value.(type) {
+	case string:
 		return value, len(value), nil
+	case json.Number:
+		return value.String(), 1, nil
+	case bool:
+		return strconv.FormatBool(value), 1, nil
+	case nil:
+		return "null", 0, nil
+	case map[string]interface{}:
+		output, err := marshal(value)
+		return output, len(value), err
 	case []interface{}:
-		return fmt.Sprintf("%v", value), len(value), nil
-	case interface{}:
-		return fmt.Sprintf("%v", value), 1, nil
+		output, err := marshal(value)
+		return output, len(value), err
 	default:
-		return "", 0, fmt.Errorf("couldn't walk through '%s' because type was '%T', but expected 'map[string]interface{}'", currentKey, value)
+		return "", 0, fmt.Errorf("unexpected value of type %T", value)
 	}
 }
 
-func extractValue(currentKey string, value interface{}) interface{} {
-	// Check if the current key ends with [#]
-	if strings.HasSuffix(currentKey, "]") && strings.Contains(currentKey, "[") {
-		tmp := strings.SplitN(currentKey, "[", 3)
-		arrayIndex, err := strconv.Atoi(strings.Replace(tmp[1], "]", "", 1))
-		if err != nil {
-			return nil
-		}
-		currentKey := tmp[0]
-		// if currentKey contains only an index (i.e. [0] or 0)
-		if len(currentKey) == 0 {
-			array := value.([]interface{})
-			if len(array) > arrayIndex {
-				if len(tmp) > 2 {
-					// Nested array? Go deeper.
-					return extractValue(fmt.Sprintf("%s[%s", currentKey, tmp[2]), array[arrayIndex])
-				}
-				return array[arrayIndex]
-			}
-			return nil
-		}
-		if value == nil || value.(map[string]interface{})[currentKey] == nil {
-			return nil
+// marshal returns the JSON representation of a value without escaping HTML characters, which makes comparing the
+// value with a string in a condition more intuitive
+func marshal(value interface{}) (string, error) {
+	if nodes, ok := value.([]interface{}); ok && nodes == nil {
+		return "[]", nil
+	}
+	buffer := new(bytes.Buffer)
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buffer.String(), "\n"), nil
+}
+
+// children returns the elements of an array or the values of the members of an object, sorted by name
+func children(node interface{}) []interface{} {
+	switch node := node.(type) {
+	case []interface{}:
+		return node
+	case map[string]interface{}:
+		names := make([]string, 0, len(node))
+		for name := range node {
+			names = append(names, name)
 		}
-		// if currentKey contains both a key and an index (i.e. data[0])
-		array := value.(map[string]interface{})[currentKey].([]interface{})
-		if len(array) > arrayIndex {
-			if len(tmp) > 2 {
-				// Nested array? Go deeper.
-				return extractValue(fmt.Sprintf("[%s", tmp[2]), array[arrayIndex])
-			}
-			return array[arrayIndex]
+		sort.Strings(names)
+		values := make([]interface{}, len(names))
+		for i, name := range names {
+			values[i] = node[name]
 		}
-		return nil
+		return values
+	}
+	return nil
+}
+
+// descendants returns the node followed by all of its descendants
+func descendants(node interface{}) []interface{} {
+	nodes := []interface{}{node}
+	for _, child := range children(node) {
+		nodes = append(nodes, descendants(child)...)
 	}
-	return value.(map[string]interface{})[currentKey]
+	return nodes
 }
@@ -0,0 +1,30 @@
+//go:build go1.18
+// +build go1.18
+
+package jsonpath
+
+import (
+	"testing"
+)
+
+func FuzzEval(f *testing.F) {
+	f.Add("data.name", `{"data": {"name": "john"}}`)
+	f.Add("$..name", `{"name": "root", "services": [{"name": "api"}]}`)
+	f.Add("services[?(@.status=='up' && @.latency < 100)].name", `{"services": [{"name": "api", "status": "up", "latency": 50}]}`)
+	f.Add("ids[-1:0:-2]", `{"ids": [1, 2, 3, 4]}`)
+	f.Add(`$['a.b', "c"][*]`, `{"a.b": [true, null], "c": {"d": 1.5e3}}`)
+	f.Add("[?!(@ == $[0])]", `[1, "1", [1], {"1": 1}]`)
+	f.Fuzz(func(t *testing.T, path, data string) {
+		output, outputLength, err := Eval(path, []byte(data))
+		if err != nil && (len(output) != 0 || outputLength != 0) {
+			t.Errorf("expected no output when there's an error, got '%s' with a length of %d", output, outputLength)
+		}
+		if outputLength < 0 {
+			t.Errorf("expected the output length to be positive, got %d", outputLength)
+		}
+		secondOutput, secondOutputLength, secondErr := Eval(path, []byte(data))
+		if output != secondOutput || outputLength != secondOutputLength || (err == nil) != (secondErr == nil) {
+			t.Error("expected the evaluation to be deterministic")
+		}
+	})
+}
@@ -22,6 +22,22 @@ func TestEval(t *testing.T) {
 			ExpectedOutputLength: 5,
 			ExpectedError:        false,
 		},
+		{
+			Name:                 "empty-path-with-array",
+			Path:                 "",
+			Data:                 `["93.184.216.34","93.184.216.35"]`,
+			ExpectedOutput:       `["93.184.216.34","93.184.216.35"]`,
+			ExpectedOutputLength: 2,
+			ExpectedError:        false,
+		},
+		{
+			Name:                 "index-of-top-level-array",
+			Path:                 "[1]",
+			Data:                 `["93.184.216.34","93.184.216.35"]`,
+			ExpectedOutput:       "93.184.216.35",
+			ExpectedOutputLength: 13,
+			ExpectedError:        false,
+		},
 		{
 			Name:                 "simple-with-invalid-data",
 			Path:                 "key",
@@ -126,6 +142,214 @@ func TestEval(t *testing.T) {
 			ExpectedOutputLength: 0,
 			ExpectedError:        true,
 		},
+		{
+			Name:                 "root-identifier",
+			Path:                 "$.data.name",
+			Data:                 `{"data": {"name": "john"}}`,
+			ExpectedOutput:       "john",
+			ExpectedOutputLength: 4,
+			ExpectedError:        false,
+		},
+		{
+			Name:                 "leading-dot",
+			Path:                 ".data.name",
+			Data:                 `{"data": {"name": "john"}}`,
+			ExpectedOutput:       "john",
+			ExpectedOutputLength: 4,
+			ExpectedError:        false,
+		},
+		{
+			Name:                 "object",
+			Path:                 "data",
+			Data:                 `{"data": {"name": "<john>", "age": 30}}`,
+			ExpectedOutput:       `{"age":30,"name":"<john>"}`,
+			ExpectedOutputLength: 2,
+			ExpectedError:        false,
+		},
+		{
+			Name:                 "null",
+			Path:                 "data",
+			Data:                 `{"data": null}`,
+			ExpectedOutput:       "null",
+			ExpectedOutputLength: 0,
+			ExpectedError:        false,
+		},
+		{
+			Name:                 "boolean",
+			Path:                 "data.healthy",
+			Data:                 `{"data": {"healthy": true}}`,
+			ExpectedOutput:       "true",
+			ExpectedOutputLength: 1,
+			ExpectedError:        false,
+		},
+		{
+			Name:                 "number-with-decimals",
+			Path:                 "data.uptime",
+			Data:                 `{"data": {"uptime": 99.95}}`,
+			ExpectedOutput:       "99.95",
+			ExpectedOutputLength: 1,
+			ExpectedError:        false,
+		},
+		{
+			Name:                 "quoted-key-with-dots",
+			Path:                 "$['service.status']",
+			Data:                 `{"service.status": "up"}`,
+			ExpectedOutput:       "up",
+			ExpectedOutputLength: 2,
+			ExpectedError:        false,
+		},
+		{
+			Name:                 "double-quoted-key-with-spaces",
+			Path:                 `data["first name"]`,
+			Data:                 `{"data": {"first name": "john"}}`,
+			ExpectedOutput:       "john",
+			ExpectedOutputLength: 4,
+			ExpectedError:        false,
+		},
+		{
+			Name:                 "key-with-dash",
+			Path:                 "data.first-name",
+			Data:                 `{"data": {"first-name": "john"}}`,
+			ExpectedOutput:       "john",
+			ExpectedOutputLength: 4,
+			ExpectedError:        false,
+		},
+		{
+			Name:                 "negative-index",
+			Path:                 "ids[-1]",
+			Data:                 `{"ids": [1, 2, 3]}`,
+			ExpectedOutput:       "3",
+			ExpectedOutputLength: 1,
+			ExpectedError:        false,
+		},
+		{
+			Name:                 "negative-index-out-of-range",
+			Path:                 "ids[-4]",
+			Data:                 `{"ids": [1, 2, 3]}`,
+			ExpectedOutput:       "",
+			ExpectedOutputLength: 0,
+			ExpectedError:        true,
+		},
+		{
+			Name:                 "wildcard",
+			Path:                 "services[*].name",
+			Data:                 `{"services": [{"name": "api"}, {"name": "db"}]}`,
+			ExpectedOutput:       `["api","db"]`,
+			ExpectedOutputLength: 2,
+			ExpectedError:        false,
+		},
+		{
+			Name:                 "wildcard-on-object",
+			Path:                 "$.services.*",
+			Data:                 `{"services": {"db": "down", "api": "up"}}`,
+			ExpectedOutput:       `["up","down"]`,
+			ExpectedOutputLength: 2,
+			ExpectedError:        false,
+		},
+		{
+			Name:                 "recursive-descent",
+			Path:                 "$..name",
+			Data:                 `{"name": "root", "services": [{"name": "api"}, {"dependencies": [{"name": "db"}]}]}`,
+			ExpectedOutput:       `["root","api","db"]`,
+			ExpectedOutputLength: 3,
+			ExpectedError:        false,
+		},
+		{
+			Name:                 "recursive-descent-with-no-match",
+			Path:                 "$..missing",
+			Data:                 `{"name": "root"}`,
+			ExpectedOutput:       "[]",
+			ExpectedOutputLength: 0,
+			ExpectedError:        false,
+		},
+		{
+			Name:                 "slice",
+			Path:                 "ids[1:3]",
+			Data:                 `{"ids": [1, 2, 3, 4]}`,
+			ExpectedOutput:       "[2,3]",
+			ExpectedOutputLength: 2,
+			ExpectedError:        false,
+		},
+		{
+			Name:                 "slice-with-negative-step",
+			Path:                 "ids[::-2]",
+			Data:                 `{"ids": [1, 2, 3, 4]}`,
+			ExpectedOutput:       "[4,2]",
+			ExpectedOutputLength: 2,
+			ExpectedError:        false,
+		},
+		{
+			Name:                 "union",
+			Path:                 "ids[0, -1]",
+			Data:                 `{"ids": [1, 2, 3, 4]}`,
+			ExpectedOutput:       "[1,4]",
+			ExpectedOutputLength: 2,
+			ExpectedError:        false,
+		},
+		{
+			Name:                 "filter",
+			Path:                 "services[?(@.status=='up')].name",
+			Data:                 `{"services": [{"name": "api", "status": "up"}, {"name": "db", "status": "down"}]}`,
+			ExpectedOutput:       `["api"]`,
+			ExpectedOutputLength: 1,
+			ExpectedError:        false,
+		},
+		{
+			Name:                 "filter-without-parentheses",
+			Path:                 `$.services[?@.status != "up"].name`,
+			Data:                 `{"services": [{"name": "api", "status": "up"}, {"name": "db", "status": "down"}]}`,
+			ExpectedOutput:       `["db"]`,
+			ExpectedOutputLength: 1,
+			ExpectedError:        false,
+		},
+		{
+			Name:                 "filter-with-numbers-and-logical-operators",
+			Path:                 "services[?(@.latency < 100 && !(@.status == 'down') || @.critical == false)].name",
+			Data:                 `{"services": [{"name": "api", "latency": 50, "status": "up"}, {"name": "db", "latency": 50, "status": "down", "critical": true}, {"name": "cache", "latency": 500, "critical": false}]}`,
+			ExpectedOutput:       `["api","cache"]`,
+			ExpectedOutputLength: 2,
+			ExpectedError:        false,
+		},
+		{
+			Name:                 "filter-with-existence-test",
+			Path:                 "services[?(@.error)].name",
+			Data:                 `{"services": [{"name": "api"}, {"name": "db", "error": "timeout"}]}`,
+			ExpectedOutput:       `["db"]`,
+			ExpectedOutputLength: 1,
+			ExpectedError:        false,
+		},
+		{
+			Name:                 "filter-with-root-query",
+			Path:                 "services[?(@.version == $.latest)].name",
+			Data:                 `{"latest": 2, "services": [{"name": "api", "version": 2}, {"name": "db", "version": 1}]}`,
+			ExpectedOutput:       `["api"]`,
+			ExpectedOutputLength: 1,
+			ExpectedError:        false,
+		},
+		{
+			Name:                 "filter-with-no-match",
+			Path:                 "services[?(@.status=='unknown')]",
+			Data:                 `{"services": [{"name": "api", "status": "up"}]}`,
+			ExpectedOutput:       "[]",
+			ExpectedOutputLength: 0,
+			ExpectedError:        false,
+		},
+		{
+			Name:                 "unterminated-bracket",
+			Path:                 "ids[0",
+			Data:                 `{"ids": [1, 2]}`,
+			ExpectedOutput:       "",
+			ExpectedOutputLength: 0,
+			ExpectedError:        true,
+		},
+		{
+			Name:                 "invalid-filter",
+			Path:                 "services[?(@.status==)]",
+			Data:                 `{"services": []}`,
+			ExpectedOutput:       "",
+			ExpectedOutputLength: 0,
+			ExpectedError:        true,
+		},
 	}
 	for _, scenario := range scenarios {
 		t.Run(scenario.Name, func(t *testing.T) {
@@ -146,3 +370,70 @@ func TestEval(t *testing.T) {
 		})
 	}
 }
+
+func TestExists(t *testing.T) {
+	type Scenario struct {
+		Name           string
+		Path           string
+		Data           string
+		ExpectedOutput bool
+	}
+	scenarios := []Scenario{
+		{
+			Name:           "existing-key",
+			Path:           "key",
+			Data:           `{"key": "value"}`,
+			ExpectedOutput: true,
+		},
+		{
+			Name:           "missing-key",
+			Path:           "key",
+			Data:           `{"other": "value"}`,
+			ExpectedOutput: false,
+		},
+		{
+			// A key whose value is null is treated as absent, as it was before the support of RFC 9535
+			Name:           "key-with-null-value",
+			Path:           "key",
+			Data:           `{"key": null}`,
+			ExpectedOutput: false,
+		},
+		{
+			Name:           "null-element",
+			Path:           "data[0]",
+			Data:           `{"data": [null]}`,
+			ExpectedOutput: false,
+		},
+		{
+			Name:           "wildcard-with-null-and-non-null-values",
+			Path:           "data[*]",
+			Data:           `{"data": [null, 1]}`,
+			ExpectedOutput: true,
+		},
+		{
+			Name:           "filter-without-match",
+			Path:           "services[?(@.status == 'down')]",
+			Data:           `{"services": [{"status": "up"}]}`,
+			ExpectedOutput: false,
+		},
+		{
+			Name:           "invalid-path",
+			Path:           "ids[0",
+			Data:           `{"ids": [1, 2]}`,
+			ExpectedOutput: false,
+		},
+		{
+			Name:           "invalid-json",
+			Path:           "key",
+			Data:           `{"key": `,
+			ExpectedOutput: false,
+		},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			if output := Exists(scenario.Path, []byte(scenario.Data)); output != scenario.ExpectedOutput {
+				t.Errorf("Expected output to be %v, but was %v", scenario.ExpectedOutput, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// query is a parsed JSONPath expression
+type query struct {
+	segments []*segment
+}
+
+// segment is a part of a query, such as .name, [0, 1] or ..[*]
+type segment struct {
+	// descendant is whether the selectors apply to the node and all of its descendants, rather than to the node only
+	descendant bool
+
+	selectors []selector
+}
+
+// selector selects nodes from the children of a node
+type selector interface {
+	selectFrom(node, root interface{}) []interface{}
+}
+
+// isSingular checks whether the query can only ever select a single node
+func (q *query) isSingular() bool {
+	for _, segment := range q.segments {
+		if segment.descendant || len(segment.selectors) != 1 {
+			return false
+		}
+		switch segment.selectors[0].(type) {
+		case nameSelector, indexSelector:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// evaluate returns the nodes selected by the query, starting from the given node
+func (q *query) evaluate(node, root interface{}) []interface{} {
+	nodes := []interface{}{node}
+	for _, segment := range q.segments {
+		var selected []interface{}
+		for _, node := range nodes {
+			candidates := []interface{}{node}
+			if segment.descendant {
+				candidates = descendants(node)
+			}
+			for _, candidate := range candidates {
+				for _, selector := range segment.selectors {
+					selected = append(selected, selector.selectFrom(candidate, root)...)
+				}
+			}
+		}
+		nodes = selected
+	}
+	return nodes
+}
+
+// parser parses JSONPath expressions
+type parser struct {
+	path     string
+	position int
+}
+
+// parse parses a JSONPath expression starting with the root identifier
+func parse(path string) (*query, error) {
+	p := &parser{path: path}
+	if !p.consume("$") {
+		return nil, p.errorf("expected '$'")
+	}
+	q, err := p.parseSegments()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, p.errorf("unexpected character %q", p.peek())
+	}
+	return q, nil
+}
+
+// parseSegments parses segments until it reaches a character that cannot start a segment
+func (p *parser) parseSegments() (*query, error) {
+	q := &query{}
+	for !p.done() {
+		var s *segment
+		var err error
+		switch {
+		case p.consume(".."):
+			s, err = p.parseChildSegment()
+			if s != nil {
+				s.descendant = true
+			}
+		case p.consume("."):
+			if p.peek() == '[' {
+				return nil, p.errorf("unexpected character '['")
+			}
+			s, err = p.parseChildSegment()
+		case p.peek() == '[':
+			s, err = p.parseChildSegment()
+		default:
+			return q, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		q.segments = append(q.segments, s)
+	}
+	return q, nil
+}
+
+// parseChildSegment parses a wildcard, a member name or a list of selectors between brackets
+func (p *parser) parseChildSegment() (*segment, error) {
+	switch {
+	case p.consume("*"):
+		return &segment{selectors: []selector{wildcardSelector{}}}, nil
+	case p.consume("["):
+		return p.parseBracketedSelectors()
+	}
+	start := p.position
+	for !p.done() && !strings.ContainsRune(".[]()'\" \t\n\r=!<>&|,*?@$", rune(p.peek())) {
+		p.position++
+	}
+	if start == p.position {
+		return nil, p.errorf("expected a member name")
+	}
+	return &segment{selectors: []selector{nameSelector(p.path[start:p.position])}}, nil
+}
+
+// parseBracketedSelectors parses the comma-separated selectors following a '['
+func (p *parser) parseBracketedSelectors() (*segment, error) {
+	s := &segment{}
+	for {
+		p.skipSpaces()
+		sel, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		s.selectors = append(s.selectors, sel)
+		p.skipSpaces()
+		switch {
+		case p.consume(","):
+		case p.consume("]"):
+			return s, nil
+		default:
+			return nil, p.errorf("expected ',' or ']'")
+		}
+	}
+}
+
+// parseSelector parses a single selector between brackets
+func (p *parser) parseSelector() (selector, error) {
+	switch p.peek() {
+	case '\'', '"':
+		name, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return nameSelector(name), nil
+	case '*':
+		p.position++
+		return wildcardSelector{}, nil
+	case '?':
+		p.position++
+		expression, err := p.parseFilter()
+		if err != nil {
+			return nil, err
+		}
+		return filterSelector{expression: expression}, nil
+	}
+	start, hasStart, err := p.parseOptionalInteger()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if !p.consume(":") {
+		if !hasStart {
+			return nil, p.errorf("expected a selector")
+		}
+		return indexSelector(start), nil
+	}
+	slice := sliceSelector{start: start, hasStart: hasStart, step: 1}
+	p.skipSpaces()
+	if slice.end, slice.hasEnd, err = p.parseOptionalInteger(); err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.consume(":") {
+		p.skipSpaces()
+		step, hasStep, err := p.parseOptionalInteger()
+		if err != nil {
+			return nil, err
+		}
+		if hasStep {
+			slice.step = step
+		}
+	}
+	return slice, nil
+}
+
+// parseOptionalInteger parses an integer, if there is one at the current position
+func (p *parser) parseOptionalInteger() (int, bool, error) {
+	start := p.position
+	p.consume("-")
+	for !p.done() && p.peek() >= '0' && p.peek() <= '9' {
+		p.position++
+	}
+	if start == p.position {
+		return 0, false, nil
+	}
+	text := p.path[start:p.position]
+	value, err := strconv.Atoi(text)
+	if err != nil {
+		p.position = start
+		return 0, false, p.errorf("invalid integer %q", text)
+	}
+	return value, true, nil
+}
+
+// parseString parses a string literal delimited by single or double quotes
+func (p *parser) parseString() (string, error) {
+	quote := p.peek()
+	p.position++
+	var builder strings.Builder
+	for !p.done() {
+		c := p.peek()
+		p.position++
+		switch c {
+		case quote:
+			return builder.String(), nil
+		case '\\':
+			if p.done() {
+				return "", p.errorf("unterminated string")
+			}
+			escaped := p.peek()
+			p.position++
+			switch escaped {
+			case 'b':
+				builder.WriteByte('\b')
+			case 'f':
+				builder.WriteByte('\f')
+			case 'n':
+				builder.WriteByte('\n')
+			case 'r':
+				builder.WriteByte('\r')
+			case 't':
+				builder.WriteByte('\t')
+			case 'u':
+				if p.position+4 > len(p.path) {
+					return "", p.errorf("invalid unicode escape sequence")
+				}
+				codePoint, err := strconv.ParseUint(p.path[p.position:p.position+4], 16, 32)
+				if err != nil {
+					return "", p.errorf("invalid unicode escape sequence")
+				}
+				p.position += 4
+				builder.WriteRune(rune(codePoint))
+			default:
+				builder.WriteByte(escaped)
+			}
+		default:
+			builder.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *parser) done() bool {
+	return p.position >= len(p.path)
+}
+
+// peek returns the character at the current position, or 0 if the end of the path has been reached
+func (p *parser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.path[p.position]
+}
+
+// consume advances past the given prefix if the path continues with it
+func (p *parser) consume(prefix string) bool {
+	if strings.HasPrefix(p.path[p.position:], prefix) {
+		p.position += len(prefix)
+		return true
+	}
+	return false
+}
+
+func (p *parser) skipSpaces() {
+	for !p.done() && strings.IndexByte(" \t\n\r", p.peek()) != -1 {
+		p.position++
+	}
+}
+
+func (p *parser) errorf(format string, arguments ...interface{}) error {
+	column := utf8.RuneCountInString(p.path[:p.position]) + 1
+	return fmt.Errorf("invalid path '%s' at position %d: %s", p.path, column, fmt.Sprintf(format, arguments...))
+}
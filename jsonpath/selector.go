@@ -0,0 +1,108 @@
+package jsonpath
+
+// nameSelector selects the value of the member of an object with the given name
+type nameSelector string
+
+func (s nameSelector) selectFrom(node, _ interface{}) []interface{} {
+	if object, ok := node.(map[string]interface{}); ok {
+		if value, exists := object[string(s)]; exists {
+			return []interface{}{value}
+		}
+	}
+	return nil
+}
+
+// wildcardSelector selects all elements of an array or all values of an object
+type wildcardSelector struct{}
+
+func (wildcardSelector) selectFrom(node, _ interface{}) []interface{} {
+	return children(node)
+}
+
+// indexSelector selects the element of an array at the given index, which counts from the end if negative
+type indexSelector int
+
+func (s indexSelector) selectFrom(node, _ interface{}) []interface{} {
+	array, ok := node.([]interface{})
+	if !ok {
+		return nil
+	}
+	index := int(s)
+	if index < 0 {
+		index += len(array)
+	}
+	if index < 0 || index >= len(array) {
+		return nil
+	}
+	return []interface{}{array[index]}
+}
+
+// sliceSelector selects the elements of an array from start (inclusive) to end (exclusive) by step
+type sliceSelector struct {
+	start, end, step int
+	hasStart, hasEnd bool
+}
+
+func (s sliceSelector) selectFrom(node, _ interface{}) []interface{} {
+	array, ok := node.([]interface{})
+	if !ok || s.step == 0 {
+		return nil
+	}
+	length := len(array)
+	var start, end int
+	if s.step > 0 {
+		start, end = 0, length
+	} else {
+		start, end = length-1, -length-1
+	}
+	if s.hasStart {
+		start = s.start
+	}
+	if s.hasEnd {
+		end = s.end
+	}
+	start, end = normalizeIndex(start, length), normalizeIndex(end, length)
+	var selected []interface{}
+	if s.step > 0 {
+		for i := clamp(start, 0, length); i < clamp(end, 0, length); i += s.step {
+			selected = append(selected, array[i])
+		}
+	} else {
+		for i := clamp(start, -1, length-1); i > clamp(end, -1, length-1); i += s.step {
+			selected = append(selected, array[i])
+		}
+	}
+	return selected
+}
+
+// filterSelector selects the elements of an array or the values of an object for which the expression is true
+type filterSelector struct {
+	expression expression
+}
+
+func (s filterSelector) selectFrom(node, root interface{}) []interface{} {
+	var selected []interface{}
+	for _, child := range children(node) {
+		if s.expression.evaluate(child, root) {
+			selected = append(selected, child)
+		}
+	}
+	return selected
+}
+
+func normalizeIndex(index, length int) int {
+	if index < 0 {
+		return index + length
+	}
+	return index
+}
+
+func clamp(value, minimum, maximum int) int {
+	if value < minimum {
+		return minimum
+	}
+	if value > maximum {
+		return maximum
+	}
+	return value
+}
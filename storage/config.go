@@ -1,8 +1,36 @@
 package storage
 
+import (
+	"time"
+
+	"github.com/TwinProduction/gatus/core"
+)
+
 // Config is the configuration for alerting providers
 type Config struct {
+	// Type of store to use
+	// If blank, TypeMemory is used.
+	Type Type `yaml:"type"`
+
 	// File is the path of the file to use for persistence
 	// If blank, persistence is disabled.
+	// Required if Type is TypeBolt.
 	File string `yaml:"file"`
+
+	// MaximumNumberOfResults is the maximum number of results to keep for each service
+	// If 0, defaults to core.MaximumNumberOfResults unless MaximumResultAge is set, in which case there is no limit.
+	MaximumNumberOfResults int `yaml:"maximum-number-of-results"`
+
+	// MaximumResultAge is the maximum age of the results to keep for each service
+	// If 0, results are only discarded based on MaximumNumberOfResults.
+	MaximumResultAge time.Duration `yaml:"maximum-result-age"`
+}
+
+// resultRetention returns the maximum number of results and the maximum age of these results that the store
+// should keep for each service
+func (cfg *Config) resultRetention() (int, time.Duration) {
+	if cfg.MaximumNumberOfResults == 0 && cfg.MaximumResultAge == 0 {
+		return core.MaximumNumberOfResults, 0
+	}
+	return cfg.MaximumNumberOfResults, cfg.MaximumResultAge
 }
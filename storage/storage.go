@@ -2,14 +2,26 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"log"
 	"time"
 
 	"github.com/TwinProduction/gatus/storage/store"
+	"github.com/TwinProduction/gatus/storage/store/bolt"
 	"github.com/TwinProduction/gatus/storage/store/memory"
 )
 
 var (
+	// ErrInvalidStorageType is the error returned when the configured storage type is not supported
+	ErrInvalidStorageType = errors.New("invalid storage type, supported types are memory and bolt")
+
+	// ErrInvalidResultRetention is the error returned when the maximum number of results or the maximum result age
+	// is negative
+	ErrInvalidResultRetention = errors.New("storage.maximum-number-of-results and storage.maximum-result-age cannot be negative")
+
+	// ErrBoltStoreWithNoFile is the error returned when the storage type is bolt, but no file has been specified
+	ErrBoltStoreWithNoFile = errors.New("storage.file must be specified when storage.type is bolt")
+
 	provider store.Store
 
 	// initialized keeps track of whether the storage provider was initialized
@@ -34,23 +46,65 @@ func Get() store.Store {
 }
 
 // Initialize instantiates the storage provider based on the Config provider
+//
+// The previous provider, if any, is only replaced once the new one has been successfully created, so that an invalid
+// configuration doesn't leave the application with a provider that has already been closed.
 func Initialize(cfg *Config) error {
 	initialized = true
-	var err error
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	if cfg.MaximumNumberOfResults < 0 || cfg.MaximumResultAge < 0 {
+		return ErrInvalidResultRetention
+	}
+	maximumNumberOfResults, maximumResultAge := cfg.resultRetention()
+	var newProvider store.Store
+	switch cfg.Type {
+	case TypeBolt:
+		if len(cfg.File) == 0 {
+			return ErrBoltStoreWithNoFile
+		}
+		if boltStore, ok := provider.(*bolt.Store); ok && boltStore.File() == cfg.File {
+			// The file is locked by the previous provider, so it cannot be opened again, but the previous provider
+			// can be reused instead
+			log.Printf("[storage][Initialize] Reusing storage provider with type=%s and file=%s", cfg.Type, cfg.File)
+			newProvider = boltStore.WithResultRetention(maximumNumberOfResults, maximumResultAge)
+		} else {
+			log.Printf("[storage][Initialize] Creating storage provider with type=%s and file=%s", cfg.Type, cfg.File)
+			boltStore, err := bolt.NewStore(cfg.File)
+			if err != nil {
+				return err
+			}
+			newProvider = boltStore.WithResultRetention(maximumNumberOfResults, maximumResultAge)
+		}
+	case TypeMemory, "":
+		if len(cfg.File) == 0 {
+			log.Println("[storage][Initialize] Creating storage provider")
+			memoryStore, _ := memory.NewStore("")
+			newProvider = memoryStore.WithResultRetention(maximumNumberOfResults, maximumResultAge)
+		} else {
+			log.Printf("[storage][Initialize] Creating storage provider with file=%s", cfg.File)
+			memoryStore, err := memory.NewStore(cfg.File)
+			if err != nil {
+				return err
+			}
+			newProvider = memoryStore.WithResultRetention(maximumNumberOfResults, maximumResultAge)
+		}
+	default:
+		return ErrInvalidStorageType
+	}
 	if cancelFunc != nil {
 		// Stop the active autoSave task
 		cancelFunc()
+		cancelFunc = nil
+	}
+	if provider != nil && provider != newProvider {
+		// Release the resources held by the previous provider, such as the lock on its file
+		provider.Close()
 	}
-	if cfg == nil || len(cfg.File) == 0 {
-		log.Println("[storage][Initialize] Creating storage provider")
-		provider, _ = memory.NewStore("")
-	} else {
+	provider = newProvider
+	if cfg.Type != TypeBolt && len(cfg.File) > 0 {
 		ctx, cancelFunc = context.WithCancel(context.Background())
-		log.Printf("[storage][Initialize] Creating storage provider with file=%s", cfg.File)
-		provider, err = memory.NewStore(cfg.File)
-		if err != nil {
-			return err
-		}
 		go autoSave(7*time.Minute, ctx)
 	}
 	return nil
@@ -3,6 +3,8 @@ package storage
 import (
 	"testing"
 	"time"
+
+	"github.com/TwinProduction/gatus/core"
 )
 
 func TestInitialize(t *testing.T) {
@@ -35,3 +37,70 @@ func TestAutoSave(t *testing.T) {
 	cancelFunc()
 	time.Sleep(5 * time.Millisecond)
 }
+
+func TestInitializeWithBolt(t *testing.T) {
+	file := t.TempDir() + "/test.db"
+	if err := Initialize(&Config{Type: TypeBolt, File: file}); err != nil {
+		t.Fatal("shouldn't have returned an error, got", err.Error())
+	}
+	// Initializing it again must not be blocked by the lock the previous provider had on the file
+	if err := Initialize(&Config{Type: TypeBolt, File: file}); err != nil {
+		t.Fatal("shouldn't have returned an error, got", err.Error())
+	}
+	Get().Close()
+	provider = nil
+}
+
+func TestInitializeWithInvalidConfig(t *testing.T) {
+	if err := Initialize(&Config{Type: TypeBolt}); err != ErrBoltStoreWithNoFile {
+		t.Error("expected ErrBoltStoreWithNoFile, got", err)
+	}
+	if err := Initialize(&Config{Type: "invalid"}); err != ErrInvalidStorageType {
+		t.Error("expected ErrInvalidStorageType, got", err)
+	}
+	if err := Initialize(&Config{MaximumNumberOfResults: -1}); err != ErrInvalidResultRetention {
+		t.Error("expected ErrInvalidResultRetention, got", err)
+	}
+	provider = nil
+}
+
+func TestInitializeWithInvalidConfigKeepsPreviousProvider(t *testing.T) {
+	file := t.TempDir() + "/test.db"
+	if err := Initialize(&Config{Type: TypeBolt, File: file}); err != nil {
+		t.Fatal("shouldn't have returned an error, got", err.Error())
+	}
+	previousProvider := Get()
+	for _, cfg := range []*Config{
+		{Type: "invalid"},
+		{Type: TypeBolt},
+		{Type: TypeBolt, File: t.TempDir() + "/nonexistent/test.db"},
+		{Type: TypeBolt, File: file, MaximumNumberOfResults: -1},
+	} {
+		if err := Initialize(cfg); err == nil {
+			t.Errorf("expected an error for config %+v", cfg)
+		}
+		if Get() != previousProvider {
+			t.Fatalf("expected the previous provider to have been kept for config %+v", cfg)
+		}
+	}
+	// The previous provider must still be usable, which wouldn't be the case if it had been closed
+	service := &core.Service{Name: "name", Group: "group"}
+	Get().Insert(service, &core.Result{Success: true, Timestamp: time.Now()})
+	if serviceStatus := Get().GetServiceStatus(service.Group, service.Name); serviceStatus == nil || len(serviceStatus.Results) != 1 {
+		t.Error("expected the result to have been inserted in the previous provider")
+	}
+	Get().Close()
+	provider = nil
+}
+
+func TestConfig_resultRetention(t *testing.T) {
+	if maximumNumberOfResults, maximumResultAge := (&Config{}).resultRetention(); maximumNumberOfResults != core.MaximumNumberOfResults || maximumResultAge != 0 {
+		t.Errorf("expected the default retention to be %d results with no maximum age, got %d results and %s", core.MaximumNumberOfResults, maximumNumberOfResults, maximumResultAge)
+	}
+	if maximumNumberOfResults, maximumResultAge := (&Config{MaximumResultAge: 720 * time.Hour}).resultRetention(); maximumNumberOfResults != 0 || maximumResultAge != 720*time.Hour {
+		t.Errorf("expected the retention to be no limit on the number of results with a maximum age of 720h, got %d results and %s", maximumNumberOfResults, maximumResultAge)
+	}
+	if maximumNumberOfResults, maximumResultAge := (&Config{MaximumNumberOfResults: 5000, MaximumResultAge: 720 * time.Hour}).resultRetention(); maximumNumberOfResults != 5000 || maximumResultAge != 720*time.Hour {
+		t.Errorf("expected the retention to be 5000 results with a maximum age of 720h, got %d results and %s", maximumNumberOfResults, maximumResultAge)
+	}
+}
@@ -0,0 +1,494 @@
+package bolt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"log"
+	"time"
+
+	"github.com/TwinProduction/gatus/core"
+	"github.com/TwinProduction/gatus/util"
+	bbolt "go.etcd.io/bbolt"
+)
+
+var (
+	// servicesBucketName is the name of the root bucket, which contains one nested bucket per service key
+	servicesBucketName = []byte("services")
+
+	// resultsBucketName is the name of the bucket, nested in a service bucket, that contains the service's results
+	resultsBucketName = []byte("results")
+
+	// eventsBucketName is the name of the bucket, nested in a service bucket, that contains the service's events
+	eventsBucketName = []byte("events")
+
+	// uptimeBucketName is the name of the bucket, nested in a service bucket, that contains the service's
+	// hourly uptime statistics
+	uptimeBucketName = []byte("uptime")
+
+	nameKey          = []byte("name")
+	groupKey         = []byte("group")
+	uptimeSummaryKey = []byte("uptime-summary")
+)
+
+// Store that leverages bbolt
+//
+// Unlike memory.Store, every call to Insert is persisted to the file as part of a single transaction, which means that
+// no data is lost if the application crashes.
+type Store struct {
+	file string
+	db   *bbolt.DB
+
+	maximumNumberOfResults int
+	maximumResultAge       time.Duration
+}
+
+// NewStore creates a new store
+func NewStore(file string) (*Store, error) {
+	db, err := bbolt.Open(file, 0600, &bbolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(servicesBucketName)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &Store{file: file, db: db, maximumNumberOfResults: core.MaximumNumberOfResults}, nil
+}
+
+// WithResultRetention sets the maximum number of results to keep for each service as well as the maximum age of
+// these results. A value of 0 means that there's no limit.
+func (s *Store) WithResultRetention(maximumNumberOfResults int, maximumResultAge time.Duration) *Store {
+	s.maximumNumberOfResults = maximumNumberOfResults
+	s.maximumResultAge = maximumResultAge
+	return s
+}
+
+// File returns the path of the file in which the store persists its data
+func (s *Store) File() string {
+	return s.file
+}
+
+// GetAllServiceStatusesWithResultPagination returns all monitored core.ServiceStatus
+// with a subset of core.Result defined by the page and pageSize parameters
+func (s *Store) GetAllServiceStatusesWithResultPagination(page, pageSize int) map[string]*core.ServiceStatus {
+	pagedServiceStatuses := make(map[string]*core.ServiceStatus)
+	_ = s.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(servicesBucketName).ForEach(func(key, _ []byte) error {
+			// Only the results within the requested page are read, because depending on the result retention,
+			// a service may have a lot of results
+			serviceStatus, err := readServiceStatus(tx.Bucket(servicesBucketName).Bucket(key), string(key), page, pageSize)
+			if err != nil {
+				return err
+			}
+			pagedServiceStatuses[string(key)] = serviceStatus
+			return nil
+		})
+	})
+	return pagedServiceStatuses
+}
+
+// GetServiceStatus returns the service status for a given service name in the given group
+func (s *Store) GetServiceStatus(groupName, serviceName string) *core.ServiceStatus {
+	return s.GetServiceStatusByKey(util.ConvertGroupAndServiceToKey(groupName, serviceName))
+}
+
+// GetServiceStatusByKey returns the service status for a given key
+//
+// Only the core.MaximumNumberOfResults most recent results are read, because depending on the result retention,
+// a service may have a lot of results. Use GetServiceStatusByKeyWithResultPagination to read the other results.
+func (s *Store) GetServiceStatusByKey(key string) *core.ServiceStatus {
+	return s.GetServiceStatusByKeyWithResultPagination(key, 1, core.MaximumNumberOfResults)
+}
+
+// GetServiceStatusByKeyWithResultPagination returns the service status for a given key with a subset of
+// core.Result defined by the page and pageSize parameters
+func (s *Store) GetServiceStatusByKeyWithResultPagination(key string, page, pageSize int) *core.ServiceStatus {
+	var serviceStatus *core.ServiceStatus
+	_ = s.db.View(func(tx *bbolt.Tx) error {
+		serviceBucket := tx.Bucket(servicesBucketName).Bucket([]byte(key))
+		if serviceBucket == nil {
+			return nil
+		}
+		var err error
+		serviceStatus, err = readServiceStatus(serviceBucket, key, page, pageSize)
+		return err
+	})
+	return serviceStatus
+}
+
+// GetServiceStatusResultsBetween returns the results of the service with the given key that have a timestamp
+// between from and to, inclusively
+func (s *Store) GetServiceStatusResultsBetween(key string, from, to time.Time) []*core.Result {
+	var results []*core.Result
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		serviceBucket := tx.Bucket(servicesBucketName).Bucket([]byte(key))
+		if serviceBucket == nil {
+			return nil
+		}
+		results = make([]*core.Result, 0)
+		// Results are stored in the order in which they were inserted, so we can stop iterating as soon as we
+		// reach a result that is older than the beginning of the requested range
+		cursor := serviceBucket.Bucket(resultsBucketName).Cursor()
+		for k, value := cursor.Last(); k != nil; k, value = cursor.Prev() {
+			result := &core.Result{}
+			if err := decode(value, result); err != nil {
+				return err
+			}
+			if result.Timestamp.Before(from) {
+				break
+			}
+			if !result.Timestamp.After(to) {
+				results = append([]*core.Result{result}, results...)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("[bolt][GetServiceStatusResultsBetween] Failed to read results for key=%s: %s", key, err.Error())
+		return nil
+	}
+	return results
+}
+
+// Insert adds the observed result for the specified service into the store
+func (s *Store) Insert(service *core.Service, result *core.Result) {
+	key := util.ConvertGroupAndServiceToKey(service.Group, service.Name)
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		serviceBucket, err := getOrCreateServiceBucket(tx, key, service)
+		if err != nil {
+			return err
+		}
+		resultsBucket := serviceBucket.Bucket(resultsBucketName)
+		eventsBucket := serviceBucket.Bucket(eventsBucketName)
+		// Check if there's any change since the last result
+		// OR there's only 1 event, which only happens when there's a start event
+		if _, lastResultValue := resultsBucket.Cursor().Last(); lastResultValue != nil {
+			lastResult := &core.Result{}
+			if err := decode(lastResultValue, lastResult); err != nil {
+				return err
+			}
+			if lastResult.Success != result.Success || countEntries(eventsBucket) == 1 {
+				event := &core.Event{Timestamp: result.Timestamp}
+				if result.Success {
+					event.Type = core.EventHealthy
+				} else {
+					event.Type = core.EventUnhealthy
+				}
+				if err := appendAndTruncate(eventsBucket, event, core.MaximumNumberOfEvents); err != nil {
+					return err
+				}
+			}
+		}
+		if err := appendAndTruncate(resultsBucket, result, s.maximumNumberOfResults); err != nil {
+			return err
+		}
+		if s.maximumResultAge > 0 {
+			if err := deleteResultsOlderThan(resultsBucket, time.Now().Add(-s.maximumResultAge)); err != nil {
+				return err
+			}
+		}
+		return processUptime(serviceBucket, result)
+	})
+	if err != nil {
+		log.Printf("[bolt][Insert] Failed to insert result for key=%s: %s", key, err.Error())
+	}
+}
+
+// DeleteAllServiceStatusesNotInKeys removes all ServiceStatus that are not within the keys provided
+func (s *Store) DeleteAllServiceStatusesNotInKeys(keys []string) int {
+	var numberOfDeletedServiceStatuses int
+	_ = s.db.Update(func(tx *bbolt.Tx) error {
+		servicesBucket := tx.Bucket(servicesBucketName)
+		var keysToDelete [][]byte
+		_ = servicesBucket.ForEach(func(existingKey, _ []byte) error {
+			for _, key := range keys {
+				if string(existingKey) == key {
+					return nil
+				}
+			}
+			// The key is only valid for the life of the transaction and must not be modified, so we'll copy it
+			keysToDelete = append(keysToDelete, append([]byte{}, existingKey...))
+			return nil
+		})
+		for _, key := range keysToDelete {
+			if err := servicesBucket.DeleteBucket(key); err != nil {
+				return err
+			}
+			numberOfDeletedServiceStatuses++
+		}
+		return nil
+	})
+	return numberOfDeletedServiceStatuses
+}
+
+// Clear deletes everything from the store
+func (s *Store) Clear() {
+	_ = s.db.Update(func(tx *bbolt.Tx) error {
+		if err := tx.DeleteBucket(servicesBucketName); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucket(servicesBucketName)
+		return err
+	})
+}
+
+// Save persists the data if and where it needs to be persisted
+//
+// Because every Insert is already persisted, this only forces the file to be synced to disk.
+func (s *Store) Save() error {
+	return s.db.Sync()
+}
+
+// Close closes the underlying database
+func (s *Store) Close() {
+	_ = s.db.Close()
+}
+
+// getOrCreateServiceBucket returns the bucket of a service, and creates it along with its nested buckets if it
+// doesn't exist yet
+func getOrCreateServiceBucket(tx *bbolt.Tx, key string, service *core.Service) (*bbolt.Bucket, error) {
+	servicesBucket := tx.Bucket(servicesBucketName)
+	if serviceBucket := servicesBucket.Bucket([]byte(key)); serviceBucket != nil {
+		return serviceBucket, nil
+	}
+	serviceBucket, err := servicesBucket.CreateBucket([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+	if err = serviceBucket.Put(nameKey, []byte(service.Name)); err != nil {
+		return nil, err
+	}
+	if err = serviceBucket.Put(groupKey, []byte(service.Group)); err != nil {
+		return nil, err
+	}
+	for _, bucketName := range [][]byte{resultsBucketName, eventsBucketName, uptimeBucketName} {
+		if _, err = serviceBucket.CreateBucket(bucketName); err != nil {
+			return nil, err
+		}
+	}
+	if err = appendAndTruncate(serviceBucket.Bucket(eventsBucketName), &core.Event{Type: core.EventStart, Timestamp: time.Now()}, core.MaximumNumberOfEvents); err != nil {
+		return nil, err
+	}
+	return serviceBucket, nil
+}
+
+// appendAndTruncate appends a value to a bucket using the bucket's sequence as key, and then deletes the oldest
+// entries until the bucket has no more than maximumNumberOfEntries entries.
+//
+// A maximumNumberOfEntries of 0 means that there's no limit.
+func appendAndTruncate(bucket *bbolt.Bucket, value interface{}, maximumNumberOfEntries int) error {
+	sequence, err := bucket.NextSequence()
+	if err != nil {
+		return err
+	}
+	encodedValue, err := encode(value)
+	if err != nil {
+		return err
+	}
+	if err = bucket.Put(uint64ToBytes(sequence), encodedValue); err != nil {
+		return err
+	}
+	if maximumNumberOfEntries <= 0 {
+		return nil
+	}
+	numberOfEntriesToDelete := countEntries(bucket) - maximumNumberOfEntries
+	if numberOfEntriesToDelete > 0 {
+		cursor := bucket.Cursor()
+		for key, _ := cursor.First(); key != nil && numberOfEntriesToDelete > 0; key, _ = cursor.First() {
+			if err = cursor.Delete(); err != nil {
+				return err
+			}
+			numberOfEntriesToDelete--
+		}
+	}
+	return nil
+}
+
+// deleteResultsOlderThan deletes the oldest results of a results bucket until it reaches a result that isn't older
+// than oldestTimestampAllowed
+func deleteResultsOlderThan(resultsBucket *bbolt.Bucket, oldestTimestampAllowed time.Time) error {
+	cursor := resultsBucket.Cursor()
+	for key, value := cursor.First(); key != nil; key, value = cursor.First() {
+		result := &core.Result{}
+		if err := decode(value, result); err != nil {
+			return err
+		}
+		if !result.Timestamp.Before(oldestTimestampAllowed) {
+			break
+		}
+		if err := cursor.Delete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// processUptime loads the uptime of a service, lets core.Uptime process the result, and then persists the hourly
+// statistics that changed as well as the recalculated uptime
+func processUptime(serviceBucket *bbolt.Bucket, result *core.Result) error {
+	uptime, err := readUptime(serviceBucket)
+	if err != nil {
+		return err
+	}
+	uptime.ProcessResult(result)
+	uptimeBucket := serviceBucket.Bucket(uptimeBucketName)
+	// Delete the hourly statistics that have been cleaned up by core.Uptime
+	var keysToDelete [][]byte
+	_ = uptimeBucket.ForEach(func(key, _ []byte) error {
+		if _, exists := uptime.HourlyStatistics[int64(binary.BigEndian.Uint64(key))]; !exists {
+			keysToDelete = append(keysToDelete, append([]byte{}, key...))
+		}
+		return nil
+	})
+	for _, key := range keysToDelete {
+		if err = uptimeBucket.Delete(key); err != nil {
+			return err
+		}
+	}
+	unixTimestampFlooredAtHour := result.Timestamp.Unix() - (result.Timestamp.Unix() % 3600)
+	if hourlyStats, exists := uptime.HourlyStatistics[unixTimestampFlooredAtHour]; exists {
+		encodedHourlyStats, err := encode(hourlyStats)
+		if err != nil {
+			return err
+		}
+		if err = uptimeBucket.Put(uint64ToBytes(uint64(unixTimestampFlooredAtHour)), encodedHourlyStats); err != nil {
+			return err
+		}
+	}
+	// The hourly statistics are already persisted in their own bucket, so there's no need to persist them twice
+	uptime.HourlyStatistics = nil
+	encodedUptime, err := encode(uptime)
+	if err != nil {
+		return err
+	}
+	return serviceBucket.Put(uptimeSummaryKey, encodedUptime)
+}
+
+// readServiceStatus reads the service status from a service bucket
+//
+// If page and pageSize are both 0, all results are read. Otherwise, only the results within the page are read, using
+// the same semantics as core.ServiceStatus.WithResultPagination.
+func readServiceStatus(serviceBucket *bbolt.Bucket, key string, page, pageSize int) (*core.ServiceStatus, error) {
+	serviceStatus := &core.ServiceStatus{
+		Name:    string(serviceBucket.Get(nameKey)),
+		Group:   string(serviceBucket.Get(groupKey)),
+		Key:     key,
+		Results: make([]*core.Result, 0),
+		Events:  make([]*core.Event, 0),
+	}
+	var err error
+	if serviceStatus.Results, err = readResults(serviceBucket.Bucket(resultsBucketName), page, pageSize); err != nil {
+		return nil, err
+	}
+	err = serviceBucket.Bucket(eventsBucketName).ForEach(func(_, value []byte) error {
+		event := &core.Event{}
+		if err := decode(value, event); err != nil {
+			return err
+		}
+		serviceStatus.Events = append(serviceStatus.Events, event)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if serviceStatus.Uptime, err = readUptime(serviceBucket); err != nil {
+		return nil, err
+	}
+	return serviceStatus, nil
+}
+
+func readResults(resultsBucket *bbolt.Bucket, page, pageSize int) ([]*core.Result, error) {
+	results := make([]*core.Result, 0)
+	if page == 0 && pageSize == 0 {
+		err := resultsBucket.ForEach(func(_, value []byte) error {
+			result := &core.Result{}
+			if err := decode(value, result); err != nil {
+				return err
+			}
+			results = append(results, result)
+			return nil
+		})
+		return results, err
+	}
+	if page < 1 || pageSize < 1 {
+		return results, nil
+	}
+	// The first page contains the most recent results, so we'll iterate backward from the last result
+	numberOfResultsToSkip := (page - 1) * pageSize
+	cursor := resultsBucket.Cursor()
+	for key, value := cursor.Last(); key != nil && len(results) < pageSize; key, value = cursor.Prev() {
+		if numberOfResultsToSkip > 0 {
+			numberOfResultsToSkip--
+			continue
+		}
+		result := &core.Result{}
+		if err := decode(value, result); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	// Results must be in chronological order
+	for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+		results[i], results[j] = results[j], results[i]
+	}
+	return results, nil
+}
+
+func readUptime(serviceBucket *bbolt.Bucket) (*core.Uptime, error) {
+	uptime := core.NewUptime()
+	if encodedUptime := serviceBucket.Get(uptimeSummaryKey); encodedUptime != nil {
+		if err := decode(encodedUptime, uptime); err != nil {
+			return nil, err
+		}
+		uptime.HourlyStatistics = make(map[int64]*core.HourlyUptimeStatistics)
+	}
+	err := serviceBucket.Bucket(uptimeBucketName).ForEach(func(key, value []byte) error {
+		hourlyStats := &core.HourlyUptimeStatistics{}
+		if err := decode(value, hourlyStats); err != nil {
+			return err
+		}
+		uptime.HourlyStatistics[int64(binary.BigEndian.Uint64(key))] = hourlyStats
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return uptime, nil
+}
+
+// countEntries returns the number of entries in a bucket populated by appendAndTruncate
+//
+// Because the keys are consecutive sequence numbers and entries are only ever deleted starting from the oldest one,
+// the number of entries can be computed from the first key and the bucket's sequence, without iterating over the
+// bucket. Note that bbolt.Bucket.Stats cannot be used for this, because it doesn't take into account the changes made
+// by the current transaction.
+func countEntries(bucket *bbolt.Bucket) int {
+	firstKey, _ := bucket.Cursor().First()
+	if firstKey == nil {
+		return 0
+	}
+	return int(bucket.Sequence() - binary.BigEndian.Uint64(firstKey) + 1)
+}
+
+func encode(value interface{}) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	if err := gob.NewEncoder(buffer).Encode(value); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func decode(data []byte, value interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(value)
+}
+
+func uint64ToBytes(value uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, value)
+	return b
+}
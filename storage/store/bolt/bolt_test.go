@@ -0,0 +1,393 @@
+package bolt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TwinProduction/gatus/core"
+	"github.com/TwinProduction/gatus/util"
+)
+
+var (
+	firstCondition  = core.Condition("[STATUS] == 200")
+	secondCondition = core.Condition("[RESPONSE_TIME] < 500")
+	thirdCondition  = core.Condition("[CERTIFICATE_EXPIRATION] < 72h")
+
+	timestamp = time.Now()
+
+	testService = core.Service{
+		Name:                    "name",
+		Group:                   "group",
+		URL:                     "https://example.org/what/ever",
+		Method:                  "GET",
+		Body:                    "body",
+		Interval:                30 * time.Second,
+		Conditions:              []*core.Condition{&firstCondition, &secondCondition, &thirdCondition},
+		Alerts:                  nil,
+		Insecure:                false,
+		NumberOfFailuresInARow:  0,
+		NumberOfSuccessesInARow: 0,
+	}
+	testSuccessfulResult = core.Result{
+		Hostname:              "example.org",
+		IP:                    "127.0.0.1",
+		HTTPStatus:            200,
+		Errors:                nil,
+		Connected:             true,
+		Success:               true,
+		Timestamp:             timestamp,
+		Duration:              150 * time.Millisecond,
+		CertificateExpiration: 10 * time.Hour,
+		ConditionResults: []*core.ConditionResult{
+			{
+				Condition: "[STATUS] == 200",
+				Success:   true,
+			},
+			{
+				Condition: "[RESPONSE_TIME] < 500",
+				Success:   true,
+			},
+			{
+				Condition: "[CERTIFICATE_EXPIRATION] < 72h",
+				Success:   true,
+			},
+		},
+	}
+	testUnsuccessfulResult = core.Result{
+		Hostname:              "example.org",
+		IP:                    "127.0.0.1",
+		HTTPStatus:            200,
+		Errors:                []string{"error-1", "error-2"},
+		Connected:             true,
+		Success:               false,
+		Timestamp:             timestamp,
+		Duration:              750 * time.Millisecond,
+		CertificateExpiration: 10 * time.Hour,
+		ConditionResults: []*core.ConditionResult{
+			{
+				Condition: "[STATUS] == 200",
+				Success:   true,
+			},
+			{
+				Condition: "[RESPONSE_TIME] < 500",
+				Success:   false,
+			},
+			{
+				Condition: "[CERTIFICATE_EXPIRATION] < 72h",
+				Success:   false,
+			},
+		},
+	}
+)
+
+func TestStore_Insert(t *testing.T) {
+	store, err := NewStore(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	defer store.Close()
+	store.Insert(&testService, &testSuccessfulResult)
+	store.Insert(&testService, &testUnsuccessfulResult)
+
+	serviceStatus := store.GetServiceStatus(testService.Group, testService.Name)
+	if serviceStatus == nil {
+		t.Fatalf("Store should've had key '%s', but didn't", util.ConvertGroupAndServiceToKey(testService.Group, testService.Name))
+	}
+	if serviceStatus.Name != testService.Name || serviceStatus.Group != testService.Group {
+		t.Errorf("expected name=%s and group=%s, got name=%s and group=%s", testService.Name, testService.Group, serviceStatus.Name, serviceStatus.Group)
+	}
+	if len(serviceStatus.Results) != 2 {
+		t.Fatalf("Service '%s' should've had 2 results, but actually returned %d", serviceStatus.Name, len(serviceStatus.Results))
+	}
+	for i, expectedResult := range []*core.Result{&testSuccessfulResult, &testUnsuccessfulResult} {
+		r := serviceStatus.Results[i]
+		if r.HTTPStatus != expectedResult.HTTPStatus {
+			t.Errorf("Result at index %d should've had a HTTPStatus of %d, but was actually %d", i, expectedResult.HTTPStatus, r.HTTPStatus)
+		}
+		if r.IP != expectedResult.IP {
+			t.Errorf("Result at index %d should've had a IP of %s, but was actually %s", i, expectedResult.IP, r.IP)
+		}
+		if r.Duration != expectedResult.Duration {
+			t.Errorf("Result at index %d should've had a Duration of %s, but was actually %s", i, expectedResult.Duration.String(), r.Duration.String())
+		}
+		if len(r.Errors) != len(expectedResult.Errors) {
+			t.Errorf("Result at index %d should've had %d errors, but actually had %d errors", i, len(expectedResult.Errors), len(r.Errors))
+		}
+		if len(r.ConditionResults) != len(expectedResult.ConditionResults) {
+			t.Errorf("Result at index %d should've had %d ConditionResults, but actually had %d ConditionResults", i, len(expectedResult.ConditionResults), len(r.ConditionResults))
+		}
+		if r.Success != expectedResult.Success {
+			t.Errorf("Result at index %d should've had a Success of %t, but was actually %t", i, expectedResult.Success, r.Success)
+		}
+		if !r.Timestamp.Equal(expectedResult.Timestamp) {
+			t.Errorf("Result at index %d should've had a Timestamp of %s, but was actually %s", i, expectedResult.Timestamp.String(), r.Timestamp.String())
+		}
+	}
+	if len(serviceStatus.Events) != 2 {
+		t.Errorf("expected 2 events (start and unhealthy), got %d", len(serviceStatus.Events))
+	}
+	if serviceStatus.Uptime.LastHour != 0.5 {
+		t.Errorf("serviceStatus.Uptime.LastHour should've been 0.5, got %f", serviceStatus.Uptime.LastHour)
+	}
+	if serviceStatus.Uptime.LastTwentyFourHours != 0.5 {
+		t.Errorf("serviceStatus.Uptime.LastTwentyFourHours should've been 0.5, got %f", serviceStatus.Uptime.LastTwentyFourHours)
+	}
+	if serviceStatus.Uptime.LastSevenDays != 0.5 {
+		t.Errorf("serviceStatus.Uptime.LastSevenDays should've been 0.5, got %f", serviceStatus.Uptime.LastSevenDays)
+	}
+	if len(serviceStatus.Uptime.HourlyStatistics) != 1 {
+		t.Errorf("expected 1 hourly statistics entry, got %d", len(serviceStatus.Uptime.HourlyStatistics))
+	}
+}
+
+func TestStore_InsertTruncatesResultsAndEvents(t *testing.T) {
+	store, err := NewStore(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	defer store.Close()
+	for i := 0; i < core.MaximumNumberOfResults+core.MaximumNumberOfEvents; i++ {
+		if i%2 == 0 {
+			store.Insert(&testService, &testSuccessfulResult)
+		} else {
+			store.Insert(&testService, &testUnsuccessfulResult)
+		}
+	}
+	serviceStatus := store.GetServiceStatus(testService.Group, testService.Name)
+	if len(serviceStatus.Results) != core.MaximumNumberOfResults {
+		t.Errorf("expected %d results, got %d", core.MaximumNumberOfResults, len(serviceStatus.Results))
+	}
+	if len(serviceStatus.Events) != core.MaximumNumberOfEvents {
+		t.Errorf("expected %d events, got %d", core.MaximumNumberOfEvents, len(serviceStatus.Events))
+	}
+	if serviceStatus.Results[len(serviceStatus.Results)-1].Success {
+		t.Error("expected the last result to be the last result inserted")
+	}
+}
+
+func TestStore_InsertWithResultRetention(t *testing.T) {
+	store, err := NewStore(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	defer store.Close()
+	store.WithResultRetention(0, 0)
+	for i := 0; i < core.MaximumNumberOfResults*2; i++ {
+		store.Insert(&testService, &core.Result{Timestamp: time.Now()})
+	}
+	key := util.ConvertGroupAndServiceToKey(testService.Group, testService.Name)
+	if serviceStatus := store.GetServiceStatusByKeyWithResultPagination(key, 1, core.MaximumNumberOfResults*3); len(serviceStatus.Results) != core.MaximumNumberOfResults*2 {
+		t.Errorf("expected all %d results to have been kept, got %d", core.MaximumNumberOfResults*2, len(serviceStatus.Results))
+	}
+	// Only the most recent results are read when no pagination is specified
+	if serviceStatus := store.GetServiceStatusByKey(key); len(serviceStatus.Results) != core.MaximumNumberOfResults {
+		t.Errorf("expected only the %d most recent results to have been read, got %d", core.MaximumNumberOfResults, len(serviceStatus.Results))
+	}
+	store.Clear()
+	store.WithResultRetention(0, 5*time.Hour+30*time.Minute)
+	now := time.Now()
+	for i := 10; i > 0; i-- {
+		store.Insert(&testService, &core.Result{Timestamp: now.Add(-time.Duration(i) * time.Hour)})
+	}
+	if serviceStatus := store.GetServiceStatus(testService.Group, testService.Name); len(serviceStatus.Results) != 5 {
+		t.Errorf("expected only the 5 results from the last 5h30m to have been kept, got %d", len(serviceStatus.Results))
+	}
+}
+
+func TestStore_InsertTruncatesResultsAfterDeletingOldResults(t *testing.T) {
+	store, err := NewStore(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	defer store.Close()
+	store.WithResultRetention(0, 5*time.Hour+30*time.Minute)
+	now := time.Now()
+	for i := 10; i > 0; i-- {
+		store.Insert(&testService, &core.Result{Timestamp: now.Add(-time.Duration(i) * time.Hour)})
+	}
+	// The number of results must still be accurate after the oldest results have been deleted because of their age
+	store.WithResultRetention(3, 0)
+	store.Insert(&testService, &core.Result{Timestamp: now})
+	serviceStatus := store.GetServiceStatus(testService.Group, testService.Name)
+	if len(serviceStatus.Results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(serviceStatus.Results))
+	}
+	if !serviceStatus.Results[2].Timestamp.Equal(now) || !serviceStatus.Results[0].Timestamp.Equal(now.Add(-2*time.Hour)) {
+		t.Error("expected the 3 most recent results to have been kept")
+	}
+}
+
+func TestStore_GetServiceStatusResultsBetween(t *testing.T) {
+	store, err := NewStore(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	defer store.Close()
+	now := time.Now()
+	for i := 10; i > 0; i-- {
+		store.Insert(&testService, &core.Result{Timestamp: now.Add(-time.Duration(i) * time.Hour)})
+	}
+	key := util.ConvertGroupAndServiceToKey(testService.Group, testService.Name)
+	results := store.GetServiceStatusResultsBetween(key, now.Add(-5*time.Hour), now.Add(-3*time.Hour))
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if !results[0].Timestamp.Before(results[2].Timestamp) {
+		t.Error("expected results to be in chronological order")
+	}
+	if results := store.GetServiceStatusResultsBetween(key, now.Add(-20*time.Hour), now.Add(-15*time.Hour)); results == nil || len(results) != 0 {
+		t.Errorf("expected no results, got %d", len(results))
+	}
+	if results := store.GetServiceStatusResultsBetween("invalid_key", now.Add(-5*time.Hour), now); results != nil {
+		t.Error("expected nil, because the service doesn't exist")
+	}
+}
+
+func TestStore_PersistsAcrossRestarts(t *testing.T) {
+	file := t.TempDir() + "/test.db"
+	store, err := NewStore(file)
+	if err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	store.Insert(&testService, &testSuccessfulResult)
+	store.Close()
+	store, err = NewStore(file)
+	if err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	defer store.Close()
+	serviceStatus := store.GetServiceStatus(testService.Group, testService.Name)
+	if serviceStatus == nil {
+		t.Fatal("expected service status to have been persisted")
+	}
+	if len(serviceStatus.Results) != 1 {
+		t.Errorf("expected 1 result, got %d", len(serviceStatus.Results))
+	}
+	if serviceStatus.Uptime.LastHour != 1 {
+		t.Errorf("serviceStatus.Uptime.LastHour should've been 1, got %f", serviceStatus.Uptime.LastHour)
+	}
+}
+
+func TestStore_GetServiceStatusForMissingStatusReturnsNil(t *testing.T) {
+	store, err := NewStore(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	defer store.Close()
+	store.Insert(&testService, &testSuccessfulResult)
+	if serviceStatus := store.GetServiceStatus("nonexistantgroup", "nonexistantname"); serviceStatus != nil {
+		t.Errorf("Returned service status for group '%s' and name '%s' not nil after inserting the service into the store", testService.Group, testService.Name)
+	}
+	if serviceStatus := store.GetServiceStatus(testService.Group, "nonexistantname"); serviceStatus != nil {
+		t.Errorf("Returned service status for group '%s' and name '%s' not nil after inserting the service into the store", testService.Group, "nonexistantname")
+	}
+}
+
+func TestStore_GetAllServiceStatusesWithResultPagination(t *testing.T) {
+	store, err := NewStore(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	defer store.Close()
+	store.Insert(&testService, &testSuccessfulResult)
+	store.Insert(&testService, &testUnsuccessfulResult)
+	serviceStatuses := store.GetAllServiceStatusesWithResultPagination(1, 20)
+	if len(serviceStatuses) != 1 {
+		t.Fatal("expected 1 service status")
+	}
+	actual, exists := serviceStatuses[util.ConvertGroupAndServiceToKey(testService.Group, testService.Name)]
+	if !exists {
+		t.Fatal("expected service status to exist")
+	}
+	if len(actual.Results) != 2 {
+		t.Error("expected 2 results, got", len(actual.Results))
+	}
+	if len(actual.Events) != 2 {
+		t.Error("expected 2 events, got", len(actual.Events))
+	}
+	// Make sure that the pagination has the same semantics as core.ServiceStatus.WithResultPagination
+	serviceStatus := store.GetServiceStatusByKey(util.ConvertGroupAndServiceToKey(testService.Group, testService.Name))
+	for _, pagination := range [][2]int{{1, 1}, {2, 1}, {3, 1}, {1, 20}, {0, 20}, {1, 0}} {
+		expected := serviceStatus.WithResultPagination(pagination[0], pagination[1]).Results
+		actual := store.GetAllServiceStatusesWithResultPagination(pagination[0], pagination[1])[serviceStatus.Key].Results
+		if len(expected) != len(actual) {
+			t.Errorf("expected %d results for page=%d and pageSize=%d, got %d", len(expected), pagination[0], pagination[1], len(actual))
+			continue
+		}
+		for i := range expected {
+			if expected[i].Success != actual[i].Success || expected[i].Duration != actual[i].Duration {
+				t.Errorf("expected result at index %d for page=%d and pageSize=%d to be the same", i, pagination[0], pagination[1])
+			}
+		}
+	}
+}
+
+func TestStore_GetServiceStatusByKeyWithResultPagination(t *testing.T) {
+	store, err := NewStore(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	defer store.Close()
+	store.Insert(&testService, &testSuccessfulResult)
+	store.Insert(&testService, &testUnsuccessfulResult)
+	key := util.ConvertGroupAndServiceToKey(testService.Group, testService.Name)
+	serviceStatus := store.GetServiceStatusByKeyWithResultPagination(key, 1, 1)
+	if serviceStatus == nil {
+		t.Fatal("expected service status to exist")
+	}
+	if len(serviceStatus.Results) != 1 || serviceStatus.Results[0].Success {
+		t.Error("expected only the last result, which is unsuccessful, to have been read")
+	}
+	if len(serviceStatus.Events) != 2 {
+		t.Error("expected 2 events, got", len(serviceStatus.Events))
+	}
+	if serviceStatus.Uptime == nil || serviceStatus.Uptime.LastHour != 0.5 {
+		t.Error("expected the uptime to have been read regardless of the pagination")
+	}
+	if serviceStatus := store.GetServiceStatusByKeyWithResultPagination(key, 2, 1); len(serviceStatus.Results) != 1 || !serviceStatus.Results[0].Success {
+		t.Error("expected only the first result, which is successful, to have been read")
+	}
+	if serviceStatus := store.GetServiceStatusByKeyWithResultPagination("invalid_key", 1, 1); serviceStatus != nil {
+		t.Error("expected nil, because the service doesn't exist")
+	}
+}
+
+func TestStore_DeleteAllServiceStatusesNotInKeys(t *testing.T) {
+	store, err := NewStore(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	defer store.Close()
+	firstService := core.Service{Name: "service-1", Group: "group"}
+	secondService := core.Service{Name: "service-2", Group: "group"}
+	store.Insert(&firstService, &testSuccessfulResult)
+	store.Insert(&secondService, &testSuccessfulResult)
+	if len(store.GetAllServiceStatusesWithResultPagination(1, 20)) != 2 {
+		t.Fatal("expected 2 service statuses")
+	}
+	if deleted := store.DeleteAllServiceStatusesNotInKeys([]string{util.ConvertGroupAndServiceToKey(firstService.Group, firstService.Name)}); deleted != 1 {
+		t.Errorf("expected 1 service status to have been deleted, got %d", deleted)
+	}
+	if store.GetServiceStatusByKey(util.ConvertGroupAndServiceToKey(firstService.Group, firstService.Name)) == nil {
+		t.Error("firstService should still exist")
+	}
+	if store.GetServiceStatusByKey(util.ConvertGroupAndServiceToKey(secondService.Group, secondService.Name)) != nil {
+		t.Error("secondService should've been deleted")
+	}
+}
+
+func TestStore_Clear(t *testing.T) {
+	store, err := NewStore(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatal("expected no error, got", err.Error())
+	}
+	defer store.Close()
+	store.Insert(&testService, &testSuccessfulResult)
+	store.Clear()
+	if len(store.GetAllServiceStatusesWithResultPagination(1, 20)) != 0 {
+		t.Error("expected store to be empty after being cleared")
+	}
+	if err := store.Save(); err != nil {
+		t.Error("expected no error, got", err.Error())
+	}
+}
@@ -2,6 +2,7 @@ package memory
 
 import (
 	"encoding/gob"
+	"time"
 
 	"github.com/TwinProduction/gatus/core"
 	"github.com/TwinProduction/gatus/util"
@@ -19,13 +20,17 @@ func init() {
 type Store struct {
 	file  string
 	cache *gocache.Cache
+
+	maximumNumberOfResults int
+	maximumResultAge       time.Duration
 }
 
 // NewStore creates a new store
 func NewStore(file string) (*Store, error) {
 	store := &Store{
-		file:  file,
-		cache: gocache.NewCache().WithMaxSize(gocache.NoMaxSize),
+		file:                   file,
+		cache:                  gocache.NewCache().WithMaxSize(gocache.NoMaxSize),
+		maximumNumberOfResults: core.MaximumNumberOfResults,
 	}
 	if len(file) > 0 {
 		_, err := store.cache.ReadFromFile(file)
@@ -36,6 +41,14 @@ func NewStore(file string) (*Store, error) {
 	return store, nil
 }
 
+// WithResultRetention sets the maximum number of results to keep for each service as well as the maximum age of
+// these results. A value of 0 means that there's no limit.
+func (s *Store) WithResultRetention(maximumNumberOfResults int, maximumResultAge time.Duration) *Store {
+	s.maximumNumberOfResults = maximumNumberOfResults
+	s.maximumResultAge = maximumResultAge
+	return s
+}
+
 // GetAllServiceStatusesWithResultPagination returns all monitored core.ServiceStatus
 // with a subset of core.Result defined by the page and pageSize parameters
 func (s *Store) GetAllServiceStatusesWithResultPagination(page, pageSize int) map[string]*core.ServiceStatus {
@@ -61,6 +74,16 @@ func (s *Store) GetServiceStatusByKey(key string) *core.ServiceStatus {
 	return serviceStatus.(*core.ServiceStatus)
 }
 
+// GetServiceStatusByKeyWithResultPagination returns the service status for a given key with a subset of
+// core.Result defined by the page and pageSize parameters
+func (s *Store) GetServiceStatusByKeyWithResultPagination(key string, page, pageSize int) *core.ServiceStatus {
+	serviceStatus := s.GetServiceStatusByKey(key)
+	if serviceStatus == nil {
+		return nil
+	}
+	return serviceStatus.WithResultPagination(page, pageSize)
+}
+
 // Insert adds the observed result for the specified service into the store
 func (s *Store) Insert(service *core.Service, result *core.Result) {
 	key := util.ConvertGroupAndServiceToKey(service.Group, service.Name)
@@ -68,10 +91,20 @@ func (s *Store) Insert(service *core.Service, result *core.Result) {
 	if !exists {
 		serviceStatus = core.NewServiceStatus(service)
 	}
-	serviceStatus.(*core.ServiceStatus).AddResult(result)
+	serviceStatus.(*core.ServiceStatus).AddResultWithRetention(result, s.maximumNumberOfResults, s.maximumResultAge)
 	s.cache.Set(key, serviceStatus)
 }
 
+// GetServiceStatusResultsBetween returns the results of the service with the given key that have a timestamp
+// between from and to, inclusively
+func (s *Store) GetServiceStatusResultsBetween(key string, from, to time.Time) []*core.Result {
+	serviceStatus := s.GetServiceStatusByKey(key)
+	if serviceStatus == nil {
+		return nil
+	}
+	return serviceStatus.ResultsBetween(from, to)
+}
+
 // DeleteAllServiceStatusesNotInKeys removes all ServiceStatus that are not within the keys provided
 func (s *Store) DeleteAllServiceStatusesNotInKeys(keys []string) int {
 	var keysToDelete []string
@@ -102,3 +135,6 @@ func (s *Store) Save() error {
 	}
 	return nil
 }
+
+// Close does nothing, because there's nothing to close
+func (s *Store) Close() {}
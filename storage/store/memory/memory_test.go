@@ -199,6 +199,26 @@ func TestStore_GetServiceStatusByKey(t *testing.T) {
 	}
 }
 
+func TestStore_GetServiceStatusByKeyWithResultPagination(t *testing.T) {
+	store, _ := NewStore("")
+	store.Insert(&testService, &testSuccessfulResult)
+	store.Insert(&testService, &testUnsuccessfulResult)
+	key := util.ConvertGroupAndServiceToKey(testService.Group, testService.Name)
+	serviceStatus := store.GetServiceStatusByKeyWithResultPagination(key, 1, 1)
+	if serviceStatus == nil {
+		t.Fatal("expected service status to exist")
+	}
+	if len(serviceStatus.Results) != 1 || serviceStatus.Results[0].Success {
+		t.Error("expected only the last result, which is unsuccessful, to have been returned")
+	}
+	if len(store.GetServiceStatusByKey(key).Results) != 2 {
+		t.Error("expected the results of the service status in the store to have been left untouched")
+	}
+	if serviceStatus := store.GetServiceStatusByKeyWithResultPagination("invalid_key", 1, 1); serviceStatus != nil {
+		t.Error("expected nil, because the service doesn't exist")
+	}
+}
+
 func TestStore_GetAllServiceStatusesWithResultPagination(t *testing.T) {
 	store, _ := NewStore("")
 	firstResult := &testSuccessfulResult
@@ -224,6 +244,44 @@ func TestStore_GetAllServiceStatusesWithResultPagination(t *testing.T) {
 	}
 }
 
+func TestStore_InsertWithResultRetention(t *testing.T) {
+	store, _ := NewStore("")
+	store.WithResultRetention(0, 0)
+	for i := 0; i < core.MaximumNumberOfResults*2; i++ {
+		store.Insert(&testService, &core.Result{Timestamp: time.Now()})
+	}
+	if serviceStatus := store.GetServiceStatus(testService.Group, testService.Name); len(serviceStatus.Results) != core.MaximumNumberOfResults*2 {
+		t.Errorf("expected all %d results to have been kept, got %d", core.MaximumNumberOfResults*2, len(serviceStatus.Results))
+	}
+	store.Clear()
+	store.WithResultRetention(0, 5*time.Hour+30*time.Minute)
+	now := time.Now()
+	for i := 10; i > 0; i-- {
+		store.Insert(&testService, &core.Result{Timestamp: now.Add(-time.Duration(i) * time.Hour)})
+	}
+	if serviceStatus := store.GetServiceStatus(testService.Group, testService.Name); len(serviceStatus.Results) != 5 {
+		t.Errorf("expected only the 5 results from the last 5h30m to have been kept, got %d", len(serviceStatus.Results))
+	}
+}
+
+func TestStore_GetServiceStatusResultsBetween(t *testing.T) {
+	store, _ := NewStore("")
+	now := time.Now()
+	for i := 10; i > 0; i-- {
+		store.Insert(&testService, &core.Result{Timestamp: now.Add(-time.Duration(i) * time.Hour)})
+	}
+	key := util.ConvertGroupAndServiceToKey(testService.Group, testService.Name)
+	if results := store.GetServiceStatusResultsBetween(key, now.Add(-5*time.Hour), now.Add(-3*time.Hour)); len(results) != 3 {
+		t.Errorf("expected 3 results, got %d", len(results))
+	}
+	if results := store.GetServiceStatusResultsBetween(key, now.Add(-20*time.Hour), now.Add(-15*time.Hour)); results == nil || len(results) != 0 {
+		t.Errorf("expected no results, got %d", len(results))
+	}
+	if results := store.GetServiceStatusResultsBetween("invalid_key", now.Add(-5*time.Hour), now); results != nil {
+		t.Error("expected nil, because the service doesn't exist")
+	}
+}
+
 func TestStore_DeleteAllServiceStatusesNotInKeys(t *testing.T) {
 	store, _ := NewStore("")
 	firstService := core.Service{Name: "service-1", Group: "group"}
@@ -1,7 +1,10 @@
 package store
 
 import (
+	"time"
+
 	"github.com/TwinProduction/gatus/core"
+	"github.com/TwinProduction/gatus/storage/store/bolt"
 	"github.com/TwinProduction/gatus/storage/store/memory"
 )
 
@@ -17,6 +20,14 @@ type Store interface {
 	// GetServiceStatusByKey returns the service status for a given key
 	GetServiceStatusByKey(key string) *core.ServiceStatus
 
+	// GetServiceStatusByKeyWithResultPagination returns the service status for a given key with a subset of
+	// core.Result defined by the page and pageSize parameters
+	GetServiceStatusByKeyWithResultPagination(key string, page, pageSize int) *core.ServiceStatus
+
+	// GetServiceStatusResultsBetween returns the results of the service with the given key that have a timestamp
+	// between from and to, inclusively, or nil if there's no service with the given key
+	GetServiceStatusResultsBetween(key string, from, to time.Time) []*core.Result
+
 	// Insert adds the observed result for the specified service into the store
 	Insert(service *core.Service, result *core.Result)
 
@@ -30,9 +41,15 @@ type Store interface {
 
 	// Save persists the data if and where it needs to be persisted
 	Save() error
+
+	// Close releases the resources held by the store, such as file handles
+	//
+	// The store must not be used after it has been closed
+	Close()
 }
 
 var (
 	// Validate interface implementation on compile
 	_ Store = (*memory.Store)(nil)
+	_ Store = (*bolt.Store)(nil)
 )
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/TwinProduction/gatus/core"
+	"github.com/TwinProduction/gatus/storage/store/bolt"
 	"github.com/TwinProduction/gatus/storage/store/memory"
 )
 
@@ -85,6 +86,11 @@ func BenchmarkStore_GetAllAsJSON(b *testing.B) {
 	if err != nil {
 		b.Fatal("failed to create store:", err.Error())
 	}
+	boltStore, err := bolt.NewStore(b.TempDir() + "/test.db")
+	if err != nil {
+		b.Fatal("failed to create store:", err.Error())
+	}
+	defer boltStore.Close()
 	type Scenario struct {
 		Name  string
 		Store Store
@@ -94,6 +100,10 @@ func BenchmarkStore_GetAllAsJSON(b *testing.B) {
 			Name:  "memory",
 			Store: memoryStore,
 		},
+		{
+			Name:  "bolt",
+			Store: boltStore,
+		},
 	}
 	for _, scenario := range scenarios {
 		scenario.Store.Insert(&testService, &testSuccessfulResult)
@@ -112,6 +122,11 @@ func BenchmarkStore_Insert(b *testing.B) {
 	if err != nil {
 		b.Fatal("failed to create store:", err.Error())
 	}
+	boltStore, err := bolt.NewStore(b.TempDir() + "/test.db")
+	if err != nil {
+		b.Fatal("failed to create store:", err.Error())
+	}
+	defer boltStore.Close()
 	type Scenario struct {
 		Name  string
 		Store Store
@@ -121,6 +136,10 @@ func BenchmarkStore_Insert(b *testing.B) {
 			Name:  "memory",
 			Store: memoryStore,
 		},
+		{
+			Name:  "bolt",
+			Store: boltStore,
+		},
 	}
 	for _, scenario := range scenarios {
 		b.Run(scenario.Name, func(b *testing.B) {
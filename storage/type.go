@@ -0,0 +1,13 @@
+package storage
+
+// Type is the type of storage provider
+type Type string
+
+const (
+	// TypeMemory is the Type for the memory store, which keeps everything in memory and periodically persists it to
+	// Config.File if a file is configured
+	TypeMemory Type = "memory"
+
+	// TypeBolt is the Type for the bolt store, which persists every result to Config.File as soon as it is inserted
+	TypeBolt Type = "bolt"
+)
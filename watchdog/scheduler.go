@@ -0,0 +1,104 @@
+package watchdog
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/TwinProduction/gatus/config"
+	"github.com/TwinProduction/gatus/core"
+)
+
+// scheduler determines when services can be evaluated based on the concurrency configuration
+type scheduler struct {
+	// semaphore limits the number of services that can be evaluated at the same time.
+	// If nil, there is no limit.
+	semaphore chan struct{}
+
+	// groupSemaphores limits the number of services of a given group (key) that can be evaluated at the same time
+	groupSemaphores map[string]chan struct{}
+
+	// exclusiveLock is used to prevent other services from being evaluated while an exclusive service is being
+	// evaluated. Non-exclusive services share the read lock, while exclusive services take the write lock.
+	exclusiveLock sync.RWMutex
+
+	maximumStartJitter time.Duration
+}
+
+// newScheduler creates a scheduler for the given concurrency configuration
+func newScheduler(concurrencyConfig *config.ConcurrencyConfig) *scheduler {
+	if concurrencyConfig == nil {
+		concurrencyConfig = &config.ConcurrencyConfig{Max: config.DefaultMaximumConcurrency, MaximumStartJitter: config.DefaultMaximumStartJitter}
+	}
+	s := &scheduler{
+		groupSemaphores:    make(map[string]chan struct{}, len(concurrencyConfig.Groups)),
+		maximumStartJitter: concurrencyConfig.MaximumStartJitter,
+	}
+	if concurrencyConfig.Max > 0 {
+		s.semaphore = make(chan struct{}, concurrencyConfig.Max)
+	}
+	for group, limit := range concurrencyConfig.Groups {
+		if limit > 0 {
+			s.groupSemaphores[group] = make(chan struct{}, limit)
+		}
+	}
+	return s
+}
+
+// startDelay returns a random delay to wait for before the first evaluation of a service.
+// The delay is never longer than the interval of the service.
+func (s *scheduler) startDelay(service *core.Service) time.Duration {
+	maximumStartJitter := s.maximumStartJitter
+	if service.Interval < maximumStartJitter {
+		maximumStartJitter = service.Interval
+	}
+	if maximumStartJitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(maximumStartJitter)))
+}
+
+// acquire blocks until the service is allowed to be evaluated.
+// Returns false if the context was cancelled before that happened, in which case release must not be called.
+func (s *scheduler) acquire(service *core.Service, ctx context.Context) bool {
+	groupSemaphore := s.groupSemaphores[service.Group]
+	if groupSemaphore != nil {
+		select {
+		case groupSemaphore <- struct{}{}:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	if s.semaphore != nil {
+		select {
+		case s.semaphore <- struct{}{}:
+		case <-ctx.Done():
+			if groupSemaphore != nil {
+				<-groupSemaphore
+			}
+			return false
+		}
+	}
+	if service.Exclusive {
+		s.exclusiveLock.Lock()
+	} else {
+		s.exclusiveLock.RLock()
+	}
+	return true
+}
+
+// release allows other services to be evaluated once a service that was acquired has been evaluated
+func (s *scheduler) release(service *core.Service) {
+	if service.Exclusive {
+		s.exclusiveLock.Unlock()
+	} else {
+		s.exclusiveLock.RUnlock()
+	}
+	if s.semaphore != nil {
+		<-s.semaphore
+	}
+	if groupSemaphore := s.groupSemaphores[service.Group]; groupSemaphore != nil {
+		<-groupSemaphore
+	}
+}
@@ -0,0 +1,123 @@
+package watchdog
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TwinProduction/gatus/config"
+	"github.com/TwinProduction/gatus/core"
+)
+
+// runConcurrently acquires the scheduler for each service at the same time and returns the highest number of services
+// that were being "evaluated" at the same time
+func runConcurrently(s *scheduler, services []*core.Service) int32 {
+	var current, highest int32
+	waitGroup := sync.WaitGroup{}
+	for _, service := range services {
+		waitGroup.Add(1)
+		go func(service *core.Service) {
+			defer waitGroup.Done()
+			if !s.acquire(service, context.Background()) {
+				return
+			}
+			n := atomic.AddInt32(&current, 1)
+			for {
+				h := atomic.LoadInt32(&highest)
+				if n <= h || atomic.CompareAndSwapInt32(&highest, h, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(&current, -1)
+			s.release(service)
+		}(service)
+	}
+	waitGroup.Wait()
+	return highest
+}
+
+func TestScheduler(t *testing.T) {
+	scenarios := []struct {
+		Name                      string
+		Config                    *config.ConcurrencyConfig
+		Services                  []*core.Service
+		ExpectedHighestConcurrent int32
+	}{
+		{
+			Name:                      "default",
+			Config:                    nil,
+			Services:                  []*core.Service{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+			ExpectedHighestConcurrent: 1,
+		},
+		{
+			Name:                      "max",
+			Config:                    &config.ConcurrencyConfig{Max: 2},
+			Services:                  []*core.Service{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}},
+			ExpectedHighestConcurrent: 2,
+		},
+		{
+			Name:                      "unlimited",
+			Config:                    &config.ConcurrencyConfig{Max: config.UnlimitedConcurrency},
+			Services:                  []*core.Service{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}},
+			ExpectedHighestConcurrent: 4,
+		},
+		{
+			Name:                      "group-limit",
+			Config:                    &config.ConcurrencyConfig{Max: config.UnlimitedConcurrency, Groups: map[string]int{"core": 1}},
+			Services:                  []*core.Service{{Name: "a", Group: "core"}, {Name: "b", Group: "core"}, {Name: "c", Group: "core"}},
+			ExpectedHighestConcurrent: 1,
+		},
+		{
+			Name:                      "group-limit-does-not-apply-to-other-groups",
+			Config:                    &config.ConcurrencyConfig{Max: config.UnlimitedConcurrency, Groups: map[string]int{"core": 1}},
+			Services:                  []*core.Service{{Name: "a", Group: "core"}, {Name: "b", Group: "core"}, {Name: "c", Group: "other"}},
+			ExpectedHighestConcurrent: 2,
+		},
+		{
+			Name:                      "exclusive",
+			Config:                    &config.ConcurrencyConfig{Max: config.UnlimitedConcurrency},
+			Services:                  []*core.Service{{Name: "a", Exclusive: true}, {Name: "b", Exclusive: true}, {Name: "c", Exclusive: true}},
+			ExpectedHighestConcurrent: 1,
+		},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			if highest := runConcurrently(newScheduler(scenario.Config), scenario.Services); highest != scenario.ExpectedHighestConcurrent {
+				t.Errorf("expected at most %d services to be evaluated at the same time, got %d", scenario.ExpectedHighestConcurrent, highest)
+			}
+		})
+	}
+}
+
+func TestScheduler_acquireWithCancelledContext(t *testing.T) {
+	s := newScheduler(&config.ConcurrencyConfig{Max: 1})
+	service := &core.Service{Name: "a"}
+	if !s.acquire(service, context.Background()) {
+		t.Fatal("expected to acquire the scheduler")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if s.acquire(service, ctx) {
+		t.Error("expected not to acquire the scheduler, because the context was cancelled while the maximum concurrency was reached")
+	}
+	s.release(service)
+	if !s.acquire(service, context.Background()) {
+		t.Error("expected to acquire the scheduler after it was released")
+	}
+	s.release(service)
+}
+
+func TestScheduler_startDelay(t *testing.T) {
+	s := newScheduler(&config.ConcurrencyConfig{Max: 1, MaximumStartJitter: time.Minute})
+	for i := 0; i < 100; i++ {
+		if delay := s.startDelay(&core.Service{Interval: 5 * time.Second}); delay < 0 || delay >= 5*time.Second {
+			t.Fatalf("expected start delay to be between 0 and the interval of the service, got %s", delay)
+		}
+		if delay := s.startDelay(&core.Service{Interval: time.Hour}); delay < 0 || delay >= time.Minute {
+			t.Fatalf("expected start delay to be between 0 and the maximum start jitter, got %s", delay)
+		}
+	}
+}
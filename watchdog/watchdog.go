@@ -3,7 +3,6 @@ package watchdog
 import (
 	"context"
 	"log"
-	"sync"
 	"time"
 
 	"github.com/TwinProduction/gatus/alerting"
@@ -14,10 +13,6 @@ import (
 )
 
 var (
-	// monitoringMutex is used to prevent multiple services from being evaluated at the same time.
-	// Without this, conditions using response time may become inaccurate.
-	monitoringMutex sync.Mutex
-
 	ctx        context.Context
 	cancelFunc context.CancelFunc
 )
@@ -25,17 +20,21 @@ var (
 // Monitor loops over each services and starts a goroutine to monitor each services separately
 func Monitor(cfg *config.Config) {
 	ctx, cancelFunc = context.WithCancel(context.Background())
+	s := newScheduler(cfg.Concurrency)
 	for _, service := range cfg.Services {
-		// To prevent multiple requests from running at the same time, we'll wait for a little bit before each iteration
-		time.Sleep(1111 * time.Millisecond)
-		go monitor(service, cfg.Alerting, cfg.DisableMonitoringLock, cfg.Metrics, cfg.Debug, ctx)
+		go monitor(service, s, cfg.Alerting, cfg.Metrics, cfg.Debug, ctx)
 	}
 }
 
 // monitor monitors a single service in a loop
-func monitor(service *core.Service, alertingConfig *alerting.Config, disableMonitoringLock, enabledMetrics, debug bool, ctx context.Context) {
-	// Run it immediately on start
-	execute(service, alertingConfig, disableMonitoringLock, enabledMetrics, debug)
+func monitor(service *core.Service, s *scheduler, alertingConfig *alerting.Config, enabledMetrics, debug bool, ctx context.Context) {
+	// Wait for a random delay before the first execution, so that not every service is evaluated at the same time on start
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(s.startDelay(service)):
+		execute(service, s, alertingConfig, enabledMetrics, debug, ctx)
+	}
 	// Loop for the next executions
 	for {
 		select {
@@ -43,17 +42,18 @@ func monitor(service *core.Service, alertingConfig *alerting.Config, disableMoni
 			log.Printf("[watchdog][monitor] Canceling current execution of group=%s; service=%s", service.Group, service.Name)
 			return
 		case <-time.After(service.Interval):
-			execute(service, alertingConfig, disableMonitoringLock, enabledMetrics, debug)
+			execute(service, s, alertingConfig, enabledMetrics, debug, ctx)
 		}
 	}
 }
 
-func execute(service *core.Service, alertingConfig *alerting.Config, disableMonitoringLock, enabledMetrics, debug bool) {
-	if !disableMonitoringLock {
-		// By placing the lock here, we prevent multiple services from being monitored at the exact same time, which
-		// could cause performance issues and return inaccurate results
-		monitoringMutex.Lock()
+func execute(service *core.Service, s *scheduler, alertingConfig *alerting.Config, enabledMetrics, debug bool, ctx context.Context) {
+	// By waiting for the scheduler here, we prevent more services than the configured concurrency from being monitored
+	// at the exact same time, which could cause performance issues and return inaccurate results
+	if !s.acquire(service, ctx) {
+		return
 	}
+	defer s.release(service)
 	if debug {
 		log.Printf("[watchdog][execute] Monitoring group=%s; service=%s", service.Group, service.Name)
 	}
@@ -74,9 +74,6 @@ func execute(service *core.Service, alertingConfig *alerting.Config, disableMoni
 	if debug {
 		log.Printf("[watchdog][execute] Waiting for interval=%s before monitoring group=%s service=%s again", service.Interval, service.Group, service.Name)
 	}
-	if !disableMonitoringLock {
-		monitoringMutex.Unlock()
-	}
 }
 
 // UpdateServiceStatuses updates the slice of service statuses
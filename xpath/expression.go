@@ -0,0 +1,444 @@
+package xpath
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// nodeSet is an unordered collection of nodes without duplicates, which is kept in document order
+type nodeSet []*node
+
+// context is the context in which an expression is evaluated
+type context struct {
+	node     *node
+	position int
+	size     int
+}
+
+// expr is an XPath expression, which evaluates into a nodeSet, a string, a float64 or a bool
+type expr interface {
+	evaluate(ctx context) interface{}
+}
+
+type literalExpr string
+
+func (e literalExpr) evaluate(_ context) interface{} {
+	return string(e)
+}
+
+type numberExpr float64
+
+func (e numberExpr) evaluate(_ context) interface{} {
+	return float64(e)
+}
+
+type negateExpr struct {
+	expr expr
+}
+
+func (e negateExpr) evaluate(ctx context) interface{} {
+	return -toNumber(e.expr.evaluate(ctx))
+}
+
+type binaryExpr struct {
+	operator    string
+	left, right expr
+}
+
+func (e binaryExpr) evaluate(ctx context) interface{} {
+	switch e.operator {
+	case "or":
+		return toBoolean(e.left.evaluate(ctx)) || toBoolean(e.right.evaluate(ctx))
+	case "and":
+		return toBoolean(e.left.evaluate(ctx)) && toBoolean(e.right.evaluate(ctx))
+	case "=", "!=", "<", "<=", ">", ">=":
+		return compare(e.operator, e.left.evaluate(ctx), e.right.evaluate(ctx))
+	}
+	left, right := toNumber(e.left.evaluate(ctx)), toNumber(e.right.evaluate(ctx))
+	switch e.operator {
+	case "+":
+		return left + right
+	case "-":
+		return left - right
+	case "*":
+		return left * right
+	case "div":
+		return left / right
+	default:
+		return math.Mod(left, right)
+	}
+}
+
+type unionExpr struct {
+	left, right expr
+}
+
+func (e unionExpr) evaluate(ctx context) interface{} {
+	left, _ := e.left.evaluate(ctx).(nodeSet)
+	right, _ := e.right.evaluate(ctx).(nodeSet)
+	return merge(append(append(nodeSet{}, left...), right...))
+}
+
+type functionCallExpr struct {
+	function  *function
+	arguments []expr
+}
+
+func (e functionCallExpr) evaluate(ctx context) interface{} {
+	arguments := make([]interface{}, len(e.arguments))
+	for i, argument := range e.arguments {
+		arguments[i] = argument.evaluate(ctx)
+	}
+	return e.function.call(ctx, arguments)
+}
+
+// pathExpr is a location path, which may start from the result of a filter expression rather than from the context
+// node or the root node
+type pathExpr struct {
+	absolute         bool
+	filter           expr
+	filterPredicates []expr
+	steps            []*step
+}
+
+func (e *pathExpr) evaluate(ctx context) interface{} {
+	var nodes nodeSet
+	switch {
+	case e.filter != nil:
+		nodes, _ = e.filter.evaluate(ctx).(nodeSet)
+		for _, predicate := range e.filterPredicates {
+			nodes = filter(nodes, predicate)
+		}
+	case e.absolute:
+		root := ctx.node
+		for root.parent != nil {
+			root = root.parent
+		}
+		nodes = nodeSet{root}
+	default:
+		nodes = nodeSet{ctx.node}
+	}
+	for _, s := range e.steps {
+		var selected nodeSet
+		for _, n := range nodes {
+			selected = append(selected, s.evaluate(n)...)
+		}
+		nodes = merge(selected)
+	}
+	return nodes
+}
+
+// step is a step of a location path, such as child::status[1]
+type step struct {
+	axis       string
+	test       nodeTest
+	predicates []expr
+}
+
+func descendantOrSelfStep() *step {
+	return &step{axis: "descendant-or-self", test: nodeTest{kind: "node"}}
+}
+
+// evaluate returns the nodes selected by the step from the given node, in the order of the axis
+func (s *step) evaluate(n *node) nodeSet {
+	var nodes nodeSet
+	for _, candidate := range axes[s.axis](n) {
+		if s.test.matches(candidate, s.axis) {
+			nodes = append(nodes, candidate)
+		}
+	}
+	for _, predicate := range s.predicates {
+		nodes = filter(nodes, predicate)
+	}
+	return nodes
+}
+
+// nodeTest is the test a node must pass to be selected by a step, which is either a name test or a node type test
+type nodeTest struct {
+	// kind is "name" for a name test, or the type of the node otherwise ("node", "text" or "comment")
+	kind string
+
+	// prefix is the namespace prefix of a name test, if any
+	prefix string
+
+	// name is the local name of a name test, or "*" for any name
+	name string
+}
+
+func newNameTest(qualifiedName string) nodeTest {
+	test := nodeTest{kind: "name", name: qualifiedName}
+	if i := strings.IndexByte(qualifiedName, ':'); i != -1 {
+		test.prefix, test.name = qualifiedName[:i], qualifiedName[i+1:]
+	}
+	return test
+}
+
+// matches checks whether the node passes the test. Since namespaces cannot be declared, a name without prefix
+// matches any node with that local name, regardless of the prefix of the node.
+func (t nodeTest) matches(n *node, axis string) bool {
+	switch t.kind {
+	case "node":
+		return true
+	case "text":
+		return n.nodeType == textNode
+	case "comment":
+		return n.nodeType == commentNode
+	}
+	principalNodeType := elementNode
+	if axis == "attribute" {
+		principalNodeType = attributeNode
+	}
+	if n.nodeType != principalNodeType {
+		return false
+	}
+	if len(t.prefix) > 0 && t.prefix != n.prefix {
+		return false
+	}
+	return t.name == "*" || t.name == n.name
+}
+
+// axes are the supported axes, each of which returns the nodes on the axis of a node in the order of the axis
+var axes = map[string]func(n *node) nodeSet{
+	"child": func(n *node) nodeSet {
+		return n.children
+	},
+	"attribute": func(n *node) nodeSet {
+		return n.attributes
+	},
+	"self": func(n *node) nodeSet {
+		return nodeSet{n}
+	},
+	"parent": func(n *node) nodeSet {
+		if n.parent == nil {
+			return nil
+		}
+		return nodeSet{n.parent}
+	},
+	"ancestor": func(n *node) nodeSet {
+		var nodes nodeSet
+		for ancestor := n.parent; ancestor != nil; ancestor = ancestor.parent {
+			nodes = append(nodes, ancestor)
+		}
+		return nodes
+	},
+	"ancestor-or-self": func(n *node) nodeSet {
+		nodes := nodeSet{n}
+		for ancestor := n.parent; ancestor != nil; ancestor = ancestor.parent {
+			nodes = append(nodes, ancestor)
+		}
+		return nodes
+	},
+	"descendant": func(n *node) nodeSet {
+		return descendants(n, nil)
+	},
+	"descendant-or-self": func(n *node) nodeSet {
+		return descendants(n, nodeSet{n})
+	},
+	"following-sibling": func(n *node) nodeSet {
+		siblings := siblings(n)
+		for i, sibling := range siblings {
+			if sibling == n {
+				return siblings[i+1:]
+			}
+		}
+		return nil
+	},
+	"preceding-sibling": func(n *node) nodeSet {
+		var nodes nodeSet
+		siblings := siblings(n)
+		for i := len(siblings) - 1; i >= 0; i-- {
+			if siblings[i] == n {
+				for j := i - 1; j >= 0; j-- {
+					nodes = append(nodes, siblings[j])
+				}
+				break
+			}
+		}
+		return nodes
+	},
+}
+
+func descendants(n *node, nodes nodeSet) nodeSet {
+	for _, child := range n.children {
+		nodes = append(nodes, child)
+		nodes = descendants(child, nodes)
+	}
+	return nodes
+}
+
+// siblings returns the children of the parent of the node, which attributes don't have
+func siblings(n *node) nodeSet {
+	if n.parent == nil || n.nodeType == attributeNode {
+		return nil
+	}
+	return n.parent.children
+}
+
+// filter returns the nodes for which the predicate is true. If the predicate evaluates into a number, it is true if
+// it is equal to the position of the node.
+func filter(nodes nodeSet, predicate expr) nodeSet {
+	var filtered nodeSet
+	for i, n := range nodes {
+		result := predicate.evaluate(context{node: n, position: i + 1, size: len(nodes)})
+		if number, ok := result.(float64); ok {
+			if number == float64(i+1) {
+				filtered = append(filtered, n)
+			}
+		} else if toBoolean(result) {
+			filtered = append(filtered, n)
+		}
+	}
+	return filtered
+}
+
+// merge sorts the nodes in document order and removes the duplicates
+func merge(nodes nodeSet) nodeSet {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].order < nodes[j].order
+	})
+	var merged nodeSet
+	for i, n := range nodes {
+		if i == 0 || n != nodes[i-1] {
+			merged = append(merged, n)
+		}
+	}
+	return merged
+}
+
+// compare compares two values as specified by XPath 1.0: if one of them is a node-set, the comparison is true if
+// it is true for at least one of its nodes
+func compare(operator string, left, right interface{}) bool {
+	leftNodes, leftIsNodeSet := left.(nodeSet)
+	rightNodes, rightIsNodeSet := right.(nodeSet)
+	switch {
+	case leftIsNodeSet && rightIsNodeSet:
+		for _, l := range leftNodes {
+			for _, r := range rightNodes {
+				if compareAtomic(operator, l.stringValue(), r.stringValue()) {
+					return true
+				}
+			}
+		}
+		return false
+	case leftIsNodeSet:
+		if _, isBoolean := right.(bool); isBoolean {
+			return compareAtomic(operator, toBoolean(left), right)
+		}
+		for _, l := range leftNodes {
+			if compareAtomic(operator, l.stringValue(), right) {
+				return true
+			}
+		}
+		return false
+	case rightIsNodeSet:
+		if _, isBoolean := left.(bool); isBoolean {
+			return compareAtomic(operator, left, toBoolean(right))
+		}
+		for _, r := range rightNodes {
+			if compareAtomic(operator, left, r.stringValue()) {
+				return true
+			}
+		}
+		return false
+	}
+	return compareAtomic(operator, left, right)
+}
+
+// compareAtomic compares two values that are strings, numbers or booleans
+func compareAtomic(operator string, left, right interface{}) bool {
+	if operator == "=" || operator == "!=" {
+		var equal bool
+		_, leftIsBoolean := left.(bool)
+		_, rightIsBoolean := right.(bool)
+		_, leftIsNumber := left.(float64)
+		_, rightIsNumber := right.(float64)
+		switch {
+		case leftIsBoolean || rightIsBoolean:
+			equal = toBoolean(left) == toBoolean(right)
+		case leftIsNumber || rightIsNumber:
+			equal = toNumber(left) == toNumber(right)
+		default:
+			equal = toString(left) == toString(right)
+		}
+		return equal == (operator == "=")
+	}
+	l, r := toNumber(left), toNumber(right)
+	switch operator {
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	default:
+		return l >= r
+	}
+}
+
+var numberPattern = regexp.MustCompile(`^-?(\d+(\.\d*)?|\.\d+)$`)
+
+func toNumber(value interface{}) float64 {
+	switch value := value.(type) {
+	case float64:
+		return value
+	case bool:
+		if value {
+			return 1
+		}
+		return 0
+	}
+	s := strings.TrimSpace(toString(value))
+	if !numberPattern.MatchString(s) {
+		return math.NaN()
+	}
+	number, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return math.NaN()
+	}
+	return number
+}
+
+func toString(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case float64:
+		switch {
+		case math.IsNaN(value):
+			return "NaN"
+		case math.IsInf(value, 1):
+			return "Infinity"
+		case math.IsInf(value, -1):
+			return "-Infinity"
+		case value == 0:
+			// Negative zero is formatted as 0 too
+			return "0"
+		}
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	case nodeSet:
+		if len(value) == 0 {
+			return ""
+		}
+		return value[0].stringValue()
+	}
+	return ""
+}
+
+func toBoolean(value interface{}) bool {
+	switch value := value.(type) {
+	case bool:
+		return value
+	case float64:
+		return value != 0 && !math.IsNaN(value)
+	case string:
+		return len(value) > 0
+	case nodeSet:
+		return len(value) > 0
+	}
+	return false
+}
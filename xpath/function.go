@@ -0,0 +1,170 @@
+package xpath
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// function is an XPath function
+type function struct {
+	minimumArguments int
+
+	// maximumArguments is the maximum number of arguments, or -1 if there's no maximum
+	maximumArguments int
+
+	call func(ctx context, arguments []interface{}) interface{}
+}
+
+// functions are the functions of the XPath 1.0 core function library, with the exception of id, lang and
+// namespace-uri, which are meaningless without a DTD or namespace declarations
+var functions = map[string]*function{
+	"last": {0, 0, func(ctx context, _ []interface{}) interface{} {
+		return float64(ctx.size)
+	}},
+	"position": {0, 0, func(ctx context, _ []interface{}) interface{} {
+		return float64(ctx.position)
+	}},
+	"count": {1, 1, func(_ context, arguments []interface{}) interface{} {
+		nodes, _ := arguments[0].(nodeSet)
+		return float64(len(nodes))
+	}},
+	"local-name": {0, 1, func(ctx context, arguments []interface{}) interface{} {
+		if n := contextNodeOrFirst(ctx, arguments); n != nil {
+			return n.name
+		}
+		return ""
+	}},
+	"name": {0, 1, func(ctx context, arguments []interface{}) interface{} {
+		if n := contextNodeOrFirst(ctx, arguments); n != nil {
+			return n.qualifiedName()
+		}
+		return ""
+	}},
+	"string": {0, 1, func(ctx context, arguments []interface{}) interface{} {
+		return toString(contextNodeOrArgument(ctx, arguments))
+	}},
+	"concat": {2, -1, func(_ context, arguments []interface{}) interface{} {
+		var builder strings.Builder
+		for _, argument := range arguments {
+			builder.WriteString(toString(argument))
+		}
+		return builder.String()
+	}},
+	"starts-with": {2, 2, func(_ context, arguments []interface{}) interface{} {
+		return strings.HasPrefix(toString(arguments[0]), toString(arguments[1]))
+	}},
+	"contains": {2, 2, func(_ context, arguments []interface{}) interface{} {
+		return strings.Contains(toString(arguments[0]), toString(arguments[1]))
+	}},
+	"substring-before": {2, 2, func(_ context, arguments []interface{}) interface{} {
+		s, separator := toString(arguments[0]), toString(arguments[1])
+		if i := strings.Index(s, separator); i != -1 {
+			return s[:i]
+		}
+		return ""
+	}},
+	"substring-after": {2, 2, func(_ context, arguments []interface{}) interface{} {
+		s, separator := toString(arguments[0]), toString(arguments[1])
+		if i := strings.Index(s, separator); i != -1 {
+			return s[i+len(separator):]
+		}
+		return ""
+	}},
+	"substring": {2, 3, func(_ context, arguments []interface{}) interface{} {
+		characters := []rune(toString(arguments[0]))
+		start := round(toNumber(arguments[1]))
+		end := math.Inf(1)
+		if len(arguments) == 3 {
+			end = start + round(toNumber(arguments[2]))
+		}
+		var builder strings.Builder
+		for i, character := range characters {
+			if position := float64(i + 1); position >= start && position < end {
+				builder.WriteRune(character)
+			}
+		}
+		return builder.String()
+	}},
+	"string-length": {0, 1, func(ctx context, arguments []interface{}) interface{} {
+		return float64(utf8.RuneCountInString(toString(contextNodeOrArgument(ctx, arguments))))
+	}},
+	"normalize-space": {0, 1, func(ctx context, arguments []interface{}) interface{} {
+		return strings.Join(strings.Fields(toString(contextNodeOrArgument(ctx, arguments))), " ")
+	}},
+	"translate": {3, 3, func(_ context, arguments []interface{}) interface{} {
+		from, to := []rune(toString(arguments[1])), []rune(toString(arguments[2]))
+		return strings.Map(func(r rune) rune {
+			for i, character := range from {
+				if character == r {
+					if i < len(to) {
+						return to[i]
+					}
+					return -1
+				}
+			}
+			return r
+		}, toString(arguments[0]))
+	}},
+	"boolean": {1, 1, func(_ context, arguments []interface{}) interface{} {
+		return toBoolean(arguments[0])
+	}},
+	"not": {1, 1, func(_ context, arguments []interface{}) interface{} {
+		return !toBoolean(arguments[0])
+	}},
+	"true": {0, 0, func(_ context, _ []interface{}) interface{} {
+		return true
+	}},
+	"false": {0, 0, func(_ context, _ []interface{}) interface{} {
+		return false
+	}},
+	"number": {0, 1, func(ctx context, arguments []interface{}) interface{} {
+		return toNumber(contextNodeOrArgument(ctx, arguments))
+	}},
+	"sum": {1, 1, func(_ context, arguments []interface{}) interface{} {
+		nodes, _ := arguments[0].(nodeSet)
+		sum := 0.0
+		for _, n := range nodes {
+			sum += toNumber(n.stringValue())
+		}
+		return sum
+	}},
+	"floor": {1, 1, func(_ context, arguments []interface{}) interface{} {
+		return math.Floor(toNumber(arguments[0]))
+	}},
+	"ceiling": {1, 1, func(_ context, arguments []interface{}) interface{} {
+		return math.Ceil(toNumber(arguments[0]))
+	}},
+	"round": {1, 1, func(_ context, arguments []interface{}) interface{} {
+		return round(toNumber(arguments[0]))
+	}},
+}
+
+// contextNodeOrArgument returns the only argument of a function, or a node-set containing the context node if the
+// function was called without argument
+func contextNodeOrArgument(ctx context, arguments []interface{}) interface{} {
+	if len(arguments) == 0 {
+		return nodeSet{ctx.node}
+	}
+	return arguments[0]
+}
+
+// contextNodeOrFirst returns the first node of the node-set passed as argument, or the context node if the function
+// was called without argument
+func contextNodeOrFirst(ctx context, arguments []interface{}) *node {
+	if len(arguments) == 0 {
+		return ctx.node
+	}
+	if nodes, ok := arguments[0].(nodeSet); ok && len(nodes) > 0 {
+		return nodes[0]
+	}
+	return nil
+}
+
+// round rounds a number to the closest integer, rounding halves towards positive infinity
+func round(number float64) float64 {
+	if math.IsNaN(number) || math.IsInf(number, 0) {
+		return number
+	}
+	return math.Floor(number + 0.5)
+}
@@ -0,0 +1,146 @@
+package xpath
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenType int
+
+const (
+	endToken tokenType = iota
+	nameToken
+	numberToken
+	literalToken
+	operatorToken
+	punctuationToken
+)
+
+// token is a lexical token of an XPath expression
+type token struct {
+	tokenType tokenType
+	text      string
+	position  int
+}
+
+// tokenize splits an XPath expression into tokens, disambiguating between the operators "*", "and", "or", "div" and
+// "mod", and the names that happen to be written the same way
+func tokenize(expression string) ([]token, error) {
+	var tokens []token
+	for position := 0; position < len(expression); {
+		c := expression[position]
+		start := position
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			position++
+			continue
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(expression[position+1:], c)
+			if end == -1 {
+				return nil, fmt.Errorf("invalid expression '%s': unterminated string at position %d", expression, position+1)
+			}
+			position += end + 2
+			tokens = append(tokens, token{tokenType: literalToken, text: expression[start+1 : position-1], position: start})
+			continue
+		case isDigit(c) || (c == '.' && position+1 < len(expression) && isDigit(expression[position+1])):
+			for position < len(expression) && (isDigit(expression[position]) || expression[position] == '.') {
+				position++
+			}
+			tokens = append(tokens, token{tokenType: numberToken, text: expression[start:position], position: start})
+			continue
+		case c == '*':
+			position++
+			tokenType := nameToken
+			if precedesOperator(tokens) {
+				tokenType = operatorToken
+			}
+			tokens = append(tokens, token{tokenType: tokenType, text: "*", position: start})
+			continue
+		case isNameStart(expression, position):
+			position = scanName(expression, position)
+			// Names can be qualified with a prefix, and the local part of a name test can be a wildcard
+			if position+1 < len(expression) && expression[position] == ':' && expression[position+1] != ':' {
+				if expression[position+1] == '*' {
+					position += 2
+				} else if isNameStart(expression, position+1) {
+					position = scanName(expression, position+1)
+				}
+			}
+			name := expression[start:position]
+			if precedesOperator(tokens) {
+				switch name {
+				case "and", "or", "div", "mod":
+					tokens = append(tokens, token{tokenType: operatorToken, text: name, position: start})
+					continue
+				}
+				return nil, fmt.Errorf("invalid expression '%s': unexpected name '%s' at position %d", expression, name, start+1)
+			}
+			tokens = append(tokens, token{tokenType: nameToken, text: name, position: start})
+			continue
+		}
+		for _, operator := range []string{"//", "/", "|", "+", "-", "=", "!=", "<=", ">=", "<", ">"} {
+			if strings.HasPrefix(expression[position:], operator) {
+				tokens = append(tokens, token{tokenType: operatorToken, text: operator, position: start})
+				position += len(operator)
+				break
+			}
+		}
+		if position != start {
+			continue
+		}
+		for _, punctuation := range []string{"::", "..", ".", "(", ")", "[", "]", "@", ","} {
+			if strings.HasPrefix(expression[position:], punctuation) {
+				tokens = append(tokens, token{tokenType: punctuationToken, text: punctuation, position: start})
+				position += len(punctuation)
+				break
+			}
+		}
+		if position == start {
+			r, _ := utf8.DecodeRuneInString(expression[position:])
+			return nil, fmt.Errorf("invalid expression '%s': unexpected character %q at position %d", expression, r, position+1)
+		}
+	}
+	return append(tokens, token{tokenType: endToken, position: len(expression)}), nil
+}
+
+// precedesOperator checks whether the next token must be an operator, which is the case if there is a preceding
+// token and that token isn't "@", "::", "(", "[", "," or an operator
+func precedesOperator(tokens []token) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	previous := tokens[len(tokens)-1]
+	if previous.tokenType == operatorToken {
+		return false
+	}
+	if previous.tokenType == punctuationToken {
+		switch previous.text {
+		case "@", "::", "(", "[", ",":
+			return false
+		}
+	}
+	return true
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isNameStart(expression string, position int) bool {
+	r, _ := utf8.DecodeRuneInString(expression[position:])
+	return r == '_' || unicode.IsLetter(r)
+}
+
+// scanName returns the position following the name starting at the given position
+func scanName(expression string, position int) int {
+	for position < len(expression) {
+		r, size := utf8.DecodeRuneInString(expression[position:])
+		if r != '_' && r != '-' && r != '.' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
+		}
+		position += size
+	}
+	return position
+}
@@ -0,0 +1,183 @@
+package xpath
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+type nodeType int
+
+const (
+	rootNode nodeType = iota
+	elementNode
+	attributeNode
+	textNode
+	commentNode
+)
+
+// node is a node of the tree an XPath expression is evaluated against
+type node struct {
+	nodeType nodeType
+
+	// prefix is the namespace prefix of the name of an element or of an attribute, as written in the document
+	prefix string
+
+	// name is the local name of an element or of an attribute
+	name string
+
+	// value is the value of an attribute, of a text node or of a comment
+	value string
+
+	parent     *node
+	children   []*node
+	attributes []*node
+
+	// order is the position of the node in the document, which is used to sort node-sets in document order
+	order int
+}
+
+// qualifiedName returns the name of the node, prefixed by its namespace prefix if it has one
+func (n *node) qualifiedName() string {
+	if len(n.prefix) > 0 {
+		return n.prefix + ":" + n.name
+	}
+	return n.name
+}
+
+// stringValue returns the string-value of the node, which for the root node and elements is the concatenation of
+// the values of all text nodes they contain
+func (n *node) stringValue() string {
+	if n.nodeType != rootNode && n.nodeType != elementNode {
+		return n.value
+	}
+	var builder strings.Builder
+	var walk func(*node)
+	walk = func(current *node) {
+		for _, child := range current.children {
+			if child.nodeType == textNode {
+				builder.WriteString(child.value)
+			} else if child.nodeType == elementNode {
+				walk(child)
+			}
+		}
+	}
+	walk(n)
+	return builder.String()
+}
+
+// parseDocument parses a document as XML, or as HTML if it isn't well-formed XML
+func parseDocument(b []byte) *node {
+	root, err := parseXML(b)
+	if err != nil {
+		root = parseHTML(b)
+	}
+	setDocumentOrder(root, 0)
+	return root
+}
+
+// parseXML parses a well-formed XML document, preserving the namespace prefixes of the names of its elements and
+// attributes so that they can be used in XPath expressions without having to declare namespaces
+func parseXML(b []byte) (*node, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(b))
+	root := &node{nodeType: rootNode}
+	current := root
+	hasElement := false
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if current == root && hasElement {
+				return nil, errors.New("multiple root elements")
+			}
+			hasElement = true
+			element := &node{nodeType: elementNode, prefix: token.Name.Space, name: token.Name.Local, parent: current}
+			for _, attribute := range token.Attr {
+				if attribute.Name.Space == "xmlns" || (len(attribute.Name.Space) == 0 && attribute.Name.Local == "xmlns") {
+					// Namespace declarations aren't attributes
+					continue
+				}
+				element.attributes = append(element.attributes, &node{nodeType: attributeNode, prefix: attribute.Name.Space, name: attribute.Name.Local, value: attribute.Value, parent: element})
+			}
+			current.children = append(current.children, element)
+			current = element
+		case xml.EndElement:
+			if current == root || current.prefix != token.Name.Space || current.name != token.Name.Local {
+				return nil, errors.New("unexpected end element " + token.Name.Local)
+			}
+			current = current.parent
+		case xml.CharData:
+			if current == root {
+				if len(bytes.TrimSpace(token)) > 0 {
+					return nil, errors.New("text outside of the root element")
+				}
+				continue
+			}
+			if last := len(current.children) - 1; last >= 0 && current.children[last].nodeType == textNode {
+				// Adjacent text, such as text followed by a CDATA section, forms a single text node
+				current.children[last].value += string(token)
+				continue
+			}
+			current.children = append(current.children, &node{nodeType: textNode, value: string(token), parent: current})
+		case xml.Comment:
+			current.children = append(current.children, &node{nodeType: commentNode, value: string(token), parent: current})
+		}
+	}
+	if current != root || !hasElement {
+		return nil, errors.New("invalid XML document")
+	}
+	return root, nil
+}
+
+// parseHTML parses an HTML document, which never fails since HTML parsers must recover from any error
+func parseHTML(b []byte) *node {
+	root := &node{nodeType: rootNode}
+	document, err := html.Parse(bytes.NewReader(b))
+	if err != nil {
+		return root
+	}
+	var convert func(source *html.Node, destination *node)
+	convert = func(source *html.Node, destination *node) {
+		for child := source.FirstChild; child != nil; child = child.NextSibling {
+			switch child.Type {
+			case html.ElementNode:
+				element := &node{nodeType: elementNode, name: child.Data, parent: destination}
+				for _, attribute := range child.Attr {
+					element.attributes = append(element.attributes, &node{nodeType: attributeNode, prefix: attribute.Namespace, name: attribute.Key, value: attribute.Val, parent: element})
+				}
+				destination.children = append(destination.children, element)
+				convert(child, element)
+			case html.TextNode:
+				destination.children = append(destination.children, &node{nodeType: textNode, value: child.Data, parent: destination})
+			case html.CommentNode:
+				destination.children = append(destination.children, &node{nodeType: commentNode, value: child.Data, parent: destination})
+			}
+		}
+	}
+	convert(document, root)
+	return root
+}
+
+// setDocumentOrder numbers the node and its descendants in document order, starting from the given number, and
+// returns the next number
+func setDocumentOrder(n *node, order int) int {
+	n.order = order
+	order++
+	for _, attribute := range n.attributes {
+		attribute.order = order
+		order++
+	}
+	for _, child := range n.children {
+		order = setDocumentOrder(child, order)
+	}
+	return order
+}
@@ -0,0 +1,335 @@
+package xpath
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// parser parses the tokens of an XPath expression into an expression tree
+type parser struct {
+	expression string
+	tokens     []token
+	position   int
+}
+
+// parse parses an XPath 1.0 expression
+func parse(expression string) (expr, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{expression: expression, tokens: tokens}
+	e, err := p.parseOrExpr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().tokenType != endToken {
+		return nil, p.unexpected()
+	}
+	return e, nil
+}
+
+func (p *parser) parseOrExpr() (expr, error) {
+	return p.parseBinaryExpr(p.parseAndExpr, "or")
+}
+
+func (p *parser) parseAndExpr() (expr, error) {
+	return p.parseBinaryExpr(p.parseEqualityExpr, "and")
+}
+
+func (p *parser) parseEqualityExpr() (expr, error) {
+	return p.parseBinaryExpr(p.parseRelationalExpr, "=", "!=")
+}
+
+func (p *parser) parseRelationalExpr() (expr, error) {
+	return p.parseBinaryExpr(p.parseAdditiveExpr, "<", "<=", ">", ">=")
+}
+
+func (p *parser) parseAdditiveExpr() (expr, error) {
+	return p.parseBinaryExpr(p.parseMultiplicativeExpr, "+", "-")
+}
+
+func (p *parser) parseMultiplicativeExpr() (expr, error) {
+	return p.parseBinaryExpr(p.parseUnaryExpr, "*", "div", "mod")
+}
+
+// parseBinaryExpr parses left-associative binary expressions whose operands are parsed by parseOperand
+func (p *parser) parseBinaryExpr(parseOperand func() (expr, error), operators ...string) (expr, error) {
+	left, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		operator, ok := p.acceptOperator(operators...)
+		if !ok {
+			return left, nil
+		}
+		right, err := parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{operator: operator, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnaryExpr() (expr, error) {
+	if _, ok := p.acceptOperator("-"); ok {
+		e, err := p.parseUnaryExpr()
+		if err != nil {
+			return nil, err
+		}
+		return negateExpr{expr: e}, nil
+	}
+	return p.parseUnionExpr()
+}
+
+func (p *parser) parseUnionExpr() (expr, error) {
+	left, err := p.parsePathExpr()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.acceptOperator("|"); !ok {
+			return left, nil
+		}
+		right, err := p.parsePathExpr()
+		if err != nil {
+			return nil, err
+		}
+		left = unionExpr{left: left, right: right}
+	}
+}
+
+// parsePathExpr parses a location path, or a filter expression optionally followed by a relative location path
+func (p *parser) parsePathExpr() (expr, error) {
+	t := p.peek()
+	isFilterExpr := t.tokenType == literalToken || t.tokenType == numberToken || (t.tokenType == punctuationToken && t.text == "(") ||
+		(t.tokenType == nameToken && p.peekAt(1).text == "(" && !isNodeType(t.text))
+	if !isFilterExpr {
+		return p.parseLocationPath()
+	}
+	primary, err := p.parsePrimaryExpr()
+	if err != nil {
+		return nil, err
+	}
+	predicates, err := p.parsePredicates()
+	if err != nil {
+		return nil, err
+	}
+	path := &pathExpr{filter: primary, filterPredicates: predicates}
+	if operator, ok := p.acceptOperator("/", "//"); ok {
+		if operator == "//" {
+			path.steps = append(path.steps, descendantOrSelfStep())
+		}
+		if err := p.parseRelativeLocationPath(path); err != nil {
+			return nil, err
+		}
+	}
+	if len(path.steps) == 0 && len(predicates) == 0 {
+		return primary, nil
+	}
+	return path, nil
+}
+
+func (p *parser) parsePrimaryExpr() (expr, error) {
+	t := p.next()
+	switch t.tokenType {
+	case literalToken:
+		return literalExpr(t.text), nil
+	case numberToken:
+		number, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, p.errorAt(t, "invalid number '%s'", t.text)
+		}
+		return numberExpr(number), nil
+	case punctuationToken:
+		e, err := p.parseOrExpr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.acceptPunctuation(")") {
+			return nil, p.unexpected()
+		}
+		return e, nil
+	}
+	// The only remaining possibility is a function call
+	p.next()
+	function, exists := functions[t.text]
+	if !exists {
+		return nil, p.errorAt(t, "unknown function '%s'", t.text)
+	}
+	var arguments []expr
+	if !p.acceptPunctuation(")") {
+		for {
+			argument, err := p.parseOrExpr()
+			if err != nil {
+				return nil, err
+			}
+			arguments = append(arguments, argument)
+			if p.acceptPunctuation(")") {
+				break
+			}
+			if !p.acceptPunctuation(",") {
+				return nil, p.unexpected()
+			}
+		}
+	}
+	if len(arguments) < function.minimumArguments || (function.maximumArguments >= 0 && len(arguments) > function.maximumArguments) {
+		return nil, p.errorAt(t, "invalid number of arguments for function '%s'", t.text)
+	}
+	return functionCallExpr{function: function, arguments: arguments}, nil
+}
+
+func (p *parser) parseLocationPath() (expr, error) {
+	path := &pathExpr{}
+	if operator, ok := p.acceptOperator("/", "//"); ok {
+		path.absolute = true
+		if operator == "/" && !p.startsStep() {
+			// "/" on its own selects the root node
+			return path, nil
+		}
+		if operator == "//" {
+			path.steps = append(path.steps, descendantOrSelfStep())
+		}
+	}
+	if err := p.parseRelativeLocationPath(path); err != nil {
+		return nil, err
+	}
+	return path, nil
+}
+
+// parseRelativeLocationPath parses steps separated by "/" or "//", and adds them to the path
+func (p *parser) parseRelativeLocationPath(path *pathExpr) error {
+	for {
+		s, err := p.parseStep()
+		if err != nil {
+			return err
+		}
+		path.steps = append(path.steps, s)
+		operator, ok := p.acceptOperator("/", "//")
+		if !ok {
+			return nil
+		}
+		if operator == "//" {
+			path.steps = append(path.steps, descendantOrSelfStep())
+		}
+	}
+}
+
+// startsStep checks whether the next token can be the start of a step
+func (p *parser) startsStep() bool {
+	t := p.peek()
+	if t.tokenType == nameToken {
+		return true
+	}
+	return t.tokenType == punctuationToken && (t.text == "." || t.text == ".." || t.text == "@")
+}
+
+func (p *parser) parseStep() (*step, error) {
+	if p.acceptPunctuation(".") {
+		return &step{axis: "self", test: nodeTest{kind: "node"}}, nil
+	}
+	if p.acceptPunctuation("..") {
+		return &step{axis: "parent", test: nodeTest{kind: "node"}}, nil
+	}
+	s := &step{axis: "child"}
+	if p.acceptPunctuation("@") {
+		s.axis = "attribute"
+	} else if p.peek().tokenType == nameToken && p.peekAt(1).text == "::" {
+		s.axis = p.next().text
+		p.next()
+		if _, supported := axes[s.axis]; !supported {
+			return nil, p.errorAt(p.tokens[p.position-2], "unsupported axis '%s'", s.axis)
+		}
+	}
+	if p.peek().tokenType != nameToken {
+		return nil, p.unexpected()
+	}
+	t := p.next()
+	if isNodeType(t.text) && p.acceptPunctuation("(") {
+		if !p.acceptPunctuation(")") {
+			return nil, p.unexpected()
+		}
+		s.test = nodeTest{kind: t.text}
+	} else {
+		s.test = newNameTest(t.text)
+	}
+	predicates, err := p.parsePredicates()
+	if err != nil {
+		return nil, err
+	}
+	s.predicates = predicates
+	return s, nil
+}
+
+func (p *parser) parsePredicates() ([]expr, error) {
+	var predicates []expr
+	for p.acceptPunctuation("[") {
+		predicate, err := p.parseOrExpr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.acceptPunctuation("]") {
+			return nil, p.unexpected()
+		}
+		predicates = append(predicates, predicate)
+	}
+	return predicates, nil
+}
+
+func (p *parser) peek() token {
+	return p.peekAt(0)
+}
+
+func (p *parser) peekAt(offset int) token {
+	if p.position+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.position+offset]
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	if p.position < len(p.tokens)-1 {
+		p.position++
+	}
+	return t
+}
+
+func (p *parser) acceptOperator(operators ...string) (string, bool) {
+	t := p.peek()
+	if t.tokenType != operatorToken {
+		return "", false
+	}
+	for _, operator := range operators {
+		if t.text == operator {
+			p.next()
+			return operator, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) acceptPunctuation(punctuation string) bool {
+	if t := p.peek(); t.tokenType == punctuationToken && t.text == punctuation {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) unexpected() error {
+	t := p.peek()
+	if t.tokenType == endToken {
+		return p.errorAt(t, "unexpected end of expression")
+	}
+	return p.errorAt(t, "unexpected '%s'", t.text)
+}
+
+func (p *parser) errorAt(t token, format string, arguments ...interface{}) error {
+	return fmt.Errorf("invalid expression '%s' at position %d: %s", p.expression, t.position+1, fmt.Sprintf(format, arguments...))
+}
+
+func isNodeType(name string) bool {
+	return name == "node" || name == "text" || name == "comment"
+}
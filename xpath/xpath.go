@@ -0,0 +1,27 @@
+package xpath
+
+// Eval evaluates an XPath 1.0 expression against an XML document, and returns the value the expression resolves to
+// as well as the length of that value.
+//
+// Documents that aren't well-formed XML are parsed as HTML, which allows XPath expressions to be used with web pages.
+// Since namespaces cannot be declared, a name without prefix matches elements and attributes with that local name
+// regardless of their namespace, and a prefixed name (e.g. soap:Body) matches the prefix used in the document.
+//
+// If the expression evaluates into a node-set, the value is the string-value of the first node in document order
+// (e.g. the text of an element), and the length is the number of nodes. Otherwise, the value is the string, number or
+// boolean the expression evaluates into, and the length is the length of a string, and 1 for numbers and booleans.
+func Eval(expression string, b []byte) (string, int, error) {
+	e, err := parse(expression)
+	if err != nil {
+		return "", 0, err
+	}
+	value := e.evaluate(context{node: parseDocument(b), position: 1, size: 1})
+	switch value := value.(type) {
+	case nodeSet:
+		return toString(value), len(value), nil
+	case string:
+		return value, len(value), nil
+	default:
+		return toString(value), 1, nil
+	}
+}
@@ -0,0 +1,31 @@
+//go:build go1.18
+// +build go1.18
+
+package xpath
+
+import (
+	"testing"
+)
+
+// FuzzEvalWithAbbreviatedDescendantAxis checks that // evaluates exactly like its unabbreviated form,
+// /descendant-or-self::node()/, whatever the relative location path that follows it
+func FuzzEvalWithAbbreviatedDescendantAxis(f *testing.F) {
+	f.Add("Status/text()", soapResponse)
+	f.Add("Component[@latency > 10 and not(. = 'UP')]/@name", soapResponse)
+	f.Add("li[2] | //a", htmlPage)
+	f.Add("a[position() = last()]/ancestor-or-self::*[1]/following-sibling::node()", htmlPage)
+	f.Add("c[normalize-space(.) = 'd']/..", `<a> b <c>d</c> </a>`)
+	f.Fuzz(func(t *testing.T, path, data string) {
+		output, outputLength, err := Eval("//"+path, []byte(data))
+		if err != nil {
+			return
+		}
+		unabbreviatedOutput, unabbreviatedOutputLength, err := Eval("/descendant-or-self::node()/"+path, []byte(data))
+		if err != nil {
+			return
+		}
+		if output != unabbreviatedOutput || outputLength != unabbreviatedOutputLength {
+			t.Errorf("expected //%s to evaluate into '%s' with a length of %d like its unabbreviated form, got '%s' with a length of %d", path, unabbreviatedOutput, unabbreviatedOutputLength, output, outputLength)
+		}
+	})
+}
@@ -0,0 +1,255 @@
+package xpath
+
+import (
+	"testing"
+)
+
+const soapResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:m="https://example.org/health">
+  <soap:Body>
+    <m:GetHealthResponse>
+      <m:Status>UP</m:Status>
+      <m:Components>
+        <m:Component name="database" latency="12">UP</m:Component>
+        <m:Component name="cache" latency="230">DEGRADED</m:Component>
+        <m:Component name="queue" latency="3"><![CDATA[UP]]></m:Component>
+      </m:Components>
+      <!-- generated in 2ms -->
+    </m:GetHealthResponse>
+  </soap:Body>
+</soap:Envelope>`
+
+const htmlPage = `<!DOCTYPE html>
+<html>
+<head><title>Status page</title></head>
+<body>
+  <div id="status" class="banner green">All systems <b>operational</b></div>
+  <ul>
+    <li><a href="/api">API</a>
+    <li><a href="/docs">Docs</a>
+  </ul>
+  <br>
+</body>
+</html>`
+
+func TestEval(t *testing.T) {
+	type Scenario struct {
+		Name                 string
+		Expression           string
+		Data                 string
+		ExpectedOutput       string
+		ExpectedOutputLength int
+		ExpectedError        bool
+	}
+	scenarios := []Scenario{
+		{
+			Name:                 "text",
+			Expression:           "//Status/text()",
+			Data:                 soapResponse,
+			ExpectedOutput:       "UP",
+			ExpectedOutputLength: 1,
+		},
+		{
+			Name:                 "element",
+			Expression:           "//Status",
+			Data:                 soapResponse,
+			ExpectedOutput:       "UP",
+			ExpectedOutputLength: 1,
+		},
+		{
+			Name:                 "absolute-path-with-prefixes",
+			Expression:           "/soap:Envelope/soap:Body/m:GetHealthResponse/m:Status",
+			Data:                 soapResponse,
+			ExpectedOutput:       "UP",
+			ExpectedOutputLength: 1,
+		},
+		{
+			Name:                 "absolute-path-with-wrong-prefix",
+			Expression:           "/soap:Envelope/soap:Body/soap:GetHealthResponse",
+			Data:                 soapResponse,
+			ExpectedOutput:       "",
+			ExpectedOutputLength: 0,
+		},
+		{
+			Name:                 "multiple-nodes",
+			Expression:           "//Component",
+			Data:                 soapResponse,
+			ExpectedOutput:       "UP",
+			ExpectedOutputLength: 3,
+		},
+		{
+			Name:                 "attribute",
+			Expression:           "//Component[2]/@name",
+			Data:                 soapResponse,
+			ExpectedOutput:       "cache",
+			ExpectedOutputLength: 1,
+		},
+		{
+			Name:                 "predicate-with-attribute",
+			Expression:           "//Component[@name='cache']",
+			Data:                 soapResponse,
+			ExpectedOutput:       "DEGRADED",
+			ExpectedOutputLength: 1,
+		},
+		{
+			Name:                 "predicate-with-comparison-and-logical-operators",
+			Expression:           `//Component[@latency > 10 and not(. = "UP")]/@name`,
+			Data:                 soapResponse,
+			ExpectedOutput:       "cache",
+			ExpectedOutputLength: 1,
+		},
+		{
+			Name:                 "cdata",
+			Expression:           "//Component[last()]",
+			Data:                 soapResponse,
+			ExpectedOutput:       "UP",
+			ExpectedOutputLength: 1,
+		},
+		{
+			Name:                 "axis",
+			Expression:           "//Component[@name='queue']/preceding-sibling::*[1]/@name",
+			Data:                 soapResponse,
+			ExpectedOutput:       "cache",
+			ExpectedOutputLength: 1,
+		},
+		{
+			Name:                 "parent",
+			Expression:           "local-name(//Status/..)",
+			Data:                 soapResponse,
+			ExpectedOutput:       "GetHealthResponse",
+			ExpectedOutputLength: 17,
+		},
+		{
+			Name:                 "comment",
+			Expression:           "normalize-space(//comment())",
+			Data:                 soapResponse,
+			ExpectedOutput:       "generated in 2ms",
+			ExpectedOutputLength: 16,
+		},
+		{
+			Name:                 "count",
+			Expression:           "count(//Component[. != 'UP'])",
+			Data:                 soapResponse,
+			ExpectedOutput:       "1",
+			ExpectedOutputLength: 1,
+		},
+		{
+			Name:                 "sum",
+			Expression:           "sum(//Component/@latency) div 5",
+			Data:                 soapResponse,
+			ExpectedOutput:       "49",
+			ExpectedOutputLength: 1,
+		},
+		{
+			Name:                 "boolean",
+			Expression:           "//Status = 'UP' or //Status = 'DOWN'",
+			Data:                 soapResponse,
+			ExpectedOutput:       "true",
+			ExpectedOutputLength: 1,
+		},
+		{
+			Name:                 "union",
+			Expression:           "//Component[@name='queue'] | //Status",
+			Data:                 soapResponse,
+			ExpectedOutput:       "UP",
+			ExpectedOutputLength: 2,
+		},
+		{
+			Name:                 "string-functions",
+			Expression:           "concat(substring-before(//Component[2]/@name, 'che'), '-', translate(substring(//Component[2], 1, 3), 'DEG', 'deg'))",
+			Data:                 soapResponse,
+			ExpectedOutput:       "ca-deg",
+			ExpectedOutputLength: 6,
+		},
+		{
+			Name:                 "name-that-looks-like-an-operator",
+			Expression:           "//div[. = 'and'] and //and * 2 = 4",
+			Data:                 `<root><div>and</div><and>2</and></root>`,
+			ExpectedOutput:       "true",
+			ExpectedOutputLength: 1,
+		},
+		{
+			Name:                 "html",
+			Expression:           "normalize-space(//div[@id='status'])",
+			Data:                 htmlPage,
+			ExpectedOutput:       "All systems operational",
+			ExpectedOutputLength: 23,
+		},
+		{
+			Name:                 "html-with-unclosed-elements",
+			Expression:           "//li[2]/a/@href",
+			Data:                 htmlPage,
+			ExpectedOutput:       "/docs",
+			ExpectedOutputLength: 1,
+		},
+		{
+			Name:                 "html-with-contains",
+			Expression:           "//div[contains(concat(' ', @class, ' '), ' green ')]/b",
+			Data:                 htmlPage,
+			ExpectedOutput:       "operational",
+			ExpectedOutputLength: 1,
+		},
+		{
+			Name:                 "root",
+			Expression:           "/",
+			Data:                 `<status>UP</status>`,
+			ExpectedOutput:       "UP",
+			ExpectedOutputLength: 1,
+		},
+		{
+			Name:                 "no-match",
+			Expression:           "//missing",
+			Data:                 soapResponse,
+			ExpectedOutput:       "",
+			ExpectedOutputLength: 0,
+		},
+		{
+			Name:          "unknown-function",
+			Expression:    "unknown(//Status)",
+			Data:          soapResponse,
+			ExpectedError: true,
+		},
+		{
+			Name:          "invalid-number-of-arguments",
+			Expression:    "contains(//Status)",
+			Data:          soapResponse,
+			ExpectedError: true,
+		},
+		{
+			Name:          "unterminated-predicate",
+			Expression:    "//Component[@name='cache'",
+			Data:          soapResponse,
+			ExpectedError: true,
+		},
+		{
+			Name:          "unterminated-string",
+			Expression:    "//Component[@name='cache]",
+			Data:          soapResponse,
+			ExpectedError: true,
+		},
+		{
+			Name:          "unsupported-axis",
+			Expression:    "//Status/following::*",
+			Data:          soapResponse,
+			ExpectedError: true,
+		},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			output, outputLength, err := Eval(scenario.Expression, []byte(scenario.Data))
+			if (err != nil) != scenario.ExpectedError {
+				if scenario.ExpectedError {
+					t.Errorf("Expected error, got '%v'", err)
+				} else {
+					t.Errorf("Expected no error, got '%v'", err)
+				}
+			}
+			if outputLength != scenario.ExpectedOutputLength {
+				t.Errorf("Expected output length to be %v, but was %v", scenario.ExpectedOutputLength, outputLength)
+			}
+			if output != scenario.ExpectedOutput {
+				t.Errorf("Expected output to be %v, but was %v", scenario.ExpectedOutput, output)
+			}
+		})
+	}
+}